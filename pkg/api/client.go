@@ -3,7 +3,11 @@ package api
 import (
 	"context"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"os"
+	"strconv"
 
 	"recotem.org/cli/recotem/pkg/cfg"
 	"recotem.org/cli/recotem/pkg/openapi"
@@ -61,3 +65,50 @@ func (c Client) newUnauthenticatedClient() (*openapi.ClientWithResponses, error)
 	}
 	return client, nil
 }
+
+// multipartUpload encodes a "file" part followed by a "project" field into
+// a pipe, returning the content type and the read end of the body.
+func multipartUpload(projectId int, filename string, r io.Reader) (string, io.ReadCloser) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	go func() {
+		fileWriter, err := writer.CreateFormFile("file", filename)
+		if err == nil {
+			_, err = io.Copy(fileWriter, r)
+		}
+		if err == nil {
+			err = writer.WriteField("project", strconv.Itoa(projectId))
+		}
+		if err == nil {
+			err = writer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return writer.FormDataContentType(), pr
+}
+
+// streamBody returns the body of a successful response without reading it,
+// or an error carrying the status and body of a failed one.
+func streamBody(resp *http.Response) (io.ReadCloser, error) {
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s: %s", resp.Status, string(body))
+	}
+	return resp.Body, nil
+}
+
+// writeStream copies r into a new file readable only by the current user.
+func writeStream(output string, r io.Reader) error {
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -74,7 +74,7 @@ func (c Client) GetDeploymentSlot(id int) (*openapi.DeploymentSlot, error) {
 	return nil, fmt.Errorf("%s: %s", resp.Status(), string(resp.Body))
 }
 
-func (c Client) UpdateDeploymentSlot(id int, name *string, trainedModel *int) (*openapi.DeploymentSlot, error) {
+func (c Client) UpdateDeploymentSlot(id int, name *string, trainedModel *int, isActive *bool) (*openapi.DeploymentSlot, error) {
 	client, err := c.newApiClient()
 	if err != nil {
 		return nil, err
@@ -83,6 +83,7 @@ func (c Client) UpdateDeploymentSlot(id int, name *string, trainedModel *int) (*
 	req := openapi.DeploymentSlotUpdateJSONRequestBody{
 		Name:         name,
 		TrainedModel: trainedModel,
+		IsActive:     isActive,
 	}
 	resp, err := client.DeploymentSlotUpdateWithResponse(c.Context, id, req)
 	if err != nil {
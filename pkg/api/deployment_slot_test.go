@@ -110,7 +110,7 @@ func TestUpdateDeploymentSlotSuccess(t *testing.T) {
 	})
 	defer server.Close()
 
-	result, err := client.UpdateDeploymentSlot(1, stringPtr("updated-slot"), nil)
+	result, err := client.UpdateDeploymentSlot(1, stringPtr("updated-slot"), nil, nil)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -1,50 +1,34 @@
 package api
 
 import (
-	"bytes"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"os"
 	"path/filepath"
-	"strconv"
 
 	"recotem.org/cli/recotem/pkg/openapi"
 )
 
 func (c Client) UploadItemMetaData(projectId int, uploadPath string) (*openapi.ItemMetaData, error) {
-	client, err := c.newApiClient()
-	if err != nil {
-		return nil, err
-	}
-
 	f, err := os.Open(uploadPath)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	filename := filepath.Base(uploadPath)
-
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	fileWriter, err := writer.CreateFormFile("file", filename)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = io.Copy(fileWriter, f)
-	if err != nil {
-		return nil, err
-	}
+	return c.UploadItemMetaDataFrom(projectId, filepath.Base(uploadPath), f)
+}
 
-	err = writer.WriteField("project", strconv.Itoa(projectId))
+// UploadItemMetaDataFrom uploads the contents of r under the given filename.
+// The multipart body is streamed, so r is never buffered in full.
+func (c Client) UploadItemMetaDataFrom(projectId int, filename string, r io.Reader) (*openapi.ItemMetaData, error) {
+	client, err := c.newApiClient()
 	if err != nil {
 		return nil, err
 	}
 
-	contentType := writer.FormDataContentType()
+	contentType, body := multipartUpload(projectId, filename, r)
+	defer body.Close()
 
 	resp, err := client.ItemMetaDataCreateWithBodyWithResponse(c.Context, contentType, body)
 	if err != nil {
@@ -104,19 +88,27 @@ func (c Client) GetItemMetaData(id *int, page *int, pageSize *int, project *int)
 }
 
 func (c Client) DownloadItemMetaData(id int, output string) error {
-	client, err := c.newApiClient()
+	body, err := c.OpenItemMetaData(id)
 	if err != nil {
 		return err
 	}
+	defer body.Close()
+
+	return writeStream(output, body)
+}
 
-	resp, err := client.ItemMetaDataDownloadWithResponse(c.Context, id)
+// OpenItemMetaData streams the item meta data file from the server.
+// The caller must close the returned reader.
+func (c Client) OpenItemMetaData(id int) (io.ReadCloser, error) {
+	client, err := c.newApiClient()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if resp.StatusCode() != 200 {
-		return fmt.Errorf("%s: %s", resp.Status(), string(resp.Body))
+	resp, err := client.ItemMetaDataDownload(c.Context, id)
+	if err != nil {
+		return nil, err
 	}
 
-	return os.WriteFile(output, resp.Body, 0600)
+	return streamBody(resp)
 }
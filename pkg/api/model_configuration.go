@@ -77,7 +77,7 @@ func (c Client) GetModelConfigurations(id *int, page *int, pageSize *int,
 	return nil, fmt.Errorf("%s: %s", resp.Status(), string(resp.Body))
 }
 
-func (c Client) UpdateModelConfiguration(id int, name *string,
+func (c Client) UpdateModelConfiguration(id int, name *string, recommenderClassName *string,
 	parametersJson *string) (*openapi.ModelConfiguration, error) {
 	client, err := c.newApiClient()
 	if err != nil {
@@ -85,8 +85,9 @@ func (c Client) UpdateModelConfiguration(id int, name *string,
 	}
 
 	req := openapi.ModelConfigurationUpdateJSONRequestBody{
-		Name:           name,
-		ParametersJson: parametersJson,
+		Name:                 name,
+		RecommenderClassName: recommenderClassName,
+		ParametersJson:       parametersJson,
 	}
 	resp, err := client.ModelConfigurationUpdateWithResponse(c.Context, id, req)
 	if err != nil {
@@ -111,7 +111,7 @@ func TestUpdateModelConfigurationSuccess(t *testing.T) {
 	})
 	defer server.Close()
 
-	result, err := client.UpdateModelConfiguration(1, stringPtr("updated-config"), stringPtr("{\"alpha\": 0.5}"))
+	result, err := client.UpdateModelConfiguration(1, stringPtr("updated-config"), nil, stringPtr("{\"alpha\": 0.5}"))
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -90,3 +90,22 @@ func (c Client) GetParameterTuningJobs(data *int, dataProject *int, id *int, pag
 
 	return nil, fmt.Errorf("%s: %s", resp.Status(), string(resp.Body))
 }
+
+// GetParameterTuningJob fetches a single parameter tuning job.
+func (c Client) GetParameterTuningJob(id int) (*openapi.ParameterTuningJob, error) {
+	client, err := c.newApiClient()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.ParameterTuningJobRetrieveWithResponse(c.Context, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.JSON200 != nil {
+		return resp.JSON200, nil
+	}
+
+	return nil, fmt.Errorf("%s: %s", resp.Status(), string(resp.Body))
+}
@@ -95,3 +95,45 @@ func TestGetParameterTuningJobsSuccess(t *testing.T) {
 		t.Errorf("expected job evaluation 1, got %d", job.Evaluation)
 	}
 }
+
+func TestGetParameterTuningJobSuccess(t *testing.T) {
+	server, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/parameter-tuning-job/7/" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		jsonResponse(w, http.StatusOK, map[string]any{
+			"id":         7,
+			"data":       1,
+			"split":      1,
+			"evaluation": 1,
+			"status":     "running",
+			"best_score": 0.25,
+		})
+	})
+	defer server.Close()
+
+	job, err := client.GetParameterTuningJob(7)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if job.Id == nil || *job.Id != 7 {
+		t.Errorf("expected id 7, got %v", job.Id)
+	}
+	if job.Status == nil || *job.Status != "running" {
+		t.Errorf("expected status running, got %v", job.Status)
+	}
+	if job.BestScore == nil || *job.BestScore != 0.25 {
+		t.Errorf("expected best score 0.25, got %v", job.BestScore)
+	}
+}
+
+func TestGetParameterTuningJobNotFound(t *testing.T) {
+	server, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer server.Close()
+
+	if _, err := client.GetParameterTuningJob(7); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
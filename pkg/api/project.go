@@ -1,7 +1,10 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"recotem.org/cli/recotem/pkg/openapi"
 )
@@ -75,7 +78,116 @@ func (c Client) GetProjects(id *int, name *string) (*[]openapi.Project, error) {
 	return nil, fmt.Errorf("%s: %s", resp.Status(), string(resp.Body))
 }
 
-func (c Client) GetProjectSummary(id int) ([]byte, error) {
+// GetProject fetches a single project.
+func (c Client) GetProject(id int) (*openapi.Project, error) {
+	client, err := c.newApiClient()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.ProjectRetrieveWithResponse(c.Context, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.JSON200 != nil {
+		return resp.JSON200, nil
+	}
+
+	return nil, fmt.Errorf("%s: %s", resp.Status(), string(resp.Body))
+}
+
+// UpdateProject changes the given fields of a project; nil fields are left
+// unchanged. An empty timeColumn clears the time column.
+func (c Client) UpdateProject(id int, name *string, userColumn *string, itemColumn *string,
+	timeColumn *string) (*openapi.Project, error) {
+	client, err := c.newApiClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// PatchedProject always serializes time_column, so build the body by
+	// hand to avoid clearing it when it is not being changed.
+	req := map[string]any{}
+	if name != nil {
+		req["name"] = *name
+	}
+	if userColumn != nil {
+		req["user_column"] = *userColumn
+	}
+	if itemColumn != nil {
+		req["item_column"] = *itemColumn
+	}
+	if timeColumn != nil {
+		if *timeColumn == "" {
+			req["time_column"] = nil
+		} else {
+			req["time_column"] = *timeColumn
+		}
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	rawResp, err := client.ProjectUpdateWithBody(c.Context, id, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := openapi.ParseProjectUpdateResponse(rawResp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.JSON200 != nil {
+		return resp.JSON200, nil
+	}
+
+	return nil, fmt.Errorf("%s: %s", resp.Status(), string(resp.Body))
+}
+
+// ProjectSummary is the project summary returned by the server. Counts the
+// CLI knows about are decoded into fields; anything else is kept in Extra so
+// newer servers still render completely.
+type ProjectSummary struct {
+	NData                *int           `json:"n_data,omitempty" yaml:"n_data,omitempty"`
+	NItemMetaData        *int           `json:"n_item_meta_data,omitempty" yaml:"n_item_meta_data,omitempty"`
+	NCompleteJobs        *int           `json:"n_complete_jobs,omitempty" yaml:"n_complete_jobs,omitempty"`
+	NModels              *int           `json:"n_models,omitempty" yaml:"n_models,omitempty"`
+	NModelConfigurations *int           `json:"n_model_configurations,omitempty" yaml:"n_model_configurations,omitempty"`
+	NDeploymentSlots     *int           `json:"n_deployment_slots,omitempty" yaml:"n_deployment_slots,omitempty"`
+	NAbTests             *int           `json:"n_ab_tests,omitempty" yaml:"n_ab_tests,omitempty"`
+	NRetrainingSchedules *int           `json:"n_retraining_schedules,omitempty" yaml:"n_retraining_schedules,omitempty"`
+	InsDatetime          *time.Time     `json:"ins_datetime,omitempty" yaml:"ins_datetime,omitempty"`
+	Extra                map[string]any `json:"extra,omitempty" yaml:"extra,omitempty"`
+}
+
+var projectSummaryFields = []string{
+	"n_data", "n_item_meta_data", "n_complete_jobs", "n_models", "n_model_configurations",
+	"n_deployment_slots", "n_ab_tests", "n_retraining_schedules", "ins_datetime",
+}
+
+// UnmarshalJSON decodes the known counts and collects the other keys.
+func (s *ProjectSummary) UnmarshalJSON(data []byte) error {
+	type known ProjectSummary
+	if err := json.Unmarshal(data, (*known)(s)); err != nil {
+		return err
+	}
+	var all map[string]any
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for _, k := range projectSummaryFields {
+		delete(all, k)
+	}
+	delete(all, "extra")
+	s.Extra = nil
+	if len(all) > 0 {
+		s.Extra = all
+	}
+	return nil
+}
+
+func (c Client) GetProjectSummary(id int) (*ProjectSummary, error) {
 	client, err := c.newApiClient()
 	if err != nil {
 		return nil, err
@@ -87,7 +199,11 @@ func (c Client) GetProjectSummary(id int) ([]byte, error) {
 	}
 
 	if resp.StatusCode() >= 200 && resp.StatusCode() < 300 {
-		return resp.Body, nil
+		summary := &ProjectSummary{}
+		if err := json.Unmarshal(resp.Body, summary); err != nil {
+			return nil, fmt.Errorf("invalid project summary: %w", err)
+		}
+		return summary, nil
 	}
 
 	return nil, fmt.Errorf("%s: %s", resp.Status(), string(resp.Body))
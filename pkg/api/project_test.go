@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"reflect"
 	"testing"
 )
 
@@ -174,32 +175,37 @@ func TestGetProjectsWithFilter(t *testing.T) {
 
 func TestGetProjectSummarySuccess(t *testing.T) {
 	summaryData := map[string]any{
+		"n_data":      3,
+		"n_models":    2,
 		"project_id":  1,
 		"total_users": 100,
-		"total_items": 500,
 	}
 	server, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
 		jsonResponse(w, http.StatusOK, summaryData)
 	})
 	defer server.Close()
 
-	body, err := client.GetProjectSummary(1)
+	summary, err := client.GetProjectSummary(1)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
-	if body == nil {
-		t.Fatal("expected non-nil body")
+	if summary == nil {
+		t.Fatal("expected non-nil summary")
 	}
-
-	var result map[string]any
-	if err := json.Unmarshal(body, &result); err != nil {
-		t.Fatalf("failed to unmarshal summary body: %v", err)
+	if summary.NData == nil || *summary.NData != 3 {
+		t.Errorf("expected n_data 3, got %v", summary.NData)
+	}
+	if summary.NModels == nil || *summary.NModels != 2 {
+		t.Errorf("expected n_models 2, got %v", summary.NModels)
+	}
+	if summary.NAbTests != nil {
+		t.Errorf("expected n_ab_tests to be absent, got %v", *summary.NAbTests)
 	}
-	if result["project_id"] != float64(1) {
-		t.Errorf("expected project_id 1, got %v", result["project_id"])
+	if summary.Extra["total_users"] != float64(100) {
+		t.Errorf("expected total_users 100 in extra, got %v", summary.Extra["total_users"])
 	}
-	if result["total_users"] != float64(100) {
-		t.Errorf("expected total_users 100, got %v", result["total_users"])
+	if _, ok := summary.Extra["n_data"]; ok {
+		t.Error("known fields should not be repeated in extra")
 	}
 }
 
@@ -211,11 +217,71 @@ func TestGetProjectSummaryError(t *testing.T) {
 	})
 	defer server.Close()
 
-	body, err := client.GetProjectSummary(999)
+	summary, err := client.GetProjectSummary(999)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
-	if body != nil {
-		t.Errorf("expected nil body on error, got %v", body)
+	if summary != nil {
+		t.Errorf("expected nil summary on error, got %v", summary)
+	}
+}
+
+func TestGetProjectSuccess(t *testing.T) {
+	server, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/project/7/" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		jsonResponse(w, http.StatusOK, map[string]any{
+			"id": 7, "name": "shop", "user_column": "user_id", "item_column": "item_id",
+		})
+	})
+	defer server.Close()
+
+	project, err := client.GetProject(7)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if project.Name != "shop" {
+		t.Errorf("expected name shop, got %q", project.Name)
+	}
+}
+
+func TestUpdateProjectSendsOnlyChangedFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		timeColumn *string
+		expected   map[string]any
+	}{
+		{"keep time column", nil, map[string]any{"name": "renamed"}},
+		{"clear time column", stringPtr(""), map[string]any{"name": "renamed", "time_column": nil}},
+		{"set time column", stringPtr("ts"), map[string]any{"name": "renamed", "time_column": "ts"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPatch {
+					t.Errorf("expected PATCH method, got %s", r.Method)
+				}
+				var body map[string]any
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("invalid body: %v", err)
+				}
+				if !reflect.DeepEqual(body, tt.expected) {
+					t.Errorf("expected body %v, got %v", tt.expected, body)
+				}
+				jsonResponse(w, http.StatusOK, map[string]any{
+					"id": 1, "name": "renamed", "user_column": "u", "item_column": "i",
+				})
+			})
+			defer server.Close()
+
+			project, err := client.UpdateProject(1, stringPtr("renamed"), nil, nil, tt.timeColumn)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if project.Name != "renamed" {
+				t.Errorf("expected name renamed, got %q", project.Name)
+			}
+		})
 	}
 }
@@ -6,27 +6,35 @@ import (
 	"recotem.org/cli/recotem/pkg/openapi"
 )
 
-func (c Client) GetTaskLogs(page, pageSize *int, task *int) ([]byte, error) {
+func (c Client) GetTaskLogs(page, pageSize *int, task *int) ([]openapi.TaskLog, error) {
+	return c.QueryTaskLogs(openapi.TaskLogListParams{
+		Page:     page,
+		PageSize: pageSize,
+		Task:     task,
+	})
+}
+
+// GetParameterTuningJobTaskLogs fetches the logs of the tasks run for a
+// parameter tuning job.
+func (c Client) GetParameterTuningJobTaskLogs(id int) ([]openapi.TaskLog, error) {
+	return c.QueryTaskLogs(openapi.TaskLogListParams{TuningJobId: &id})
+}
+
+// QueryTaskLogs fetches task logs with any combination of the server's
+// filters; IdGt fetches only entries newer than one already seen.
+func (c Client) QueryTaskLogs(params openapi.TaskLogListParams) ([]openapi.TaskLog, error) {
 	client, err := c.newApiClient()
 	if err != nil {
 		return nil, err
 	}
 
-	var req openapi.TaskLogListParams
-	if page != nil || pageSize != nil || task != nil {
-		req = openapi.TaskLogListParams{
-			Page:     page,
-			PageSize: pageSize,
-			Task:     task,
-		}
-	}
-	resp, err := client.TaskLogListWithResponse(c.Context, &req)
+	resp, err := client.TaskLogListWithResponse(c.Context, &params)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode() >= 200 && resp.StatusCode() < 300 {
-		return resp.Body, nil
+	if resp.JSON200 != nil {
+		return *resp.JSON200, nil
 	}
 
 	return nil, fmt.Errorf("%s: %s", resp.Status(), string(resp.Body))
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+
+	"recotem.org/cli/recotem/pkg/openapi"
 )
 
 func TestGetTaskLogsSuccess(t *testing.T) {
@@ -28,15 +30,11 @@ func TestGetTaskLogsSuccess(t *testing.T) {
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
-	if result == nil {
-		t.Fatal("expected non-nil result")
-	}
-	if len(result) == 0 {
-		t.Fatal("expected non-empty response body")
+	if len(result) != 1 {
+		t.Fatalf("expected 1 task log, got %d", len(result))
 	}
-	body := string(result)
-	if !strings.Contains(body, "Training completed") {
-		t.Errorf("expected body to contain 'Training completed', got %s", body)
+	if result[0].Task != 1 || result[0].Contents == nil || *result[0].Contents != "Training completed" {
+		t.Errorf("unexpected task log: %+v", result[0])
 	}
 }
 
@@ -62,3 +60,51 @@ func TestGetTaskLogsError(t *testing.T) {
 		t.Errorf("expected error to contain '500', got %s", err.Error())
 	}
 }
+
+func TestGetParameterTuningJobTaskLogsSuccess(t *testing.T) {
+	server, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("tuning_job_id"); got != "3" {
+			t.Errorf("expected tuning_job_id=3, got %q", got)
+		}
+		jsonResponse(w, http.StatusOK, []map[string]any{
+			{"id": 1, "task": 5, "contents": "Traceback (most recent call last):"},
+		})
+	})
+	defer server.Close()
+
+	logs, err := client.GetParameterTuningJobTaskLogs(3)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(logs) != 1 || logs[0].Task != 5 {
+		t.Fatalf("expected one log for task 5, got %v", logs)
+	}
+	if logs[0].Contents == nil || !strings.HasPrefix(*logs[0].Contents, "Traceback") {
+		t.Errorf("unexpected contents: %v", logs[0].Contents)
+	}
+}
+
+func TestQueryTaskLogsSendsFilters(t *testing.T) {
+	server, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("model_id") != "4" || q.Get("id_gt") != "10" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		if q.Has("task") || q.Has("tuning_job_id") {
+			t.Errorf("expected unset filters to be left out: %s", r.URL.RawQuery)
+		}
+		jsonResponse(w, http.StatusOK, []map[string]any{
+			{"id": 11, "task": 2, "contents": "fit", "ins_datetime": "2024-01-01T00:00:00Z"},
+		})
+	})
+	defer server.Close()
+
+	model, after := 4, 10
+	logs, err := client.QueryTaskLogs(openapi.TaskLogListParams{ModelId: &model, IdGt: &after})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(logs) != 1 || *logs[0].Id != 11 || logs[0].InsDatetime == nil {
+		t.Errorf("unexpected logs: %+v", logs)
+	}
+}
@@ -2,49 +2,35 @@ package api
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"os"
 	"path/filepath"
-	"strconv"
 
 	"recotem.org/cli/recotem/pkg/openapi"
 )
 
 func (c Client) UploadTrainingData(projectId int, uploadPath string) (*openapi.TrainingData, error) {
-	client, err := c.newApiClient()
-	if err != nil {
-		return nil, err
-	}
-
 	f, err := os.Open(uploadPath)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	filename := filepath.Base(uploadPath)
-
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	fileWriter, err := writer.CreateFormFile("file", filename)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = io.Copy(fileWriter, f)
-	if err != nil {
-		return nil, err
-	}
+	return c.UploadTrainingDataFrom(projectId, filepath.Base(uploadPath), f)
+}
 
-	err = writer.WriteField("project", strconv.Itoa(projectId))
+// UploadTrainingDataFrom uploads the contents of r under the given filename.
+// The multipart body is streamed, so r is never buffered in full.
+func (c Client) UploadTrainingDataFrom(projectId int, filename string, r io.Reader) (*openapi.TrainingData, error) {
+	client, err := c.newApiClient()
 	if err != nil {
 		return nil, err
 	}
 
-	contentType := writer.FormDataContentType()
+	contentType, body := multipartUpload(projectId, filename, r)
+	defer body.Close()
 
 	resp, err := client.TrainingDataCreateWithBodyWithResponse(c.Context, contentType, body)
 	if err != nil {
@@ -104,36 +90,61 @@ func (c Client) GetTrainingData(id *int, page *int, pageSize *int, project *int)
 }
 
 func (c Client) DownloadTrainingData(id int, output string) error {
-	client, err := c.newApiClient()
+	body, err := c.OpenTrainingData(id)
 	if err != nil {
 		return err
 	}
+	defer body.Close()
 
-	resp, err := client.TrainingDataDownloadWithResponse(c.Context, id)
+	return writeStream(output, body)
+}
+
+// OpenTrainingData streams the training data file from the server.
+// The caller must close the returned reader.
+func (c Client) OpenTrainingData(id int) (io.ReadCloser, error) {
+	client, err := c.newApiClient()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if resp.StatusCode() != 200 {
-		return fmt.Errorf("%s: %s", resp.Status(), string(resp.Body))
+	resp, err := client.TrainingDataDownload(c.Context, id)
+	if err != nil {
+		return nil, err
 	}
 
-	return os.WriteFile(output, resp.Body, 0600)
+	return streamBody(resp)
 }
 
-func (c Client) PreviewTrainingData(id int) ([]byte, error) {
+// TrainingDataPreview holds the first rows of a training data file as
+// returned by the preview endpoint.
+type TrainingDataPreview struct {
+	Columns   []string         `json:"columns"`
+	Rows      []map[string]any `json:"rows"`
+	TotalRows *int             `json:"total_rows,omitempty"`
+}
+
+// PreviewTrainingData fetches up to nRows rows (the server default if nil).
+// Numbers are kept as json.Number so large IDs are not rounded.
+func (c Client) PreviewTrainingData(id int, nRows *int) (*TrainingDataPreview, error) {
 	client, err := c.newApiClient()
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := client.TrainingDataPreviewWithResponse(c.Context, id, nil)
+	params := &openapi.TrainingDataPreviewParams{NRows: nRows}
+	resp, err := client.TrainingDataPreviewWithResponse(c.Context, id, params)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode() >= 200 && resp.StatusCode() < 300 {
-		return resp.Body, nil
+		preview := &TrainingDataPreview{}
+		dec := json.NewDecoder(bytes.NewReader(resp.Body))
+		dec.UseNumber()
+		if err := dec.Decode(preview); err != nil {
+			return nil, fmt.Errorf("invalid preview response: %w", err)
+		}
+		return preview, nil
 	}
 
 	return nil, fmt.Errorf("%s: %s", resp.Status(), string(resp.Body))
@@ -1,7 +1,10 @@
 package api
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -57,21 +60,94 @@ func TestGetTrainingDataSuccess(t *testing.T) {
 }
 
 func TestPreviewTrainingDataSuccess(t *testing.T) {
-	previewBody := []byte("col1,col2\nval1,val2\n")
 	server, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			t.Errorf("expected GET method, got %s", r.Method)
 		}
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write(previewBody)
+		if r.URL.Query().Get("n_rows") != "5" {
+			t.Errorf("expected n_rows=5, got %q", r.URL.Query().Get("n_rows"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"columns":["user_id","score"],"rows":[{"user_id":"u1","score":12345678901234567890},{"user_id":"u2","score":null}],"total_rows":2}`))
+	})
+	defer server.Close()
+
+	preview, err := client.PreviewTrainingData(1, intPtr(5))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(preview.Columns) != 2 || preview.Columns[0] != "user_id" {
+		t.Errorf("unexpected columns %v", preview.Columns)
+	}
+	if len(preview.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(preview.Rows))
+	}
+	if n, ok := preview.Rows[0]["score"].(json.Number); !ok || n.String() != "12345678901234567890" {
+		t.Errorf("expected exact json.Number, got %#v", preview.Rows[0]["score"])
+	}
+	if preview.TotalRows == nil || *preview.TotalRows != 2 {
+		t.Errorf("expected total_rows 2, got %v", preview.TotalRows)
+	}
+}
+
+func TestPreviewTrainingDataDefaultRows(t *testing.T) {
+	server, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("n_rows") {
+			t.Errorf("expected no n_rows parameter, got %q", r.URL.RawQuery)
+		}
+		jsonResponse(w, http.StatusOK, map[string]any{"columns": []string{}, "rows": []any{}})
+	})
+	defer server.Close()
+
+	if _, err := client.PreviewTrainingData(1, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestUploadTrainingDataFromSuccess(t *testing.T) {
+	server, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST method, got %s", r.Method)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("expected file part, got %v", err)
+			return
+		}
+		defer file.Close()
+		body, _ := io.ReadAll(file)
+		if header.Filename != "events.csv" {
+			t.Errorf("expected filename events.csv, got %s", header.Filename)
+		}
+		if string(body) != "user_id,item_id\nu1,i1\n" {
+			t.Errorf("unexpected file body %q", string(body))
+		}
+		if r.FormValue("project") != "3" {
+			t.Errorf("expected project 3, got %s", r.FormValue("project"))
+		}
+		jsonResponse(w, http.StatusCreated, map[string]any{
+			"id": 10, "project": 3, "basename": "events.csv", "file": "events.csv",
+		})
 	})
 	defer server.Close()
 
-	data, err := client.PreviewTrainingData(1)
+	td, err := client.UploadTrainingDataFrom(3, "events.csv", strings.NewReader("user_id,item_id\nu1,i1\n"))
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
-	if string(data) != string(previewBody) {
-		t.Errorf("expected preview body %q, got %q", string(previewBody), string(data))
+	if td.Id == nil || *td.Id != 10 {
+		t.Errorf("expected id 10, got %v", td.Id)
+	}
+}
+
+func TestUploadTrainingDataFromError(t *testing.T) {
+	server, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, http.StatusBadRequest, map[string]any{"file": []string{"invalid"}})
+	})
+	defer server.Close()
+
+	_, err := client.UploadTrainingDataFrom(3, "events.csv", strings.NewReader("x"))
+	if err == nil {
+		t.Fatal("expected error, got nil")
 	}
 }
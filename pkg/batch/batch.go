@@ -0,0 +1,212 @@
+// Package batch requests recommendations for many users concurrently and
+// writes them to a file that an interrupted run can resume.
+package batch
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReadUsers reads one user ID per line, skipping blank lines and repeats.
+func ReadUsers(r io.Reader) ([]string, error) {
+	var users []string
+	seen := map[string]bool{}
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		u := strings.TrimSpace(s.Text())
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		users = append(users, u)
+	}
+	return users, s.Err()
+}
+
+// Item is a recommended item and its score.
+type Item struct {
+	ID    string
+	Score float32
+}
+
+// Options controls a batch run.
+type Options struct {
+	// Workers is the number of concurrent requests.
+	Workers int
+	// Rate caps requests per second across workers, retries included; 0
+	// means no limit.
+	Rate float64
+	// Retries is how many times a failed request is repeated.
+	Retries int
+	// Backoff is the wait before the first retry; it doubles each time.
+	Backoff time.Duration
+	// Retryable reports whether an error is worth retrying; nil retries
+	// every error.
+	Retryable func(error) bool
+	// Progress, if set, is called after each user with the counts so far.
+	Progress func(Stats)
+}
+
+// Stats counts the users of a run.
+type Stats struct {
+	Total     int
+	Skipped   int
+	Succeeded int
+	Failed    int
+}
+
+// Failure is a user whose request failed after all retries.
+type Failure struct {
+	User string
+	Err  error
+}
+
+type result struct {
+	user  string
+	items []Item
+	err   error
+}
+
+// Run fetches recommendations for every user not in done and writes them to
+// out as they arrive. Users that fail are reported, not written, so a
+// resumed run requests them again. Run stops early when ctx is cancelled.
+func Run(ctx context.Context, users []string, done map[string]bool, out *Output,
+	fetch func(ctx context.Context, user string) ([]Item, error), opts Options) (Stats, []Failure, error) {
+	stats := Stats{Total: len(users)}
+	var todo []string
+	for _, u := range users {
+		if done[u] {
+			stats.Skipped++
+		} else {
+			todo = append(todo, u)
+		}
+	}
+	workers := max(opts.Workers, 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	wait := limiter(ctx, opts.Rate)
+
+	jobs := make(chan string)
+	results := make(chan result)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range jobs {
+				items, err := fetchWithRetry(ctx, u, fetch, wait, opts)
+				select {
+				case results <- result{user: u, items: items, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for _, u := range todo {
+			select {
+			case jobs <- u:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var failures []Failure
+	for r := range results {
+		switch {
+		case r.err != nil && ctx.Err() != nil:
+			// Cut short by cancellation; the user is requested on resume.
+			continue
+		case r.err != nil:
+			stats.Failed++
+			failures = append(failures, Failure{User: r.user, Err: r.err})
+		default:
+			if err := out.Write(rowsFor(r.user, r.items)); err != nil {
+				cancel()
+				for range results {
+				}
+				return stats, failures, err
+			}
+			stats.Succeeded++
+		}
+		if opts.Progress != nil {
+			opts.Progress(stats)
+		}
+	}
+	return stats, failures, ctx.Err()
+}
+
+func rowsFor(user string, items []Item) []Row {
+	rows := make([]Row, len(items))
+	for i, it := range items {
+		rows[i] = Row{User: user, Rank: int32(i + 1), Item: it.ID, Score: it.Score}
+	}
+	return rows
+}
+
+func fetchWithRetry(ctx context.Context, user string, fetch func(context.Context, string) ([]Item, error),
+	wait func() error, opts Options) ([]Item, error) {
+	backoff := opts.Backoff
+	for attempt := 0; ; attempt++ {
+		if err := wait(); err != nil {
+			return nil, err
+		}
+		items, err := fetch(ctx, user)
+		if err == nil {
+			return items, nil
+		}
+		if attempt >= opts.Retries || (opts.Retryable != nil && !opts.Retryable(err)) || ctx.Err() != nil {
+			if attempt > 0 {
+				err = fmt.Errorf("after %d attempts: %w", attempt+1, err)
+			}
+			return nil, err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// limiter returns a function that blocks until the next request may start,
+// spacing requests evenly at rate per second.
+func limiter(ctx context.Context, rate float64) func() error {
+	if rate <= 0 {
+		return func() error { return ctx.Err() }
+	}
+	interval := time.Duration(float64(time.Second) / rate)
+	var mu sync.Mutex
+	next := time.Now()
+	return func() error {
+		mu.Lock()
+		now := time.Now()
+		if next.Before(now) {
+			next = now
+		}
+		at := next
+		next = next.Add(interval)
+		mu.Unlock()
+		select {
+		case <-time.After(time.Until(at)):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadUsers(t *testing.T) {
+	users, err := ReadUsers(strings.NewReader("u1\n\n  u2 \nu1\r\nu3"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(users, ",") != "u1,u2,u3" {
+		t.Errorf("unexpected users %v", users)
+	}
+}
+
+func TestRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recs.csv")
+	out, err := Create(path, CSV, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	calls := map[string]int{}
+	fetch := func(_ context.Context, user string) ([]Item, error) {
+		mu.Lock()
+		calls[user]++
+		n := calls[user]
+		mu.Unlock()
+		switch {
+		case user == "flaky" && n < 3:
+			return nil, errors.New("503 Service Unavailable")
+		case user == "missing":
+			return nil, errors.New("404 Not Found")
+		}
+		return []Item{{ID: user + "-a", Score: 0.5}, {ID: user + "-b", Score: 0.25}}, nil
+	}
+	opts := Options{
+		Workers:   3,
+		Retries:   3,
+		Backoff:   time.Millisecond,
+		Retryable: func(err error) bool { return !strings.HasPrefix(err.Error(), "404") },
+	}
+	users := []string{"done", "u1", "flaky", "missing", "u2"}
+	stats, failures, err := Run(context.Background(), users, map[string]bool{"done": true}, out, fetch, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := out.Close(true); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats != (Stats{Total: 5, Skipped: 1, Succeeded: 3, Failed: 1}) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if len(failures) != 1 || failures[0].User != "missing" || calls["missing"] != 1 {
+		t.Errorf("expected missing to fail without retries, got %v (%d calls)", failures, calls["missing"])
+	}
+	if calls["flaky"] != 3 || calls["done"] != 0 {
+		t.Errorf("unexpected calls %v", calls)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "user,rank,item,score\n") || !strings.Contains(string(data), "flaky,2,flaky-b,0.25\n") {
+		t.Errorf("unexpected output:\n%s", data)
+	}
+}
+
+func TestRunStopsWhenCancelled(t *testing.T) {
+	out, err := Create(filepath.Join(t.TempDir(), "recs.ndjson"), NDJSON, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	fetch := func(_ context.Context, user string) ([]Item, error) {
+		if user == "u2" {
+			cancel()
+		}
+		return []Item{{ID: "i"}}, nil
+	}
+	users := []string{"u1", "u2", "u3", "u4", "u5", "u6"}
+	stats, _, err := Run(ctx, users, nil, out, fetch, Options{Workers: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancellation error, got %v", err)
+	}
+	if stats.Succeeded >= len(users) {
+		t.Errorf("expected the run to stop early, got %+v", stats)
+	}
+	_ = out.Close(false)
+}
+
+func TestLimiter(t *testing.T) {
+	wait := limiter(context.Background(), 200)
+	start := time.Now()
+	for range 5 {
+		if err := wait(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Five requests at 200/s take at least four intervals of 5ms.
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected requests to be spaced out, took %s", elapsed)
+	}
+}
@@ -0,0 +1,408 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Format is an output file format.
+type Format string
+
+const (
+	CSV     Format = "csv"
+	NDJSON  Format = "ndjson"
+	Parquet Format = "parquet"
+)
+
+// ParseFormat reads a --format value; jsonl is accepted for NDJSON.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "csv":
+		return CSV, nil
+	case "ndjson", "jsonl":
+		return NDJSON, nil
+	case "parquet":
+		return Parquet, nil
+	}
+	return "", fmt.Errorf("unknown output format %q (use csv, ndjson or parquet)", s)
+}
+
+// FormatFromPath guesses the format from a file name, defaulting to CSV.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return NDJSON
+	case ".parquet":
+		return Parquet
+	}
+	return CSV
+}
+
+// Row is one recommended item for a user. Rank starts at 1.
+type Row struct {
+	User  string  `json:"user" parquet:"user"`
+	Rank  int32   `json:"rank" parquet:"rank"`
+	Item  string  `json:"item" parquet:"item"`
+	Score float32 `json:"score" parquet:"score"`
+}
+
+var csvHeader = []string{"user", "rank", "item", "score"}
+
+// Output writes the rows of each user together, so that an interrupted run
+// leaves at most the last user incomplete.
+type Output struct {
+	format Format
+	path   string
+	// spool is the NDJSON file Parquet rows collect in until Close.
+	spool string
+	file  *os.File
+	w     *bufio.Writer
+	csv   *csv.Writer
+	enc   *json.Encoder
+}
+
+// Create opens path for a new run; an existing output is an error. An empty
+// path or "-" writes to stdout, where Parquet is not available.
+func Create(path string, format Format, stdout io.Writer) (*Output, error) {
+	if path == "" || path == "-" {
+		if format == Parquet {
+			return nil, fmt.Errorf("parquet output needs a file")
+		}
+		o := &Output{format: format}
+		o.start(stdout)
+		return o, o.writeHeader()
+	}
+	o := &Output{format: format, path: path}
+	target := path
+	if format == Parquet {
+		o.spool = SpoolPath(path)
+		if _, err := os.Stat(path); err == nil {
+			return nil, fmt.Errorf("%s already exists; use --resume to continue it", path)
+		}
+		target = o.spool
+	}
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("%s already exists; use --resume to continue it", target)
+	}
+	if err != nil {
+		return nil, err
+	}
+	o.file = f
+	o.start(f)
+	return o, o.writeHeader()
+}
+
+// Resume continues the output of an interrupted run. It returns the users
+// whose rows are complete; the rows of the last user in the file, which may
+// have been cut short, are removed so that user is requested again. A
+// missing file starts a new output.
+func Resume(path string, format Format) (*Output, map[string]bool, error) {
+	if path == "" || path == "-" {
+		return nil, nil, fmt.Errorf("--resume needs an output file")
+	}
+	o := &Output{format: format, path: path}
+	target := path
+	finished := false
+	if format == Parquet {
+		o.spool = SpoolPath(path)
+		target = o.spool
+		var err error
+		if finished, err = spoolParquet(path, o.spool); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	data, err := os.ReadFile(target)
+	if errors.Is(err, os.ErrNotExist) {
+		o, err := Create(path, format, nil)
+		return o, map[string]bool{}, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var records []record
+	var headerEnd int64
+	if format == CSV {
+		records, headerEnd, err = scanCSV(data)
+	} else {
+		records, err = scanNDJSON(data)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot resume %s: %w", target, err)
+	}
+	done, keep := completeUsers(records, headerEnd)
+	if finished {
+		// A finished Parquet file was written in one piece.
+		for _, r := range records {
+			done[r.user] = true
+		}
+		keep = int64(len(data))
+	}
+
+	f, err := os.OpenFile(target, os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := f.Truncate(keep); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if _, err := f.Seek(keep, io.SeekStart); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	o.file = f
+	o.start(f)
+	if keep == 0 {
+		err = o.writeHeader()
+	}
+	return o, done, err
+}
+
+// SpoolPath is where Parquet output collects before it is complete.
+func SpoolPath(path string) string {
+	return path + ".partial"
+}
+
+func (o *Output) start(w io.Writer) {
+	o.w = bufio.NewWriter(w)
+	switch o.format {
+	case CSV:
+		o.csv = csv.NewWriter(o.w)
+	default:
+		o.enc = json.NewEncoder(o.w)
+	}
+}
+
+func (o *Output) writeHeader() error {
+	if o.csv == nil {
+		return nil
+	}
+	if err := o.csv.Write(csvHeader); err != nil {
+		return err
+	}
+	return o.flush()
+}
+
+// Write appends the rows of one user and flushes them.
+func (o *Output) Write(rows []Row) error {
+	for _, r := range rows {
+		var err error
+		if o.csv != nil {
+			err = o.csv.Write([]string{r.User, strconv.Itoa(int(r.Rank)), r.Item,
+				strconv.FormatFloat(float64(r.Score), 'g', -1, 32)})
+		} else {
+			err = o.enc.Encode(r)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return o.flush()
+}
+
+func (o *Output) flush() error {
+	if o.csv != nil {
+		o.csv.Flush()
+		if err := o.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return o.w.Flush()
+}
+
+// Close finishes the output. For Parquet, complete is whether every user
+// was processed: only then is the spool converted, otherwise it is kept for
+// --resume.
+func (o *Output) Close(complete bool) error {
+	if err := o.flush(); err != nil {
+		return err
+	}
+	if o.file == nil {
+		return nil
+	}
+	if err := o.file.Close(); err != nil {
+		return err
+	}
+	if o.format != Parquet || !complete {
+		return nil
+	}
+	if err := writeParquet(o.spool, o.path); err != nil {
+		return err
+	}
+	return os.Remove(o.spool)
+}
+
+// record is a row as found when resuming: its user and the offset just past
+// it.
+type record struct {
+	user string
+	end  int64
+}
+
+// completeUsers returns the users whose rows are all in the file and the
+// length to truncate it to: up to the start of the last user's rows.
+func completeUsers(records []record, headerEnd int64) (map[string]bool, int64) {
+	done := map[string]bool{}
+	if len(records) == 0 {
+		return done, headerEnd
+	}
+	last := records[len(records)-1].user
+	i := len(records)
+	for i > 0 && records[i-1].user == last {
+		i--
+	}
+	keep := headerEnd
+	if i > 0 {
+		keep = records[i-1].end
+	}
+	for _, r := range records[:i] {
+		done[r.user] = true
+	}
+	return done, keep
+}
+
+// scanCSV reads rows up to the last complete line. headerEnd is 0 if the
+// file does not even hold a complete header.
+func scanCSV(data []byte) ([]record, int64, error) {
+	data = data[:bytes.LastIndexByte(data, '\n')+1]
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = len(csvHeader)
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if strings.Join(header, ",") != strings.Join(csvHeader, ",") {
+		return nil, 0, fmt.Errorf("expected the header %s", strings.Join(csvHeader, ","))
+	}
+	headerEnd := r.InputOffset()
+	var records []record
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return records, headerEnd, nil
+		}
+		if err != nil {
+			// A quoted field cut off by the interruption ends the file.
+			var perr *csv.ParseError
+			if errors.As(err, &perr) && perr.Err == csv.ErrQuote {
+				return records, headerEnd, nil
+			}
+			return nil, 0, err
+		}
+		records = append(records, record{user: row[0], end: r.InputOffset()})
+	}
+}
+
+// scanNDJSON reads rows up to the last complete line.
+func scanNDJSON(data []byte) ([]record, error) {
+	var records []record
+	var offset int64
+	for {
+		i := bytes.IndexByte(data[offset:], '\n')
+		if i < 0 {
+			return records, nil
+		}
+		line := data[offset : offset+int64(i)]
+		offset += int64(i) + 1
+		var row Row
+		if err := json.Unmarshal(line, &row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", len(records)+1, err)
+		}
+		records = append(records, record{user: row.User, end: offset})
+	}
+}
+
+// spoolParquet copies the rows of a finished Parquet output into a spool, so
+// a resumed run can add to it, and reports whether it did. It does nothing
+// if a spool exists already or there is no output.
+func spoolParquet(path, spool string) (bool, error) {
+	if _, err := os.Stat(spool); err == nil {
+		return false, nil
+	}
+	rows, err := parquet.ReadFile[Row](path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot resume %s: %w", path, err)
+	}
+	f, err := os.Create(spool)
+	if err != nil {
+		return false, err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, r := range rows {
+		if err := enc.Encode(r); err != nil {
+			f.Close()
+			return false, err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return false, err
+	}
+	return true, f.Close()
+}
+
+func writeParquet(spool, path string) error {
+	in, err := os.Open(spool)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := path + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := parquet.NewGenericWriter[Row](out)
+	dec := json.NewDecoder(bufio.NewReader(in))
+	batch := make([]Row, 0, 1024)
+	for {
+		var r Row
+		err := dec.Decode(&r)
+		if err == nil {
+			batch = append(batch, r)
+		}
+		if len(batch) == cap(batch) || (errors.Is(err, io.EOF) && len(batch) > 0) {
+			if _, werr := w.Write(batch); werr != nil {
+				out.Close()
+				return werr
+			}
+			batch = batch[:0]
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			out.Close()
+			return fmt.Errorf("%s: %w", spool, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,154 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat("JSONL"); err != nil || f != NDJSON {
+		t.Errorf("expected ndjson, got %v, %v", f, err)
+	}
+	if _, err := ParseFormat("xlsx"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+	if FormatFromPath("out.parquet") != Parquet || FormatFromPath("out.txt") != CSV {
+		t.Error("unexpected format from path")
+	}
+}
+
+func TestCreateRefusesExistingOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recs.csv")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Create(path, CSV, nil); err == nil || !strings.Contains(err.Error(), "--resume") {
+		t.Errorf("expected an error suggesting --resume, got %v", err)
+	}
+	if _, err := Create("-", Parquet, os.Stdout); err == nil {
+		t.Error("expected parquet to stdout to be refused")
+	}
+}
+
+func TestResumeCSVDropsTheLastUser(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recs.csv")
+	// u2 may be incomplete and the last line was cut off mid-write.
+	partial := "user,rank,item,score\nu1,1,a,0.5\nu1,2,b,0.25\n\"u,2\",1,a,0.5\n\"u,2\",2,b"
+	if err := os.WriteFile(path, []byte(partial), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, done, err := Resume(path, CSV)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !done["u1"] || done["u,2"] || len(done) != 1 {
+		t.Errorf("unexpected done users %v", done)
+	}
+	if err := out.Write([]Row{{User: "u,2", Rank: 1, Item: "c", Score: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(true); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	want := "user,rank,item,score\nu1,1,a,0.5\nu1,2,b,0.25\n\"u,2\",1,c,1\n"
+	if string(data) != want {
+		t.Errorf("expected %q, got %q", want, data)
+	}
+}
+
+func TestResumeNDJSONAndMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "recs.ndjson")
+	partial := `{"user":"u1","rank":1,"item":"a","score":0.5}` + "\n" +
+		`{"user":"u2","rank":1,"item":"a","score":0.5}` + "\n" + `{"user":"u2","ra`
+	if err := os.WriteFile(path, []byte(partial), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, done, err := Resume(path, NDJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = out.Close(true)
+	if !done["u1"] || done["u2"] {
+		t.Errorf("unexpected done users %v", done)
+	}
+	if data, _ := os.ReadFile(path); strings.Count(string(data), "\n") != 1 {
+		t.Errorf("expected only u1's row to remain, got %q", data)
+	}
+
+	out, done, err = Resume(filepath.Join(dir, "new.csv"), CSV)
+	if err != nil || len(done) != 0 {
+		t.Fatalf("expected a new output, got %v, %v", done, err)
+	}
+	_ = out.Close(true)
+	if data, _ := os.ReadFile(filepath.Join(dir, "new.csv")); string(data) != "user,rank,item,score\n" {
+		t.Errorf("expected a header, got %q", data)
+	}
+}
+
+func TestParquetOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recs.parquet")
+	out, err := Create(path, Parquet, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Write([]Row{{User: "u1", Rank: 1, Item: "a", Score: 0.5}}); err != nil {
+		t.Fatal(err)
+	}
+	// An interrupted run keeps the spool and writes no Parquet file.
+	if err := out.Close(false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no parquet file yet, got %v", err)
+	}
+
+	out, done, err := Resume(path, Parquet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 0 {
+		t.Errorf("expected the last user to be requested again, got %v", done)
+	}
+	for _, u := range []string{"u1", "u2"} {
+		if err := out.Write([]Row{{User: u, Rank: 1, Item: "a", Score: 0.5}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := out.Close(true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(SpoolPath(path)); !os.IsNotExist(err) {
+		t.Errorf("expected the spool to be removed, got %v", err)
+	}
+	rows, err := parquet.ReadFile[Row](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1].User != "u2" {
+		t.Errorf("unexpected rows %+v", rows)
+	}
+
+	// Resuming a finished file continues from its rows.
+	out, done, err = Resume(path, Parquet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !done["u1"] || !done["u2"] {
+		t.Errorf("expected every user of a finished file to be done, got %v", done)
+	}
+	if err := out.Write([]Row{{User: "u3", Rank: 1, Item: "a", Score: 0.5}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(true); err != nil {
+		t.Fatal(err)
+	}
+	if rows, _ := parquet.ReadFile[Row](path); len(rows) != 3 {
+		t.Errorf("expected the new user added to the finished rows, got %+v", rows)
+	}
+}
@@ -0,0 +1,207 @@
+// Package bundle reads and writes portable project bundles: a gzipped tar
+// archive holding a JSON manifest of a project's resources and, optionally,
+// the data files uploaded to it.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"recotem.org/cli/recotem/pkg/openapi"
+)
+
+const (
+	// ManifestName is the name of the manifest inside the archive.
+	ManifestName = "bundle.json"
+	// FormatVersion is the manifest version written by this package.
+	FormatVersion = 1
+)
+
+// File is a data file stored in the bundle.
+type File struct {
+	ID       int    `json:"id"`
+	Basename string `json:"basename"`
+	// Path is the slash-separated path of the file inside the archive.
+	Path string `json:"path"`
+}
+
+// Manifest describes the exported project. Resources keep the IDs they had
+// on the source server so that references between them can be remapped.
+type Manifest struct {
+	Version             int                          `json:"version"`
+	ExportedAt          time.Time                    `json:"exported_at"`
+	Server              string                       `json:"server,omitempty"`
+	Project             openapi.Project              `json:"project"`
+	SplitConfigs        []openapi.SplitConfig        `json:"split_configs"`
+	EvaluationConfigs   []openapi.EvaluationConfig   `json:"evaluation_configs"`
+	ModelConfigurations []openapi.ModelConfiguration `json:"model_configurations"`
+	DeploymentSlots     []openapi.DeploymentSlot     `json:"deployment_slots"`
+	RetrainingSchedules []openapi.RetrainingSchedule `json:"retraining_schedules"`
+	AbTests             []openapi.AbTest             `json:"ab_tests"`
+	TrainingData        []File                       `json:"training_data,omitempty"`
+	ItemMetaData        []File                       `json:"item_meta_data,omitempty"`
+}
+
+// DataPath returns the archive path of a data file.
+func DataPath(kind string, id int, basename string) string {
+	name := path.Base(filepath.ToSlash(basename))
+	if name == "." || name == "/" || name == "" {
+		name = "data.csv"
+	}
+	return fmt.Sprintf("%s/%d/%s", kind, id, name)
+}
+
+// Writer writes a bundle. Data files are added first; Close writes the
+// manifest and finishes the archive.
+type Writer struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	gz := gzip.NewWriter(w)
+	return &Writer{gz: gz, tw: tar.NewWriter(gz)}
+}
+
+// AddFile copies the local file src into the archive as name.
+func (w *Writer) AddFile(name, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Format:  tar.FormatPAX,
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(w.tw, f)
+	return err
+}
+
+// Close writes the manifest and closes the archive. It does not close the
+// underlying writer.
+func (w *Writer) Close(m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{
+		Name:    ManifestName,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: m.ExportedAt,
+		Format:  tar.FormatPAX,
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := w.tw.Write(data); err != nil {
+		return err
+	}
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	return w.gz.Close()
+}
+
+// Extract reads a bundle, writing its data files under dir, and returns the
+// manifest. Every data file listed in the manifest must be present.
+func Extract(r io.Reader, dir string) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a bundle: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	var m *Manifest
+	files := map[string]bool{}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid bundle: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if hdr.Name == ManifestName {
+			m = &Manifest{}
+			if err := json.NewDecoder(tr).Decode(m); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", ManifestName, err)
+			}
+			continue
+		}
+		dst, err := localPath(dir, hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFile(dst, tr); err != nil {
+			return nil, err
+		}
+		files[hdr.Name] = true
+	}
+
+	if m == nil {
+		return nil, fmt.Errorf("invalid bundle: %s is missing", ManifestName)
+	}
+	if m.Version > FormatVersion {
+		return nil, fmt.Errorf("bundle version %d is newer than the supported version %d", m.Version, FormatVersion)
+	}
+	for _, f := range append(append([]File{}, m.TrainingData...), m.ItemMetaData...) {
+		if !files[f.Path] {
+			return nil, fmt.Errorf("invalid bundle: %s is missing", f.Path)
+		}
+	}
+	return m, nil
+}
+
+// LocalPath returns where Extract wrote the archive file name under dir.
+func LocalPath(dir, name string) string {
+	return filepath.Join(dir, filepath.FromSlash(name))
+}
+
+// localPath is LocalPath, rejecting names that would escape dir.
+func localPath(dir, name string) (string, error) {
+	clean := path.Clean(name)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid bundle: unsafe path %q", name)
+	}
+	return LocalPath(dir, clean), nil
+}
+
+func writeFile(dst string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,114 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"recotem.org/cli/recotem/pkg/openapi"
+)
+
+func TestWriteAndExtract(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "interactions.csv")
+	if err := os.WriteFile(src, []byte("user,item\nu1,i1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	id := 3
+	m := &Manifest{
+		Version:         FormatVersion,
+		ExportedAt:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Project:         openapi.Project{Id: &id, Name: "shop", UserColumn: "user", ItemColumn: "item"},
+		DeploymentSlots: []openapi.DeploymentSlot{{Id: &id, Name: "main", Project: 3}},
+		TrainingData:    []File{{ID: 5, Basename: "interactions.csv", Path: DataPath("training-data", 5, "interactions.csv")}},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.AddFile(m.TrainingData[0].Path, src); err != nil {
+		t.Fatalf("AddFile failed: %v", err)
+	}
+	if err := w.Close(m); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	got, err := Extract(&buf, dir)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if got.Project.Name != "shop" || len(got.DeploymentSlots) != 1 || got.DeploymentSlots[0].Name != "main" {
+		t.Errorf("unexpected manifest: %+v", got)
+	}
+	data, err := os.ReadFile(LocalPath(dir, got.TrainingData[0].Path))
+	if err != nil {
+		t.Fatalf("extracted file missing: %v", err)
+	}
+	if string(data) != "user,item\nu1,i1\n" {
+		t.Errorf("unexpected file content %q", data)
+	}
+}
+
+func TestDataPath(t *testing.T) {
+	tests := []struct {
+		basename string
+		expected string
+	}{
+		{"a.csv", "training-data/1/a.csv"},
+		{"../../a.csv", "training-data/1/a.csv"},
+		{"", "training-data/1/data.csv"},
+	}
+	for _, tt := range tests {
+		if got := DataPath("training-data", 1, tt.basename); got != tt.expected {
+			t.Errorf("DataPath(%q) = %q, expected %q", tt.basename, got, tt.expected)
+		}
+	}
+}
+
+func archive(t *testing.T, entries map[string]string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range entries {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+	return &buf
+}
+
+func TestExtractErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries map[string]string
+		message string
+	}{
+		{"missing manifest", map[string]string{"a.csv": "x"}, "bundle.json is missing"},
+		{"unsafe path", map[string]string{"../evil": "x"}, "unsafe path"},
+		{"newer version", map[string]string{ManifestName: `{"version": 99}`}, "newer"},
+		{"missing data file", map[string]string{
+			ManifestName: `{"version": 1, "training_data": [{"id": 1, "path": "training-data/1/a.csv"}]}`,
+		}, "training-data/1/a.csv is missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Extract(archive(t, tt.entries), t.TempDir())
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("expected error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+	if _, err := Extract(strings.NewReader("not gzip"), t.TempDir()); err == nil {
+		t.Error("expected an error for a non-gzip input")
+	}
+}
@@ -0,0 +1,39 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const stateDirname = ".recotem"
+
+// StateDir returns a path under ~/.recotem for local state such as
+// pseudonym mappings. The directory itself is not created.
+func StateDir(elem ...string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{home, stateDirname}, elem...)...), nil
+}
+
+// ServerKey returns a file-name safe identifier for the configured server,
+// so state kept for one server is never applied to another.
+func (c *RecotemConfig) ServerKey() string {
+	u := c.Url
+	if _, rest, ok := strings.Cut(u, "://"); ok {
+		u = rest
+	}
+	u = strings.TrimRight(u, "/")
+	key := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, u)
+	if key == "" {
+		return "default"
+	}
+	return key
+}
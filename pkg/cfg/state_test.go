@@ -0,0 +1,34 @@
+package cfg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStateDir(t *testing.T) {
+	path, err := StateDir("pseudonyms", "example")
+	if err != nil {
+		t.Fatalf("StateDir() failed: %v", err)
+	}
+	if !filepath.IsAbs(path) {
+		t.Errorf("StateDir should return an absolute path, got %s", path)
+	}
+	if filepath.Base(path) != "example" || filepath.Base(filepath.Dir(path)) != "pseudonyms" {
+		t.Errorf("unexpected path %s", path)
+	}
+}
+
+func TestServerKey(t *testing.T) {
+	tests := map[string]string{
+		"http://localhost:8000":        "localhost_8000",
+		"https://recotem.example.com/": "recotem.example.com",
+		"https://example.com/api/v1":   "example.com_api_v1",
+		"":                             "default",
+	}
+	for url, want := range tests {
+		c := NewRecotemConfig(url)
+		if got := c.ServerKey(); got != want {
+			t.Errorf("ServerKey(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
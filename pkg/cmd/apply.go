@@ -0,0 +1,413 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/manifest"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+func newPlanCmd() *cobra.Command {
+	var file string
+	var prune bool
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Show the changes apply would make",
+		Long: "Compare a manifest with the server and list the resources that \"apply\" would\n" +
+			"create, update or (with --prune) delete, with the fields that differ.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := manifest.Load(file)
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			state, err := fetchManifestState(client, m)
+			if err != nil {
+				return err
+			}
+			printPlan(getOutputFormat(), manifest.Compute(m, state, prune))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Manifest file (YAML)")
+	cmd.Flags().BoolVar(&prune, "prune", false, "Include deletes of resources not in the manifest")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func newApplyCmd() *cobra.Command {
+	var file string
+	var prune bool
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Create or update resources to match a manifest",
+		Long: "Reconcile the server with a manifest of projects, split configs, evaluation\n" +
+			"configs, model configurations, deployment slots, retraining schedules and\n" +
+			"A/B tests identified by name. Applying the same manifest again changes nothing.\n" +
+			"With --prune, model configurations, deployment slots, retraining schedules and\n" +
+			"A/B tests of the declared projects that the manifest does not list are deleted.\n" +
+			"Run \"plan\" first to review the changes.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := manifest.Load(file)
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			state, err := fetchManifestState(client, m)
+			if err != nil {
+				return err
+			}
+			plan := manifest.Compute(m, state, prune)
+
+			format := getOutputFormat()
+			text := format != "json" && format != "yaml"
+			r := newReconciler(client, state)
+			applied := &manifest.Plan{Changes: []manifest.Change{}}
+			for _, c := range plan.Changes {
+				id, err := r.apply(c)
+				if err != nil {
+					if !text {
+						printPlan(format, applied)
+					}
+					return fmt.Errorf("%s %s %s: %w", c.Action, c.Kind, c.Path(), err)
+				}
+				c.ID = id
+				applied.Changes = append(applied.Changes, c)
+				if text {
+					fmt.Printf("%s %s %s (%s)\n", pastTense(c.Action), c.Kind, c.Path(), utils.Itoa(id))
+				}
+			}
+			if !text {
+				printPlan(format, applied)
+				return nil
+			}
+			create, update, remove := plan.Counts()
+			if create+update+remove == 0 {
+				fmt.Println("No changes. The server matches the manifest.")
+			} else {
+				fmt.Printf("Apply complete: %d created, %d updated, %d deleted.\n", create, update, remove)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Manifest file (YAML)")
+	cmd.Flags().BoolVar(&prune, "prune", false, "Delete resources of declared projects that are not in the manifest")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+// fetchManifestState reads the live state of everything the manifest
+// declares. Projects are looked up by exact name.
+func fetchManifestState(client api.Client, m *manifest.Manifest) (*manifest.State, error) {
+	s := &manifest.State{Projects: map[string]*manifest.ProjectState{}}
+	if len(m.SplitConfigs) > 0 {
+		list, err := client.GetSplitConfigs(nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.SplitConfigs = results(list)
+	}
+	if len(m.EvaluationConfigs) > 0 {
+		list, err := client.GetEvaluationConfigs(nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.EvaluationConfigs = results(list)
+	}
+	for _, p := range m.Projects {
+		projects, err := client.GetProjects(nil, &p.Name)
+		if err != nil {
+			return nil, err
+		}
+		var project *openapi.Project
+		for _, x := range results(projects) {
+			if x.Name == p.Name {
+				project = &x
+				break
+			}
+		}
+		if project == nil {
+			continue
+		}
+		ps := &manifest.ProjectState{Project: *project}
+		id := utils.Deref(project.Id)
+		if ps.ModelConfigurations, err = listModelConfigurations(client, id); err != nil {
+			return nil, err
+		}
+		if ps.DeploymentSlots, err = listDeploymentSlots(client, id); err != nil {
+			return nil, err
+		}
+		if ps.RetrainingSchedules, err = listRetrainingSchedules(client, ps.DeploymentSlots); err != nil {
+			return nil, err
+		}
+		if ps.AbTests, err = listAbTests(client, id); err != nil {
+			return nil, err
+		}
+		s.Projects[p.Name] = ps
+	}
+	return s, nil
+}
+
+// reconciler applies plan changes, resolving project and slot names to the
+// IDs of existing or newly created resources.
+type reconciler struct {
+	client   api.Client
+	state    *manifest.State
+	projects map[string]int
+	slots    map[string]map[string]int
+}
+
+func newReconciler(client api.Client, state *manifest.State) *reconciler {
+	r := &reconciler{
+		client:   client,
+		state:    state,
+		projects: map[string]int{},
+		slots:    map[string]map[string]int{},
+	}
+	for name, ps := range state.Projects {
+		r.projects[name] = utils.Deref(ps.Project.Id)
+		r.slots[name] = map[string]int{}
+		for _, slot := range ps.DeploymentSlots {
+			r.slots[name][slot.Name] = utils.Deref(slot.Id)
+		}
+	}
+	return r
+}
+
+// apply performs a change and returns the ID of the affected resource.
+func (r *reconciler) apply(c manifest.Change) (*int, error) {
+	if c.Action == manifest.ActionDelete {
+		return c.ID, r.remove(c)
+	}
+	id := utils.Deref(c.ID)
+	create := c.Action == manifest.ActionCreate
+
+	switch spec := c.Spec.(type) {
+	case *manifest.SplitConfig:
+		if create {
+			x, err := r.client.CreateSplitConfig(&spec.Name, spec.Scheme, spec.HeldoutRatio, spec.NHeldout,
+				spec.TestUserRatio, spec.NTestUsers, spec.RandomSeed)
+			return idOrNil(x, err, func(x *openapi.SplitConfig) *int { return x.Id })
+		}
+		// n_heldout and n_test_users are always sent, so keep the live
+		// values of the fields that are not declared.
+		live := r.liveSplitConfig(id)
+		x, err := r.client.UpdateSplitConfig(id, &spec.Name, spec.Scheme, spec.HeldoutRatio,
+			either(spec.NHeldout, live.NHeldout), spec.TestUserRatio,
+			either(spec.NTestUsers, live.NTestUsers), spec.RandomSeed)
+		return idOrNil(x, err, func(x *openapi.SplitConfig) *int { return x.Id })
+
+	case *manifest.EvaluationConfig:
+		if create {
+			x, err := r.client.CreateEvaluationConfig(&spec.Name, spec.Cutoff, spec.TargetMetric)
+			return idOrNil(x, err, func(x *openapi.EvaluationConfig) *int { return x.Id })
+		}
+		x, err := r.client.UpdateEvaluationConfig(id, &spec.Name, spec.Cutoff, spec.TargetMetric)
+		return idOrNil(x, err, func(x *openapi.EvaluationConfig) *int { return x.Id })
+
+	case *manifest.Project:
+		if create {
+			var timeColumn *string
+			if spec.TimeColumn != nil && *spec.TimeColumn != "" {
+				timeColumn = spec.TimeColumn
+			}
+			x, err := r.client.CreateProject(spec.Name, spec.UserColumn, spec.ItemColumn, timeColumn)
+			if err != nil {
+				return nil, err
+			}
+			r.projects[spec.Name] = utils.Deref(x.Id)
+			r.slots[spec.Name] = map[string]int{}
+			return x.Id, nil
+		}
+		x, err := r.client.UpdateProject(id, nil, &spec.UserColumn, &spec.ItemColumn, spec.TimeColumn)
+		return idOrNil(x, err, func(x *openapi.Project) *int { return x.Id })
+
+	case *manifest.ModelConfiguration:
+		params, err := spec.ParametersJSON()
+		if err != nil {
+			return nil, err
+		}
+		if create {
+			x, err := r.client.CreateModelConfiguration(&spec.Name, r.projects[c.Project],
+				spec.RecommenderClassName, params)
+			return idOrNil(x, err, func(x *openapi.ModelConfiguration) *int { return x.Id })
+		}
+		var paramsPtr *string
+		if spec.Parameters != nil {
+			paramsPtr = &params
+		}
+		x, err := r.client.UpdateModelConfiguration(id, &spec.Name, &spec.RecommenderClassName, paramsPtr)
+		return idOrNil(x, err, func(x *openapi.ModelConfiguration) *int { return x.Id })
+
+	case *manifest.DeploymentSlot:
+		if create {
+			isActive := true
+			if spec.IsActive != nil {
+				isActive = *spec.IsActive
+			}
+			x, err := r.client.CreateDeploymentSlot(spec.Name, r.projects[c.Project], spec.TrainedModel, isActive)
+			if err != nil {
+				return nil, err
+			}
+			r.slots[c.Project][spec.Name] = utils.Deref(x.Id)
+			return x.Id, nil
+		}
+		// trained_model is always sent; an undeclared one keeps its value.
+		live := r.liveDeploymentSlot(c.Project, id)
+		x, err := r.client.UpdateDeploymentSlot(id, &spec.Name, either(spec.TrainedModel, live.TrainedModel), spec.IsActive)
+		return idOrNil(x, err, func(x *openapi.DeploymentSlot) *int { return x.Id })
+
+	case *manifest.RetrainingSchedule:
+		if create {
+			isActive := true
+			if spec.IsActive != nil {
+				isActive = *spec.IsActive
+			}
+			x, err := r.client.CreateRetrainingSchedule(r.slots[c.Project][c.Name], spec.CronExpression, isActive)
+			return idOrNil(x, err, func(x *openapi.RetrainingSchedule) *int { return x.Id })
+		}
+		x, err := r.client.UpdateRetrainingSchedule(id, &spec.CronExpression, spec.IsActive)
+		return idOrNil(x, err, func(x *openapi.RetrainingSchedule) *int { return x.Id })
+
+	case *manifest.AbTest:
+		slots := make([]int, 0, len(spec.Slots))
+		for _, name := range spec.Slots {
+			slot, ok := r.slots[c.Project][name]
+			if !ok {
+				return nil, fmt.Errorf("deployment slot %q does not exist", name)
+			}
+			slots = append(slots, slot)
+		}
+		if create {
+			x, err := r.client.CreateAbTest(spec.Name, r.projects[c.Project], slots)
+			return idOrNil(x, err, func(x *openapi.AbTest) *int { return x.Id })
+		}
+		x, err := r.client.UpdateAbTest(id, &spec.Name, &slots)
+		return idOrNil(x, err, func(x *openapi.AbTest) *int { return x.Id })
+	}
+	return nil, fmt.Errorf("unsupported change")
+}
+
+func (r *reconciler) remove(c manifest.Change) error {
+	id := utils.Deref(c.ID)
+	switch c.Kind {
+	case manifest.KindModelConfiguration:
+		return r.client.DeleteModelConfiguration(id)
+	case manifest.KindDeploymentSlot:
+		return r.client.DeleteDeploymentSlot(id)
+	case manifest.KindRetrainingSchedule:
+		return r.client.DeleteRetrainingSchedule(id)
+	case manifest.KindAbTest:
+		return r.client.DeleteAbTest(id)
+	}
+	return fmt.Errorf("%s cannot be deleted", c.Kind)
+}
+
+func (r *reconciler) liveSplitConfig(id int) openapi.SplitConfig {
+	for _, x := range r.state.SplitConfigs {
+		if utils.Deref(x.Id) == id {
+			return x
+		}
+	}
+	return openapi.SplitConfig{}
+}
+
+func (r *reconciler) liveDeploymentSlot(project string, id int) openapi.DeploymentSlot {
+	if ps := r.state.Projects[project]; ps != nil {
+		for _, x := range ps.DeploymentSlots {
+			if utils.Deref(x.Id) == id {
+				return x
+			}
+		}
+	}
+	return openapi.DeploymentSlot{}
+}
+
+func idOrNil[T any](x *T, err error, id func(*T) *int) (*int, error) {
+	if err != nil {
+		return nil, err
+	}
+	return id(x), nil
+}
+
+// either returns the declared value, or the live one if it is not declared.
+func either[T any](declared, live *T) *T {
+	if declared != nil {
+		return declared
+	}
+	return live
+}
+
+func pastTense(a manifest.Action) string {
+	switch a {
+	case manifest.ActionCreate:
+		return "created"
+	case manifest.ActionUpdate:
+		return "updated"
+	case manifest.ActionDelete:
+		return "deleted"
+	}
+	return string(a)
+}
+
+func printPlan(format string, p *manifest.Plan) {
+	create, update, remove := p.Counts()
+	if format == "json" || format == "yaml" {
+		utils.PrintOutput(format, map[string]any{
+			"changes": p.Changes,
+			"create":  create,
+			"update":  update,
+			"delete":  remove,
+		})
+		return
+	}
+	writePlan(os.Stdout, p)
+	if create+update+remove == 0 {
+		fmt.Println("No changes. The server matches the manifest.")
+		return
+	}
+	fmt.Printf("\nPlan: %d to create, %d to update, %d to delete.\n", create, update, remove)
+}
+
+// writePlan writes one line per change followed by its field changes.
+func writePlan(w io.Writer, p *manifest.Plan) {
+	symbols := map[manifest.Action]string{
+		manifest.ActionCreate: "+",
+		manifest.ActionUpdate: "~",
+		manifest.ActionDelete: "-",
+	}
+	for _, c := range p.Changes {
+		line := fmt.Sprintf("%s %s %s %s", symbols[c.Action], c.Action, c.Kind, c.Path())
+		if c.ID != nil {
+			line += fmt.Sprintf(" (%d)", *c.ID)
+		}
+		fmt.Fprintln(w, line)
+		for _, f := range c.Fields {
+			fmt.Fprintf(w, "    %s: %s -> %s\n", f.Field, planValue(f.Old), planValue(f.New))
+		}
+	}
+}
+
+func planValue(v any) string {
+	if v == nil {
+		return utils.NoValue
+	}
+	return fmt.Sprint(v)
+}
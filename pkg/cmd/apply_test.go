@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"recotem.org/cli/recotem/pkg/manifest"
+)
+
+func TestWritePlan(t *testing.T) {
+	id := 7
+	p := &manifest.Plan{Changes: []manifest.Change{
+		{Action: manifest.ActionCreate, Kind: manifest.KindSplitConfig, Name: "default"},
+		{Action: manifest.ActionUpdate, Kind: manifest.KindDeploymentSlot, Project: "shop", Name: "main", ID: &id,
+			Fields: []manifest.FieldChange{{Field: "trained_model", Old: nil, New: 12}}},
+		{Action: manifest.ActionDelete, Kind: manifest.KindAbTest, Project: "shop", Name: "old", ID: &id},
+	}}
+
+	var buf bytes.Buffer
+	writePlan(&buf, p)
+	expected := "+ create split-config default\n" +
+		"~ update deployment-slot shop/main (7)\n" +
+		"    trained_model: <NA> -> 12\n" +
+		"- delete ab-test shop/old (7)\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestEither(t *testing.T) {
+	declared, live := 1, 2
+	if got := either(&declared, &live); *got != 1 {
+		t.Errorf("expected the declared value, got %d", *got)
+	}
+	if got := either(nil, &live); *got != 2 {
+		t.Errorf("expected the live value, got %d", *got)
+	}
+}
@@ -95,11 +95,36 @@ func TestProjectCreateCmdFlags(t *testing.T) {
 	assertNotRequiredFlag(t, cmd, "time-column")
 }
 
+func TestProjectGetCmdFlags(t *testing.T) {
+	cmd := newProjectGetCmd()
+
+	assertFlag(t, cmd, "id", "i", "")
+	assertRequiredFlag(t, cmd, "id")
+}
+
+func TestProjectUpdateCmdFlags(t *testing.T) {
+	cmd := newProjectUpdateCmd()
+
+	assertFlag(t, cmd, "id", "i", "")
+	assertFlag(t, cmd, "name", "n", "")
+	assertFlag(t, cmd, "user-column", "u", "")
+	assertFlag(t, cmd, "item-column", "", "")
+	assertFlag(t, cmd, "time-column", "t", "")
+
+	assertRequiredFlag(t, cmd, "id")
+	assertNotRequiredFlag(t, cmd, "name")
+	assertNotRequiredFlag(t, cmd, "time-column")
+}
+
 func TestProjectDeleteCmdFlags(t *testing.T) {
 	cmd := newProjectDeleteCmd()
 
 	assertFlag(t, cmd, "id", "i", "")
+	assertFlag(t, cmd, "yes", "y", "false")
+	assertFlag(t, cmd, "force", "", "false")
 	assertRequiredFlag(t, cmd, "id")
+	assertNotRequiredFlag(t, cmd, "yes")
+	assertNotRequiredFlag(t, cmd, "force")
 }
 
 func TestProjectSummaryCmdFlags(t *testing.T) {
@@ -109,6 +134,34 @@ func TestProjectSummaryCmdFlags(t *testing.T) {
 	assertRequiredFlag(t, cmd, "id")
 }
 
+func TestProjectExportCmdFlags(t *testing.T) {
+	cmd := newProjectExportCmd()
+
+	assertFlag(t, cmd, "id", "i", "")
+	assertFlag(t, cmd, "file", "f", "")
+	assertFlag(t, cmd, "with-data", "", "false")
+	assertRequiredFlag(t, cmd, "id")
+	assertRequiredFlag(t, cmd, "file")
+	assertNotRequiredFlag(t, cmd, "with-data")
+}
+
+func TestProjectImportCmdFlags(t *testing.T) {
+	cmd := newProjectImportCmd()
+
+	assertFlag(t, cmd, "file", "f", "")
+	assertFlag(t, cmd, "name", "n", "")
+	assertFlag(t, cmd, "skip-data", "", "false")
+	assertRequiredFlag(t, cmd, "file")
+	assertNotRequiredFlag(t, cmd, "name")
+}
+
+func TestProjectTreeCmdFlags(t *testing.T) {
+	cmd := newProjectTreeCmd()
+
+	assertFlag(t, cmd, "id", "i", "")
+	assertRequiredFlag(t, cmd, "id")
+}
+
 // --- Trained Model Command ---
 
 func TestTrainedModelListCmdFlags(t *testing.T) {
@@ -162,14 +215,35 @@ func TestTrainedModelRecommendCmdFlags(t *testing.T) {
 	assertFlag(t, cmd, "user-id", "", "")
 	assertFlag(t, cmd, "n-items", "n", "10")
 	assertRequiredFlag(t, cmd, "id")
+	assertFlag(t, cmd, "raw-ids", "", "false")
 	assertRequiredFlag(t, cmd, "user-id")
 	assertNotRequiredFlag(t, cmd, "n-items")
+	assertNotRequiredFlag(t, cmd, "raw-ids")
+}
+
+func TestTrainedModelRecommendBatchCmdFlags(t *testing.T) {
+	cmd := newTrainedModelRecommendBatchCmd()
+
+	assertFlag(t, cmd, "id", "i", "")
+	assertFlag(t, cmd, "users", "", "")
+	assertFlag(t, cmd, "output", "O", "")
+	assertFlag(t, cmd, "format", "", "")
+	assertFlag(t, cmd, "n-items", "n", "10")
+	assertFlag(t, cmd, "concurrency", "c", "4")
+	assertFlag(t, cmd, "rate", "", "0")
+	assertFlag(t, cmd, "retries", "", "3")
+	assertFlag(t, cmd, "resume", "", "false")
+	assertFlag(t, cmd, "raw-ids", "", "false")
+	assertRequiredFlag(t, cmd, "id")
+	assertRequiredFlag(t, cmd, "users")
+	assertNotRequiredFlag(t, cmd, "output")
 }
 
 func TestTrainedModelSampleRecommendCmdFlags(t *testing.T) {
 	cmd := newTrainedModelSampleRecommendCmd()
 
 	assertFlag(t, cmd, "id", "i", "")
+	assertFlag(t, cmd, "raw-ids", "", "false")
 	assertRequiredFlag(t, cmd, "id")
 }
 
@@ -179,6 +253,7 @@ func TestTrainedModelRecommendProfileCmdFlags(t *testing.T) {
 	assertFlag(t, cmd, "id", "i", "")
 	assertFlag(t, cmd, "n-items", "n", "10")
 	assertRequiredFlag(t, cmd, "id")
+	assertFlag(t, cmd, "raw-ids", "", "false")
 	assertRequiredFlag(t, cmd, "item-ids")
 	assertNotRequiredFlag(t, cmd, "n-items")
 }
@@ -197,6 +272,38 @@ func TestTaskLogListCmdFlags(t *testing.T) {
 	assertNotRequiredFlag(t, cmd, "page-size")
 }
 
+func TestTaskLogTailCmdFlags(t *testing.T) {
+	cmd := newTaskLogTailCmd()
+
+	assertFlag(t, cmd, "task", "", "")
+	assertFlag(t, cmd, "tuning-job", "", "")
+	assertFlag(t, cmd, "model", "", "")
+	assertFlag(t, cmd, "follow", "f", "false")
+	assertFlag(t, cmd, "interval", "", "2s")
+	assertFlag(t, cmd, "since", "", "")
+	assertFlag(t, cmd, "grep", "", "")
+
+	// One of task, tuning-job and model is required, but none on its own.
+	assertNotRequiredFlag(t, cmd, "task")
+	assertNotRequiredFlag(t, cmd, "model")
+}
+
+// --- Task Command ---
+
+func TestTaskListCmdFlags(t *testing.T) {
+	cmd := newTaskListCmd()
+
+	assertFlag(t, cmd, "for", "", "")
+	assertRequiredFlag(t, cmd, "for")
+}
+
+func TestTaskGetCmdFlags(t *testing.T) {
+	cmd := newTaskGetCmd()
+
+	assertFlag(t, cmd, "for", "", "")
+	assertRequiredFlag(t, cmd, "for")
+}
+
 // --- API Key Command ---
 
 func TestApiKeyListCmdFlags(t *testing.T) {
@@ -268,6 +375,7 @@ func TestDeploymentSlotUpdateCmdFlags(t *testing.T) {
 	assertFlag(t, cmd, "id", "i", "")
 	assertFlag(t, cmd, "name", "n", "")
 	assertFlag(t, cmd, "trained-model", "", "")
+	assertFlag(t, cmd, "is-active", "", "")
 	assertRequiredFlag(t, cmd, "id")
 	assertNotRequiredFlag(t, cmd, "name")
 }
@@ -552,8 +660,51 @@ func TestTrainingDataUploadCmdFlags(t *testing.T) {
 
 	assertFlag(t, cmd, "project", "p", "")
 	assertFlag(t, cmd, "file", "f", "")
+	assertFlag(t, cmd, "input-format", "", "")
+	assertFlag(t, cmd, "map", "", "[]")
+	assertFlag(t, cmd, "pseudonymize", "", "")
+	assertFlag(t, cmd, "on-duplicate", "", "skip")
 	assertRequiredFlag(t, cmd, "project")
 	assertRequiredFlag(t, cmd, "file")
+	assertNotRequiredFlag(t, cmd, "input-format")
+	assertNotRequiredFlag(t, cmd, "map")
+	assertNotRequiredFlag(t, cmd, "pseudonymize")
+}
+
+func TestTrainingDataAppendCmdFlags(t *testing.T) {
+	cmd := newTrainingDataAppendCmd()
+
+	assertFlag(t, cmd, "project", "", "")
+	assertFlag(t, cmd, "base", "", "")
+	assertFlag(t, cmd, "file", "f", "")
+	assertFlag(t, cmd, "input-format", "", "")
+	assertFlag(t, cmd, "retention", "", "")
+	assertFlag(t, cmd, "user-column", "", "")
+	assertRequiredFlag(t, cmd, "project")
+	assertRequiredFlag(t, cmd, "base")
+	assertRequiredFlag(t, cmd, "file")
+	assertNotRequiredFlag(t, cmd, "retention")
+}
+
+func TestTrainingDataDiffCmdFlags(t *testing.T) {
+	cmd := newTrainingDataDiffCmd()
+
+	assertFlag(t, cmd, "project", "", "")
+	assertFlag(t, cmd, "user-column", "", "")
+	assertFlag(t, cmd, "item-column", "", "")
+	assertFlag(t, cmd, "time-column", "", "")
+	assertFlag(t, cmd, "top", "", "10")
+	assertNotRequiredFlag(t, cmd, "top")
+	if err := cmd.Args(cmd, []string{"1"}); err == nil {
+		t.Error("expected an error with a single argument")
+	}
+}
+
+func TestTrainingDataVersionsCmdFlags(t *testing.T) {
+	cmd := newTrainingDataVersionsCmd()
+
+	assertFlag(t, cmd, "project", "p", "")
+	assertRequiredFlag(t, cmd, "project")
 }
 
 func TestTrainingDataDeleteCmdFlags(t *testing.T) {
@@ -576,7 +727,44 @@ func TestTrainingDataPreviewCmdFlags(t *testing.T) {
 	cmd := newTrainingDataPreviewCmd()
 
 	assertFlag(t, cmd, "id", "i", "")
+	assertFlag(t, cmd, "rows", "n", "0")
+	assertFlag(t, cmd, "columns", "", "[]")
+	assertFlag(t, cmd, "summary", "", "false")
 	assertRequiredFlag(t, cmd, "id")
+	assertNotRequiredFlag(t, cmd, "rows")
+	assertNotRequiredFlag(t, cmd, "columns")
+}
+
+func TestTrainingDataStatsCmdFlags(t *testing.T) {
+	cmd := newTrainingDataStatsCmd()
+
+	assertFlag(t, cmd, "file", "f", "")
+	assertFlag(t, cmd, "id", "i", "")
+	assertFlag(t, cmd, "input-format", "", "")
+	assertFlag(t, cmd, "project", "", "")
+	assertFlag(t, cmd, "user-column", "", "")
+	assertFlag(t, cmd, "item-column", "", "")
+	assertFlag(t, cmd, "time-column", "", "")
+	assertNotRequiredFlag(t, cmd, "file")
+	assertNotRequiredFlag(t, cmd, "id")
+}
+
+func TestTrainingDataPrepareCmdFlags(t *testing.T) {
+	cmd := newTrainingDataPrepareCmd()
+
+	assertFlag(t, cmd, "file", "f", "")
+	assertFlag(t, cmd, "id", "i", "")
+	assertFlag(t, cmd, "project", "", "")
+	assertFlag(t, cmd, "since", "", "")
+	assertFlag(t, cmd, "until", "", "")
+	assertFlag(t, cmd, "last", "", "")
+	assertFlag(t, cmd, "dedup", "", "false")
+	assertFlag(t, cmd, "sample-users", "", "0")
+	assertFlag(t, cmd, "seed", "", "0")
+	assertFlag(t, cmd, "min-user-interactions", "", "0")
+	assertFlag(t, cmd, "min-item-interactions", "", "0")
+	assertFlag(t, cmd, "output", "O", "")
+	assertFlag(t, cmd, "upload", "", "false")
 }
 
 // --- Item Meta Data Command ---
@@ -595,8 +783,39 @@ func TestItemMetaDataUploadCmdFlags(t *testing.T) {
 
 	assertFlag(t, cmd, "project", "p", "")
 	assertFlag(t, cmd, "file", "f", "")
+	assertFlag(t, cmd, "input-format", "", "")
+	assertFlag(t, cmd, "map", "", "[]")
+	assertFlag(t, cmd, "pseudonymize", "", "")
+	assertFlag(t, cmd, "on-duplicate", "", "skip")
 	assertRequiredFlag(t, cmd, "project")
 	assertRequiredFlag(t, cmd, "file")
+	assertNotRequiredFlag(t, cmd, "input-format")
+	assertNotRequiredFlag(t, cmd, "map")
+	assertNotRequiredFlag(t, cmd, "pseudonymize")
+	assertFlag(t, cmd, "skip-validation", "", "false")
+}
+
+func TestItemMetaDataInspectCmdFlags(t *testing.T) {
+	cmd := newItemMetaDataInspectCmd()
+
+	assertFlag(t, cmd, "rows", "n", "5")
+	if err := cmd.Args(cmd, []string{}); err == nil {
+		t.Error("expected an error without an ID argument")
+	}
+}
+
+func TestItemMetaDataCoverageCmdFlags(t *testing.T) {
+	cmd := newItemMetaDataCoverageCmd()
+
+	assertFlag(t, cmd, "id", "i", "")
+	assertFlag(t, cmd, "file", "f", "")
+	assertFlag(t, cmd, "input-format", "", "")
+	assertFlag(t, cmd, "training-data", "", "")
+	assertFlag(t, cmd, "item-column", "", "")
+	assertFlag(t, cmd, "sample", "", "10")
+	assertRequiredFlag(t, cmd, "training-data")
+	assertNotRequiredFlag(t, cmd, "id")
+	assertNotRequiredFlag(t, cmd, "file")
 }
 
 func TestItemMetaDataDeleteCmdFlags(t *testing.T) {
@@ -672,6 +891,7 @@ func TestModelConfigurationCreateCmdFlags(t *testing.T) {
 	assertRequiredFlag(t, cmd, "recommender-class-name")
 	assertRequiredFlag(t, cmd, "parameters-json")
 	assertNotRequiredFlag(t, cmd, "name")
+	assertFlag(t, cmd, "no-validate", "", "false")
 }
 
 func TestModelConfigurationDeleteCmdFlags(t *testing.T) {
@@ -688,9 +908,20 @@ func TestModelConfigurationUpdateCmdFlags(t *testing.T) {
 	assertFlag(t, cmd, "name", "n", "")
 	assertFlag(t, cmd, "recommender-class-name", "", "")
 	assertFlag(t, cmd, "parameters-json", "", "")
+	assertFlag(t, cmd, "no-validate", "", "false")
 	assertRequiredFlag(t, cmd, "id")
 }
 
+func TestModelConfigurationEditCmdFlags(t *testing.T) {
+	cmd := newModelConfigurationEditCmd()
+
+	assertFlag(t, cmd, "set", "", "[]")
+	assertFlag(t, cmd, "set-file", "", "")
+	assertFlag(t, cmd, "no-validate", "", "false")
+	assertNotRequiredFlag(t, cmd, "set")
+	assertNotRequiredFlag(t, cmd, "set-file")
+}
+
 // --- Split Config Command ---
 
 func TestSplitConfigListCmdFlags(t *testing.T) {
@@ -752,11 +983,15 @@ func TestParameterTuningJobCreateCmdFlags(t *testing.T) {
 	assertFlag(t, cmd, "data", "d", "")
 	assertFlag(t, cmd, "split", "s", "")
 	assertFlag(t, cmd, "evaluation", "e", "")
-	assertRequiredFlag(t, cmd, "data")
-	assertRequiredFlag(t, cmd, "split")
-	assertRequiredFlag(t, cmd, "evaluation")
+	assertFlag(t, cmd, "file", "f", "")
+	assertFlag(t, cmd, "preset", "", "")
+	// Data, split and evaluation may come from the job spec file instead.
+	assertNotRequiredFlag(t, cmd, "data")
+	assertNotRequiredFlag(t, cmd, "split")
+	assertNotRequiredFlag(t, cmd, "evaluation")
 
 	// Optional flags
+	assertFlag(t, cmd, "algorithms", "", "[]")
 	assertFlag(t, cmd, "n-tasks-parallel", "", "")
 	assertFlag(t, cmd, "n-trials", "", "")
 	assertFlag(t, cmd, "memory-budget", "", "")
@@ -771,6 +1006,55 @@ func TestParameterTuningJobCreateCmdFlags(t *testing.T) {
 	assertFlag(t, cmd, "best-config", "", "")
 }
 
+func TestParameterTuningJobGetCmdFlags(t *testing.T) {
+	cmd := newParameterTuningJobGetCmd()
+
+	assertFlag(t, cmd, "id", "i", "")
+	assertRequiredFlag(t, cmd, "id")
+}
+
+func TestParameterTuningJobWatchCmdFlags(t *testing.T) {
+	cmd := newParameterTuningJobWatchCmd()
+
+	assertFlag(t, cmd, "id", "i", "")
+	assertFlag(t, cmd, "interval", "", "5s")
+	assertRequiredFlag(t, cmd, "id")
+	assertNotRequiredFlag(t, cmd, "interval")
+}
+
+func TestParameterTuningJobWaitCmdFlags(t *testing.T) {
+	cmd := newParameterTuningJobWaitCmd()
+
+	assertFlag(t, cmd, "id", "i", "")
+	assertFlag(t, cmd, "interval", "", "5s")
+	assertFlag(t, cmd, "timeout", "", "0s")
+	assertRequiredFlag(t, cmd, "id")
+	assertNotRequiredFlag(t, cmd, "timeout")
+}
+
+func TestParameterTuningJobLeaderboardCmdFlags(t *testing.T) {
+	cmd := newParameterTuningJobLeaderboardCmd()
+
+	assertFlag(t, cmd, "project", "", "")
+	assertFlag(t, cmd, "export", "", "")
+	assertFlag(t, cmd, "name", "n", "")
+	assertNotRequiredFlag(t, cmd, "project")
+	assertNotRequiredFlag(t, cmd, "export")
+}
+
+func TestParameterTuningJobRerunCmdFlags(t *testing.T) {
+	cmd := newParameterTuningJobRerunCmd()
+
+	assertFlag(t, cmd, "data", "d", "")
+	assertFlag(t, cmd, "n-trials", "", "")
+	assertFlag(t, cmd, "algorithms", "", "[]")
+	assertFlag(t, cmd, "wait", "w", "false")
+	assertFlag(t, cmd, "interval", "", "5s")
+	assertFlag(t, cmd, "timeout", "", "0s")
+	assertNotRequiredFlag(t, cmd, "data")
+	assertNotRequiredFlag(t, cmd, "wait")
+}
+
 func TestParameterTuningJobDeleteCmdFlags(t *testing.T) {
 	cmd := newParameterTuningJobDeleteCmd()
 
@@ -778,6 +1062,20 @@ func TestParameterTuningJobDeleteCmdFlags(t *testing.T) {
 	assertRequiredFlag(t, cmd, "id")
 }
 
+// --- Pipeline Command ---
+
+func TestPipelineRunCmdFlags(t *testing.T) {
+	cmd := newPipelineRunCmd()
+
+	assertFlag(t, cmd, "file", "f", "")
+	assertFlag(t, cmd, "state", "", "")
+	assertFlag(t, cmd, "restart", "", "false")
+	assertFlag(t, cmd, "interval", "", "10s")
+	assertFlag(t, cmd, "timeout", "", "0s")
+	assertRequiredFlag(t, cmd, "file")
+	assertNotRequiredFlag(t, cmd, "state")
+}
+
 // --- Completion Command ---
 
 func TestCompletionCmdStructure(t *testing.T) {
@@ -809,3 +1107,32 @@ func TestCompletionCmdStructure(t *testing.T) {
 		}
 	}
 }
+
+// --- Plan / Apply / Drift Commands ---
+
+func TestPlanCmdFlags(t *testing.T) {
+	cmd := newPlanCmd()
+
+	assertFlag(t, cmd, "file", "f", "")
+	assertFlag(t, cmd, "prune", "", "false")
+	assertRequiredFlag(t, cmd, "file")
+	assertNotRequiredFlag(t, cmd, "prune")
+}
+
+func TestApplyCmdFlags(t *testing.T) {
+	cmd := newApplyCmd()
+
+	assertFlag(t, cmd, "file", "f", "")
+	assertFlag(t, cmd, "prune", "", "false")
+	assertRequiredFlag(t, cmd, "file")
+	assertNotRequiredFlag(t, cmd, "prune")
+}
+
+func TestDriftCmdFlags(t *testing.T) {
+	cmd := newDriftCmd()
+
+	assertFlag(t, cmd, "file", "f", "")
+	assertFlag(t, cmd, "include-unmanaged", "", "false")
+	assertRequiredFlag(t, cmd, "file")
+	assertNotRequiredFlag(t, cmd, "include-unmanaged")
+}
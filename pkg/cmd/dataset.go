@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/dataset"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+// dataSource selects either a local file or a training data ID as the input
+// of a local analysis command.
+type dataSource struct {
+	file        string
+	id          string
+	inputFormat string
+}
+
+func (s *dataSource) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&s.file, "file", "f", "", "Local file path")
+	cmd.Flags().StringVarP(&s.id, "id", "i", "", "Training data ID to download")
+	cmd.Flags().StringVar(&s.inputFormat, "input-format", "", "Input format (parquet, jsonl, csv, tsv); detected from the file name if omitted")
+	cmd.MarkFlagsMutuallyExclusive("file", "id")
+	cmd.MarkFlagsOneRequired("file", "id")
+}
+
+// open returns a reader over the selected input. For a training data ID the
+// file is downloaded to a temporary location first; the returned cleanup
+// function closes the reader and removes any downloaded file.
+func (s *dataSource) open(client func() (api.Client, error)) (dataset.Reader, *openapi.TrainingData, func(), error) {
+	open, td, remove, err := s.opener(client)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	r, err := open()
+	if err != nil {
+		remove()
+		return nil, nil, nil, err
+	}
+	return r, td, func() { r.Close(); remove() }, nil
+}
+
+// opener is like open but returns a function that opens a fresh reader on
+// every call, for commands that need several passes over the input.
+func (s *dataSource) opener(client func() (api.Client, error)) (func() (dataset.Reader, error), *openapi.TrainingData, func(), error) {
+	format, err := dataset.ParseFormat(s.inputFormat)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	path, td, remove := s.file, (*openapi.TrainingData)(nil), func() {}
+	if s.file == "" {
+		id, err := strconv.Atoi(s.id)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		c, err := client()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if path, td, remove, err = downloadTrainingData(c, id); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	open := func() (dataset.Reader, error) {
+		return dataset.Open(path, format)
+	}
+	return open, td, remove, nil
+}
+
+// downloadTrainingData fetches training data into a temporary directory,
+// keeping its original basename so the format can be detected from it.
+func downloadTrainingData(client api.Client, id int) (string, *openapi.TrainingData, func(), error) {
+	td, err := findTrainingData(client, id)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if td == nil {
+		return "", nil, nil, fmt.Errorf("training data %d not found", id)
+	}
+	path, remove, err := downloadTemp(td.Basename, fmt.Sprintf("training-data-%d.csv", id), func(path string) error {
+		return client.DownloadTrainingData(id, path)
+	})
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return path, td, remove, nil
+}
+
+// downloadItemMetaData is downloadTrainingData for item meta data.
+func downloadItemMetaData(client api.Client, id int) (string, *openapi.ItemMetaData, func(), error) {
+	imd, err := findItemMetaData(client, id)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if imd == nil {
+		return "", nil, nil, fmt.Errorf("item meta data %d not found", id)
+	}
+	path, remove, err := downloadTemp(imd.Basename, fmt.Sprintf("item-meta-data-%d.csv", id), func(path string) error {
+		return client.DownloadItemMetaData(id, path)
+	})
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return path, imd, remove, nil
+}
+
+// downloadTemp downloads into a new temporary directory under basename, or
+// fallback if the basename is unusable. remove deletes the directory.
+func downloadTemp(basename *string, fallback string, download func(path string) error) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "recotem-")
+	if err != nil {
+		return "", nil, err
+	}
+	remove := func() { os.RemoveAll(dir) }
+	name := filepath.Base(utils.Atoa(basename))
+	if basename == nil || name == "." || name == string(filepath.Separator) {
+		name = fallback
+	}
+	path := filepath.Join(dir, name)
+	if err := download(path); err != nil {
+		remove()
+		return "", nil, err
+	}
+	return path, remove, nil
+}
+
+// schemaFlags resolves the user, item and time columns of a data file,
+// either from explicit flags or from the project the data belongs to.
+type schemaFlags struct {
+	project    string
+	userColumn string
+	itemColumn string
+	timeColumn string
+}
+
+func (f *schemaFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.project, "project", "", "Project ID to read user/item/time columns from")
+	cmd.Flags().StringVar(&f.userColumn, "user-column", "", "User column (overrides the project)")
+	cmd.Flags().StringVar(&f.itemColumn, "item-column", "", "Item column (overrides the project)")
+	cmd.Flags().StringVar(&f.timeColumn, "time-column", "", "Time column (overrides the project)")
+}
+
+// resolve builds the schema. projectID is used when --project is not given,
+// typically the project of a downloaded training data file.
+func (f *schemaFlags) resolve(client func() (api.Client, error), projectID *int) (dataset.Schema, error) {
+	schema := dataset.Schema{
+		UserColumn: f.userColumn,
+		ItemColumn: f.itemColumn,
+		TimeColumn: f.timeColumn,
+	}
+	if f.project != "" {
+		id, err := strconv.Atoi(f.project)
+		if err != nil {
+			return schema, err
+		}
+		projectID = &id
+	}
+	if projectID != nil && (schema.UserColumn == "" || schema.ItemColumn == "" || schema.TimeColumn == "") {
+		c, err := client()
+		if err != nil {
+			return schema, err
+		}
+		project, err := getProject(c, *projectID)
+		if err != nil {
+			return schema, err
+		}
+		if schema.UserColumn == "" {
+			schema.UserColumn = project.UserColumn
+		}
+		if schema.ItemColumn == "" {
+			schema.ItemColumn = project.ItemColumn
+		}
+		if schema.TimeColumn == "" && project.TimeColumn != nil {
+			schema.TimeColumn = *project.TimeColumn
+		}
+	}
+	if schema.UserColumn == "" || schema.ItemColumn == "" {
+		return schema, fmt.Errorf("user and item columns are required (use --project or --user-column/--item-column)")
+	}
+	return schema, nil
+}
+
+// findTrainingData returns nil if there is no training data with the ID.
+func findTrainingData(client api.Client, id int) (*openapi.TrainingData, error) {
+	list, err := client.GetTrainingData(&id, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if list.Results == nil || len(*list.Results) == 0 {
+		return nil, nil
+	}
+	return &(*list.Results)[0], nil
+}
+
+// findItemMetaData returns nil if there is no item meta data with the ID.
+func findItemMetaData(client api.Client, id int) (*openapi.ItemMetaData, error) {
+	list, err := client.GetItemMetaData(&id, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if list.Results == nil || len(*list.Results) == 0 {
+		return nil, nil
+	}
+	return &(*list.Results)[0], nil
+}
+
+func getProject(client api.Client, id int) (*openapi.Project, error) {
+	projects, err := client.GetProjects(&id, nil)
+	if err != nil {
+		return nil, err
+	}
+	if projects == nil || len(*projects) == 0 {
+		return nil, fmt.Errorf("project %d not found", id)
+	}
+	return &(*projects)[0], nil
+}
+
+// lazyClient returns a constructor that creates the API client on first use,
+// so purely local commands never require a configured server.
+func lazyClient(cmd *cobra.Command) func() (api.Client, error) {
+	var client *api.Client
+	return func() (api.Client, error) {
+		if client != nil {
+			return *client, nil
+		}
+		c, err := newClientFromCmd(cmd)
+		if err != nil {
+			return api.Client{}, err
+		}
+		client = &c
+		return c, nil
+	}
+}
@@ -132,7 +132,7 @@ func newDeploymentSlotGetCmd() *cobra.Command {
 }
 
 func newDeploymentSlotUpdateCmd() *cobra.Command {
-	var id, name, trainedModel string
+	var id, name, trainedModel, isActive string
 
 	cmd := &cobra.Command{
 		Use:   "update",
@@ -149,7 +149,8 @@ func newDeploymentSlotUpdateCmd() *cobra.Command {
 			slot, err := client.UpdateDeploymentSlot(
 				idInt,
 				utils.NilOrString(name),
-				utils.NilOrInt(trainedModel))
+				utils.NilOrInt(trainedModel),
+				utils.NilOrBool(isActive))
 			if err != nil {
 				return err
 			}
@@ -161,6 +162,7 @@ func newDeploymentSlotUpdateCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&id, "id", "i", "", "Deployment slot ID")
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Slot name")
 	cmd.Flags().StringVar(&trainedModel, "trained-model", "", "Trained model ID")
+	cmd.Flags().StringVar(&isActive, "is-active", "", "Is active (true/false)")
 	_ = cmd.MarkFlagRequired("id")
 
 	return cmd
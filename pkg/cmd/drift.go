@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/manifest"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+func newDriftCmd() *cobra.Command {
+	var file string
+	var includeUnmanaged bool
+
+	cmd := &cobra.Command{
+		Use:   "drift",
+		Short: "Report differences between a manifest and the server",
+		Long: "Fetch the current state of every resource declared in a manifest and report\n" +
+			"the fields that differ from it, such as RetrainingSchedule.cron_expression or\n" +
+			"DeploymentSlot.trained_model, and declared resources that are missing.\n" +
+			"Exits with a non-zero status when drift is found, so it can run as a scheduled check.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := manifest.Load(file)
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			state, err := fetchManifestState(client, m)
+			if err != nil {
+				return err
+			}
+			diffs := manifest.Drift(manifest.Compute(m, state, includeUnmanaged))
+			printDrift(getOutputFormat(), diffs)
+			if len(diffs) > 0 {
+				return fmt.Errorf("drift detected: %d difference(s)", len(diffs))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Manifest file (YAML)")
+	cmd.Flags().BoolVar(&includeUnmanaged, "include-unmanaged", false,
+		"Also report resources of declared projects that the manifest does not list")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func printDrift(format string, diffs []manifest.Difference) {
+	if format == "json" || format == "yaml" {
+		utils.PrintOutput(format, map[string]any{
+			"drift":       len(diffs) > 0,
+			"differences": diffs,
+		})
+		return
+	}
+	if len(diffs) == 0 {
+		fmt.Println("No drift. The server matches the manifest.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tKIND\tRESOURCE\tFIELD\tLIVE\tDECLARED")
+	for _, d := range diffs {
+		field, live, declared := d.Field, planValue(d.Live), planValue(d.Declared)
+		switch d.Status {
+		case manifest.DriftMissing:
+			field, live, declared = "-", "<missing>", "declared"
+		case manifest.DriftUnmanaged:
+			field, live, declared = "-", "id "+utils.Itoa(d.ID), "<not declared>"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", d.Status, d.Kind, d.Path, field, live, declared)
+	}
+	_ = w.Flush()
+}
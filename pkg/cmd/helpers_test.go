@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/cfg"
+)
+
+// newTestServer creates a httptest server and a Client pointing at it.
+func newTestServer(handler http.HandlerFunc) (*httptest.Server, api.Client) {
+	server := httptest.NewServer(handler)
+	config := cfg.RecotemConfig{
+		Url:         server.URL,
+		AccessToken: "test-token",
+	}
+	return server, api.NewClient(context.Background(), config)
+}
+
+// jsonResponse writes a JSON response with the given status code and body.
+func jsonResponse(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 
 	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/ledger"
 	"recotem.org/cli/recotem/pkg/openapi"
 	"recotem.org/cli/recotem/pkg/utils"
 )
@@ -21,6 +23,8 @@ func newItemMetaDataCmd() *cobra.Command {
 		newItemMetaDataUploadCmd(),
 		newItemMetaDataDeleteCmd(),
 		newItemMetaDataDownloadCmd(),
+		newItemMetaDataInspectCmd(),
+		newItemMetaDataCoverageCmd(),
 	)
 
 	return cmd
@@ -61,7 +65,9 @@ func newItemMetaDataListCmd() *cobra.Command {
 }
 
 func newItemMetaDataUploadCmd() *cobra.Command {
-	var project, file string
+	var project string
+	var source uploadSource
+	var skipValidation bool
 
 	cmd := &cobra.Command{
 		Use:   "upload",
@@ -75,7 +81,34 @@ func newItemMetaDataUploadCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			itemMetaData, err := client.UploadItemMetaData(id, file)
+			book, err := loadLedger(client)
+			if err != nil {
+				return err
+			}
+			sha, err := ledger.HashFile(source.file)
+			if err != nil {
+				return err
+			}
+			dup, existing, err := findDuplicate(book, ledger.KindItemMetaData, id, sha, source.options(),
+				func(x int) (*openapi.ItemMetaData, error) { return findItemMetaData(client, x) },
+				func(x *openapi.ItemMetaData) (*string, *int) { return x.Basename, x.Filesize })
+			if err != nil {
+				return err
+			}
+			if skip, err := checkDuplicate(source.onDuplicate, dup, source.file); err != nil {
+				return err
+			} else if skip {
+				printItemMetaData(getOutputFormat(), *existing)
+				return nil
+			}
+
+			if !skipValidation {
+				if err := source.checkItemMetaData(client, id, os.Stderr); err != nil {
+					return fmt.Errorf("%w (use --skip-validation to upload anyway)", err)
+				}
+			}
+
+			itemMetaData, err := source.uploadItemMetaData(client, book, id, sha)
 			if err != nil {
 				return err
 			}
@@ -85,7 +118,8 @@ func newItemMetaDataUploadCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&project, "project", "p", "", "Project ID")
-	cmd.Flags().StringVarP(&file, "file", "f", "", "File path")
+	source.addFlags(cmd)
+	cmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Upload without checking item IDs and columns first")
 	_ = cmd.MarkFlagRequired("project")
 	_ = cmd.MarkFlagRequired("file")
 
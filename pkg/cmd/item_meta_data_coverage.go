@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/dataset"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+func newItemMetaDataCoverageCmd() *cobra.Command {
+	var id, file, inputFormat, trainingData, itemColumn string
+	var sample int
+
+	cmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "Report how well item meta data covers the items in training data",
+		Long: "Compare item meta data (uploaded, by --id, or a local --file) with the items\n" +
+			"of a training data file: the share of interacted items that have metadata,\n" +
+			"duplicate item IDs and columns that are always empty.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if sample < 0 {
+				return fmt.Errorf("--sample must not be negative")
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			tdID, err := strconv.Atoi(trainingData)
+			if err != nil {
+				return err
+			}
+			tdPath, td, removeTD, err := downloadTrainingData(client, tdID)
+			if err != nil {
+				return err
+			}
+			defer removeTD()
+
+			metaPath, format := file, dataset.Format("")
+			if file == "" {
+				metaID, err := strconv.Atoi(id)
+				if err != nil {
+					return err
+				}
+				path, _, remove, err := downloadItemMetaData(client, metaID)
+				if err != nil {
+					return err
+				}
+				defer remove()
+				metaPath = path
+			} else if format, err = dataset.ParseFormat(inputFormat); err != nil {
+				return err
+			}
+
+			if itemColumn == "" {
+				project, err := getProject(client, td.Project)
+				if err != nil {
+					return err
+				}
+				itemColumn = project.ItemColumn
+			}
+			meta, err := dataset.Open(metaPath, format)
+			if err != nil {
+				return err
+			}
+			defer meta.Close()
+			training, err := dataset.Open(tdPath, "")
+			if err != nil {
+				return err
+			}
+			defer training.Close()
+
+			report, err := dataset.AnalyzeItemMetadata(meta, itemColumn, training, itemColumn, sample)
+			if err != nil {
+				return err
+			}
+			printItemMetadataReport(getOutputFormat(), report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&id, "id", "i", "", "Item meta data ID")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Local item meta data file")
+	cmd.Flags().StringVar(&inputFormat, "input-format", "", "Input format of --file (parquet, jsonl, csv, tsv); detected from the file name if omitted")
+	cmd.Flags().StringVar(&trainingData, "training-data", "", "Training data ID")
+	cmd.Flags().StringVar(&itemColumn, "item-column", "", "Item column (overrides the project)")
+	cmd.Flags().IntVar(&sample, "sample", 10, "Number of missing and duplicate item IDs to list")
+	cmd.MarkFlagsMutuallyExclusive("id", "file")
+	cmd.MarkFlagsOneRequired("id", "file")
+	_ = cmd.MarkFlagRequired("training-data")
+
+	return cmd
+}
+
+func printItemMetadataReport(format string, r *dataset.ItemMetadataReport) {
+	if format == "json" || format == "yaml" {
+		utils.PrintOutput(format, r)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if c := r.Coverage; c != nil {
+		fmt.Fprintf(w, "Coverage:\t%.1f%% (%d of %d interacted items)\n", c.Ratio*100, c.Covered, c.InteractedItems)
+		if len(c.MissingSample) > 0 {
+			fmt.Fprintf(w, "Missing items:\t%s\n", sampleList(c.MissingSample, c.InteractedItems-c.Covered))
+		}
+	}
+	fmt.Fprintf(w, "Rows:\t%d\n", r.Rows)
+	fmt.Fprintf(w, "Items:\t%d\n", r.Items)
+	fmt.Fprintf(w, "Rows without item ID:\t%d\n", r.MissingItemID)
+	fmt.Fprintf(w, "Duplicate item IDs:\t%d\n", r.DuplicateItems)
+	if len(r.DuplicateSample) > 0 {
+		fmt.Fprintf(w, "Duplicates:\t%s\n", sampleList(r.DuplicateSample, r.DuplicateItems))
+	}
+	if len(r.EmptyColumns) > 0 {
+		fmt.Fprintf(w, "Empty columns:\t%s\n", strings.Join(r.EmptyColumns, ", "))
+	}
+	w.Flush()
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COLUMN\tFILLED")
+	for _, c := range r.Columns {
+		ratio := 0.0
+		if r.Rows > 0 {
+			ratio = float64(c.Filled) / float64(r.Rows) * 100
+		}
+		fmt.Fprintf(w, "%s\t%d (%.1f%%)\n", c.Name, c.Filled, ratio)
+	}
+	w.Flush()
+}
+
+// validateItemMetaData checks a file before upload. Rows without an item ID
+// are an error; duplicate IDs and empty columns are reported as warnings.
+func validateItemMetaData(r dataset.Reader, itemColumn string, warn io.Writer) error {
+	report, err := dataset.AnalyzeItemMetadata(r, itemColumn, nil, "", 5)
+	if err != nil {
+		return err
+	}
+	if report.Rows == 0 {
+		return fmt.Errorf("item meta data has no rows")
+	}
+	if report.MissingItemID > 0 {
+		return fmt.Errorf("%d rows have no %s", report.MissingItemID, itemColumn)
+	}
+	if report.DuplicateItems > 0 {
+		fmt.Fprintf(warn, "warning: %d duplicate item IDs (%s)\n", report.DuplicateItems, sampleList(report.DuplicateSample, report.DuplicateItems))
+	}
+	if len(report.EmptyColumns) > 0 {
+		fmt.Fprintf(warn, "warning: empty columns: %s\n", strings.Join(report.EmptyColumns, ", "))
+	}
+	return nil
+}
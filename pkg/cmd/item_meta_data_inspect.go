@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/dataset"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+func newItemMetaDataInspectCmd() *cobra.Command {
+	var rows int
+
+	cmd := &cobra.Command{
+		Use:   "inspect <id>",
+		Short: "Show the valid columns and sample rows of item meta data",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid item meta data ID %q", args[0])
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			path, imd, remove, err := downloadItemMetaData(client, id)
+			if err != nil {
+				return err
+			}
+			defer remove()
+
+			r, err := dataset.Open(path, "")
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			sample := [][]string{}
+			for len(sample) < rows {
+				row, err := r.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return err
+				}
+				sample = append(sample, row)
+			}
+
+			validColumns := parseValidColumns(imd.ValidColumnsListJson)
+			format := getOutputFormat()
+			if format == "json" || format == "yaml" {
+				records := make([]map[string]string, len(sample))
+				for i, row := range sample {
+					records[i] = map[string]string{}
+					for j, c := range r.Columns() {
+						records[i][c] = row[j]
+					}
+				}
+				utils.PrintOutput(format, map[string]any{
+					"id":            imd.Id,
+					"basename":      utils.Atoa(imd.Basename),
+					"filesize":      imd.Filesize,
+					"ins_datetime":  utils.FormatTime(imd.InsDatetime),
+					"valid_columns": validColumns,
+					"columns":       r.Columns(),
+					"rows":          records,
+				})
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "ID:\t%s\n", utils.Itoa(imd.Id))
+			fmt.Fprintf(w, "Basename:\t%s\n", utils.Atoa(imd.Basename))
+			fmt.Fprintf(w, "Filesize:\t%s\n", utils.Itoa(imd.Filesize))
+			fmt.Fprintf(w, "Uploaded:\t%s\n", utils.FormatTime(imd.InsDatetime))
+			fmt.Fprintf(w, "Valid columns:\t%s\n", strings.Join(validColumns, ", "))
+			w.Flush()
+			fmt.Println()
+			w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, strings.Join(r.Columns(), "\t"))
+			for _, row := range sample {
+				fmt.Fprintln(w, strings.Join(row, "\t"))
+			}
+			w.Flush()
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&rows, "rows", "n", 5, "Number of sample rows to show")
+
+	return cmd
+}
+
+// parseValidColumns decodes valid_columns_list_json. A value that is not a
+// JSON list of strings is returned as a single entry rather than dropped.
+func parseValidColumns(s *string) []string {
+	columns := []string{}
+	if s == nil || *s == "" {
+		return columns
+	}
+	if err := json.Unmarshal([]byte(*s), &columns); err != nil {
+		return []string{*s}
+	}
+	return columns
+}
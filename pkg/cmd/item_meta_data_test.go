@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"recotem.org/cli/recotem/pkg/dataset"
+)
+
+func TestParseValidColumns(t *testing.T) {
+	valid := `["title", "genre"]`
+	invalid := "title,genre"
+	empty := ""
+	tests := []struct {
+		in   *string
+		want []string
+	}{
+		{nil, []string{}},
+		{&empty, []string{}},
+		{&valid, []string{"title", "genre"}},
+		{&invalid, []string{"title,genre"}},
+	}
+	for _, tt := range tests {
+		if got := parseValidColumns(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseValidColumns(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidateItemMetaData(t *testing.T) {
+	open := func(content string) dataset.Reader {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "items.csv")
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		r, err := dataset.Open(path, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { r.Close() })
+		return r
+	}
+
+	var warn bytes.Buffer
+	if err := validateItemMetaData(open("item_id,title,genre\ni1,A,\ni1,B,\n"), "item_id", &warn); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !strings.Contains(warn.String(), "1 duplicate item IDs (i1)") || !strings.Contains(warn.String(), "empty columns: genre") {
+		t.Errorf("unexpected warnings %q", warn.String())
+	}
+
+	if err := validateItemMetaData(open("item_id,title\n,A\n"), "item_id", &warn); err == nil {
+		t.Error("expected error for rows without an item ID")
+	}
+	if err := validateItemMetaData(open("id,title\ni1,A\n"), "item_id", &warn); err == nil {
+		t.Error("expected error for a missing item column")
+	}
+	if err := validateItemMetaData(open("item_id,title\n"), "item_id", &warn); err == nil {
+		t.Error("expected error for an empty file")
+	}
+}
+
+func TestItemMetaDataCoverageRejectsNegativeSample(t *testing.T) {
+	cmd := newItemMetaDataCoverageCmd()
+	cmd.SetArgs([]string{"--file", "items.csv", "--training-data", "1", "--sample", "-1"})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--sample") {
+		t.Fatalf("expected a --sample error, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/cfg"
+	"recotem.org/cli/recotem/pkg/ledger"
+)
+
+const (
+	onDuplicateSkip = "skip"
+	onDuplicateWarn = "warn"
+)
+
+// loadLedger opens the upload ledger of the configured server:
+// ~/.recotem/ledger/<server>.json
+func loadLedger(client api.Client) (*ledger.Ledger, error) {
+	path, err := cfg.StateDir("ledger", client.Config.ServerKey()+".json")
+	if err != nil {
+		return nil, err
+	}
+	return ledger.Load(path)
+}
+
+// findDuplicate looks for an earlier upload of the same content to the
+// project that still exists on the server with the recorded basename and
+// size. Entries whose resource is gone or no longer matches are dropped
+// from the ledger. fetch returns nil if the resource does not exist.
+func findDuplicate[T any](book *ledger.Ledger, kind ledger.Kind, project int, sha, options string,
+	fetch func(id int) (*T, error), file func(*T) (*string, *int)) (*ledger.Entry, *T, error) {
+	for _, e := range book.Find(kind, project, sha, options) {
+		x, err := fetch(e.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if x != nil {
+			basename, filesize := file(x)
+			current := newLedgerEntry(kind, project, nil, basename, filesize)
+			if current.Basename == e.Basename && current.Filesize == e.Filesize {
+				return &e, x, nil
+			}
+			fmt.Fprintf(os.Stderr, "warning: %s %d no longer matches the ledger (basename %q, size %d); ignoring it\n",
+				kind, e.ID, current.Basename, current.Filesize)
+		}
+		book.Remove(kind, e.ID)
+	}
+	return nil, nil, nil
+}
+
+// checkDuplicate reports a duplicate upload according to --on-duplicate and
+// returns true if the upload should be skipped.
+func checkDuplicate(mode string, e *ledger.Entry, file string) (bool, error) {
+	if e == nil {
+		return false, nil
+	}
+	switch mode {
+	case onDuplicateSkip:
+		fmt.Fprintf(os.Stderr, "%s is identical to %s %d uploaded at %s; skipping (use --on-duplicate warn to upload anyway)\n",
+			file, e.Kind, e.ID, e.UploadedAt.Format(time.RFC3339))
+		return true, nil
+	case onDuplicateWarn:
+		fmt.Fprintf(os.Stderr, "warning: %s is identical to %s %d uploaded at %s; uploading again\n",
+			file, e.Kind, e.ID, e.UploadedAt.Format(time.RFC3339))
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid --on-duplicate %q (expected skip or warn)", mode)
+}
+
+// newLedgerEntry fills an entry from the fields of an uploaded resource.
+func newLedgerEntry(kind ledger.Kind, project int, id *int, basename *string, filesize *int) ledger.Entry {
+	e := ledger.Entry{Kind: kind, Project: project}
+	if id != nil {
+		e.ID = *id
+	}
+	if basename != nil {
+		e.Basename = *basename
+	}
+	if filesize != nil {
+		e.Filesize = *filesize
+	}
+	return e
+}
+
+// recordUpload adds an upload to the ledger. Failing to save the ledger
+// does not fail the command since the upload itself succeeded.
+func recordUpload(book *ledger.Ledger, e ledger.Entry) {
+	e.UploadedAt = time.Now().UTC()
+	book.Add(e)
+	if err := book.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not update the upload ledger: %v\n", err)
+	}
+}
+
+// ledgerOption returns the value of key in the options of a ledger entry,
+// such as "hmac" for "pseudonymize" in "input-format=jsonl pseudonymize=hmac".
+func ledgerOption(options, key string) string {
+	for _, field := range strings.Fields(options) {
+		if k, v, ok := strings.Cut(field, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"recotem.org/cli/recotem/pkg/ledger"
+	"recotem.org/cli/recotem/pkg/openapi"
+)
+
+func TestFindDuplicate(t *testing.T) {
+	book, _ := ledger.Load(filepath.Join(t.TempDir(), "ledger.json"))
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	book.Add(ledger.Entry{Kind: ledger.KindTrainingData, ID: 1, Project: 1, SHA256: "abc", Basename: "a.csv", Filesize: 10, UploadedAt: at})
+	book.Add(ledger.Entry{Kind: ledger.KindTrainingData, ID: 2, Project: 1, SHA256: "abc", Basename: "a.csv", Filesize: 10, UploadedAt: at.Add(time.Hour)})
+	book.Add(ledger.Entry{Kind: ledger.KindTrainingData, ID: 3, Project: 1, SHA256: "abc", Basename: "a.csv", Filesize: 10, UploadedAt: at.Add(2 * time.Hour)})
+
+	name, size, otherSize := "a.csv", 10, 99
+	server := map[int]*openapi.TrainingData{
+		// 3 was deleted; 2 now points at a different file.
+		2: {Basename: &name, Filesize: &otherSize},
+		1: {Basename: &name, Filesize: &size},
+	}
+	fetch := func(id int) (*openapi.TrainingData, error) { return server[id], nil }
+	file := func(x *openapi.TrainingData) (*string, *int) { return x.Basename, x.Filesize }
+
+	e, td, err := findDuplicate(book, ledger.KindTrainingData, 1, "abc", "", fetch, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e == nil || e.ID != 1 || td != server[1] {
+		t.Fatalf("expected training data 1, got %+v", e)
+	}
+	if versions := book.Versions(ledger.KindTrainingData, 1); len(versions) != 1 {
+		t.Errorf("stale entries should be removed, got %+v", versions)
+	}
+
+	if e, _, _ := findDuplicate(book, ledger.KindTrainingData, 1, "other", "", fetch, file); e != nil {
+		t.Errorf("expected no duplicate, got %+v", e)
+	}
+}
+
+func TestCheckDuplicate(t *testing.T) {
+	e := &ledger.Entry{Kind: ledger.KindTrainingData, ID: 1}
+	if skip, err := checkDuplicate(onDuplicateSkip, e, "a.csv"); err != nil || !skip {
+		t.Errorf("skip mode: got %v, %v", skip, err)
+	}
+	if skip, err := checkDuplicate(onDuplicateWarn, e, "a.csv"); err != nil || skip {
+		t.Errorf("warn mode: got %v, %v", skip, err)
+	}
+	if skip, err := checkDuplicate(onDuplicateSkip, nil, "a.csv"); err != nil || skip {
+		t.Errorf("no duplicate: got %v, %v", skip, err)
+	}
+	if _, err := checkDuplicate("ignore", e, "a.csv"); err == nil {
+		t.Error("expected error for an invalid mode")
+	}
+}
+
+func TestMergeVersions(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := at.Add(time.Hour)
+	parent := 1
+	entries := []ledger.Entry{
+		{Kind: ledger.KindTrainingData, ID: 1, Project: 1, SHA256: "aaa", UploadedAt: at},
+		{Kind: ledger.KindTrainingData, ID: 2, Project: 1, SHA256: "bbb", UploadedAt: later, Parent: &parent},
+	}
+	id1, id3 := 1, 3
+	name := "manual.csv"
+	serverTime := at.Add(30 * time.Minute)
+	server := []openapi.TrainingData{
+		{Id: &id1, Project: 1},
+		{Id: &id3, Project: 1, Basename: &name, InsDatetime: &serverTime},
+	}
+
+	versions := mergeVersions(entries, server)
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions, got %+v", versions)
+	}
+	want := []struct {
+		id     int
+		status string
+	}{{1, "ok"}, {3, "untracked"}, {2, "deleted"}}
+	for i, w := range want {
+		if versions[i].ID != w.id || versions[i].Status != w.status {
+			t.Errorf("versions[%d] = %d/%s, want %d/%s", i, versions[i].ID, versions[i].Status, w.id, w.status)
+		}
+	}
+	if versions[1].Basename != "manual.csv" {
+		t.Errorf("untracked version should keep the server basename, got %q", versions[1].Basename)
+	}
+}
+
+func TestCheckAppendPseudonyms(t *testing.T) {
+	book, _ := ledger.Load(filepath.Join(t.TempDir(), "ledger.json"))
+	book.Add(ledger.Entry{Kind: ledger.KindTrainingData, ID: 1, Project: 1, Options: "input-format=jsonl pseudonymize=hmac"})
+	book.Add(ledger.Entry{Kind: ledger.KindTrainingData, ID: 2, Project: 1, Options: "append pseudonymize=hmac retention=30d"})
+	book.Add(ledger.Entry{Kind: ledger.KindTrainingData, ID: 3, Project: 1})
+
+	tests := []struct {
+		base    int
+		mode    string
+		wantErr bool
+	}{
+		{1, "hmac", false},
+		{1, "", true},
+		{1, "sequential", true},
+		{2, "hmac", false},
+		{2, "", true},
+		{3, "", false},
+		{3, "hmac", true},
+		// Not in the ledger: nothing is known about the base.
+		{4, "", false},
+		{4, "hmac", false},
+	}
+	for _, tt := range tests {
+		err := checkAppendPseudonyms(book, tt.base, tt.mode)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("base %d, mode %q: got %v", tt.base, tt.mode, err)
+		}
+	}
+}
@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"strconv"
 
 	"github.com/spf13/cobra"
 	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/recommender"
 	"recotem.org/cli/recotem/pkg/utils"
 )
 
@@ -21,6 +23,7 @@ func newModelConfigurationCmd() *cobra.Command {
 		newModelConfigurationCreateCmd(),
 		newModelConfigurationDeleteCmd(),
 		newModelConfigurationUpdateCmd(),
+		newModelConfigurationEditCmd(),
 	)
 
 	return cmd
@@ -62,16 +65,26 @@ func newModelConfigurationListCmd() *cobra.Command {
 
 func newModelConfigurationCreateCmd() *cobra.Command {
 	var name, project, recommenderClassName, parametersJSON string
+	var noValidate bool
 
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a model configuration",
+		Long: "Create a model configuration. The recommender class and parameters are\n" +
+			"checked against the built-in catalog (see `recotem recommender list`) before\n" +
+			"anything is sent; --no-validate skips the check for classes the catalog does\n" +
+			"not know.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := newClientFromCmd(cmd)
+			projectID, err := strconv.Atoi(project)
 			if err != nil {
 				return err
 			}
-			projectID, err := strconv.Atoi(project)
+			if !noValidate {
+				if err := validateModelConfigurationJSON(recommenderClassName, parametersJSON, cmd.ErrOrStderr()); err != nil {
+					return err
+				}
+			}
+			client, err := newClientFromCmd(cmd)
 			if err != nil {
 				return err
 			}
@@ -90,6 +103,7 @@ func newModelConfigurationCreateCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&project, "project", "p", "", "Project ID")
 	cmd.Flags().StringVar(&recommenderClassName, "recommender-class-name", "", "Recommender class name")
 	cmd.Flags().StringVar(&parametersJSON, "parameters-json", "", "Parameters JSON")
+	cmd.Flags().BoolVar(&noValidate, "no-validate", false, "Skip checking against the built-in recommender catalog")
 	_ = cmd.MarkFlagRequired("project")
 	_ = cmd.MarkFlagRequired("recommender-class-name")
 	_ = cmd.MarkFlagRequired("parameters-json")
@@ -129,10 +143,14 @@ func newModelConfigurationDeleteCmd() *cobra.Command {
 
 func newModelConfigurationUpdateCmd() *cobra.Command {
 	var id, name, recommenderClassName, parametersJSON string
+	var noValidate bool
 
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "Update a model configuration",
+		Long: "Update a model configuration. A new recommender class or parameters are\n" +
+			"checked, together with the unchanged one, against the built-in catalog;\n" +
+			"--no-validate skips the check.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, err := newClientFromCmd(cmd)
 			if err != nil {
@@ -142,8 +160,27 @@ func newModelConfigurationUpdateCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if !noValidate && (recommenderClassName != "" || parametersJSON != "") {
+				class, params := recommenderClassName, parametersJSON
+				if class == "" || params == "" {
+					current, err := getModelConfiguration(client, idInt)
+					if err != nil {
+						return err
+					}
+					if class == "" {
+						class = current.RecommenderClassName
+					}
+					if params == "" {
+						params = current.ParametersJson
+					}
+				}
+				if err := validateModelConfigurationJSON(class, params, cmd.ErrOrStderr()); err != nil {
+					return err
+				}
+			}
 			mc, err := client.UpdateModelConfiguration(idInt,
 				utils.NilOrString(name),
+				utils.NilOrString(recommenderClassName),
 				utils.NilOrString(parametersJSON))
 			if err != nil {
 				return err
@@ -157,11 +194,35 @@ func newModelConfigurationUpdateCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Name")
 	cmd.Flags().StringVar(&recommenderClassName, "recommender-class-name", "", "Recommender class name")
 	cmd.Flags().StringVar(&parametersJSON, "parameters-json", "", "Parameters JSON")
+	cmd.Flags().BoolVar(&noValidate, "no-validate", false, "Skip checking against the built-in recommender catalog")
 	_ = cmd.MarkFlagRequired("id")
 
 	return cmd
 }
 
+// validateModelConfiguration checks a recommender class and its parameters
+// against the built-in catalog. Parameters the catalog does not know are
+// reported to warn and sent anyway.
+func validateModelConfiguration(class string, params map[string]any, warn io.Writer) error {
+	c, err := recommender.Find(class)
+	if err != nil {
+		return err
+	}
+	warnings, err := c.Validate(params)
+	for _, w := range warnings {
+		fmt.Fprintf(warn, "warning: %s\n", w)
+	}
+	return err
+}
+
+func validateModelConfigurationJSON(class, parametersJSON string, warn io.Writer) error {
+	params, err := decodeParameters(parametersJSON)
+	if err != nil {
+		return fmt.Errorf("--parameters-json: %w", err)
+	}
+	return validateModelConfiguration(class, params, warn)
+}
+
 func printModelConfiguration(format string, x openapi.ModelConfiguration) {
 	if format == "json" || format == "yaml" {
 		m := map[string]any{
@@ -0,0 +1,382 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+// modelConfigurationDoc is the editable part of a model configuration, with
+// the parameters as structured values instead of a JSON string.
+type modelConfigurationDoc struct {
+	Name                 *string        `yaml:"name"`
+	RecommenderClassName string         `yaml:"recommender_class_name"`
+	Parameters           map[string]any `yaml:"parameters"`
+}
+
+func newModelConfigurationEditCmd() *cobra.Command {
+	var sets []string
+	var setFile string
+	var noValidate bool
+
+	cmd := &cobra.Command{
+		Use:   "edit <id>",
+		Short: "Edit a model configuration in $EDITOR or with --set",
+		Long: "Open a model configuration as YAML in $VISUAL or $EDITOR, with its parameters\n" +
+			"as a nested mapping rather than a JSON string. The file is checked when the\n" +
+			"editor exits and reopened with the problem noted at the top until it is valid\n" +
+			"or emptied; an unchanged or empty file cancels the edit.\n\n" +
+			"With --set or --set-file no editor is opened: the values are merged into the\n" +
+			"existing parameters. --set takes key=value, where a dotted key sets a nested\n" +
+			"parameter and the value is read as JSON when it parses (64, 0.1, true, null,\n" +
+			"[1, 2]) and as a string otherwise. --set-file takes a JSON or YAML mapping and\n" +
+			"is applied before --set.",
+		Example: "  recotem model-configuration edit 3\n" +
+			"  recotem model-configuration edit 3 --set n_components=128 --set reg=0.01\n" +
+			"  recotem model-configuration edit 3 --set-file params.json",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			mc, err := getModelConfiguration(client, id)
+			if err != nil {
+				return err
+			}
+			doc, err := modelConfigurationToDoc(mc)
+			if err != nil {
+				return err
+			}
+
+			validate := func(d *modelConfigurationDoc) error {
+				if noValidate {
+					return nil
+				}
+				return validateModelConfiguration(d.RecommenderClassName, d.Parameters, cmd.ErrOrStderr())
+			}
+			var edited *modelConfigurationDoc
+			if len(sets) > 0 || setFile != "" {
+				if edited, err = applyParameterSets(doc, setFile, sets); err == nil && edited != nil {
+					err = validate(edited)
+				}
+			} else {
+				edited, err = editModelConfigurationDoc(mc, doc, runEditor, validate)
+			}
+			if err != nil {
+				return err
+			}
+			if edited == nil {
+				fmt.Fprintln(cmd.ErrOrStderr(), "Edit cancelled, no changes made.")
+				return nil
+			}
+
+			params, err := json.Marshal(edited.Parameters)
+			if err != nil {
+				return err
+			}
+			updated, err := client.UpdateModelConfiguration(id, edited.Name,
+				&edited.RecommenderClassName, utils.NilOrString(string(params)))
+			if err != nil {
+				return err
+			}
+			printModelConfiguration(getOutputFormat(), *updated)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&sets, "set", nil, "Set a parameter as key=value (repeatable)")
+	cmd.Flags().StringVar(&setFile, "set-file", "", "Merge parameters from a JSON or YAML file")
+	cmd.Flags().BoolVar(&noValidate, "no-validate", false, "Skip checking against the built-in recommender catalog")
+
+	return cmd
+}
+
+func getModelConfiguration(client api.Client, id int) (*openapi.ModelConfiguration, error) {
+	list, err := client.GetModelConfigurations(&id, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, x := range results(list.Results) {
+		if utils.Deref(x.Id) == id {
+			return &x, nil
+		}
+	}
+	return nil, fmt.Errorf("model configuration %d not found", id)
+}
+
+func modelConfigurationToDoc(mc *openapi.ModelConfiguration) (*modelConfigurationDoc, error) {
+	params, err := decodeParameters(mc.ParametersJson)
+	if err != nil {
+		return nil, fmt.Errorf("model configuration %d: parameters_json: %w", utils.Deref(mc.Id), err)
+	}
+	return &modelConfigurationDoc{
+		Name:                 mc.Name,
+		RecommenderClassName: mc.RecommenderClassName,
+		Parameters:           params,
+	}, nil
+}
+
+// decodeParameters reads parameters_json, keeping integers as integers.
+func decodeParameters(s string) (map[string]any, error) {
+	params := map[string]any{}
+	if strings.TrimSpace(s) == "" {
+		return params, nil
+	}
+	v, err := decodeJSONValue(s)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+	return m, nil
+}
+
+// decodeJSONValue decodes a single JSON value with numbers as int64 where
+// they are integral and float64 otherwise.
+func decodeJSONValue(s string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return normalizeNumbers(v), nil
+}
+
+func normalizeNumbers(v any) any {
+	switch x := v.(type) {
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return i
+		}
+		f, _ := x.Float64()
+		return f
+	case map[string]any:
+		for k, e := range x {
+			x[k] = normalizeNumbers(e)
+		}
+	case []any:
+		for i, e := range x {
+			x[i] = normalizeNumbers(e)
+		}
+	}
+	return v
+}
+
+// parseSetValue infers the type of a --set value: JSON if it parses, a
+// string otherwise.
+func parseSetValue(s string) any {
+	if v, err := decodeJSONValue(s); err == nil {
+		return v
+	}
+	return s
+}
+
+// applyParameterSets merges --set-file and then each --set into a copy of the
+// parameters. It returns nil when nothing changes.
+func applyParameterSets(doc *modelConfigurationDoc, setFile string, sets []string) (*modelConfigurationDoc, error) {
+	before, err := json.Marshal(doc.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	params, err := decodeParameters(string(before))
+	if err != nil {
+		return nil, err
+	}
+
+	if setFile != "" {
+		data, err := os.ReadFile(setFile)
+		if err != nil {
+			return nil, err
+		}
+		var m map[string]any
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("--set-file %s: %w", setFile, err)
+		}
+		if m == nil && strings.TrimSpace(string(data)) != "" {
+			return nil, fmt.Errorf("--set-file %s: expected a mapping of parameters", setFile)
+		}
+		mergeParameters(params, m)
+	}
+	for _, s := range sets {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("--set: expected key=value, got %q", s)
+		}
+		if err := setParameter(params, strings.TrimSpace(key), parseSetValue(value)); err != nil {
+			return nil, fmt.Errorf("--set %s: %w", s, err)
+		}
+	}
+
+	after, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(before, after) {
+		return nil, nil
+	}
+	edited := *doc
+	edited.Parameters = params
+	return &edited, nil
+}
+
+// mergeParameters merges src into dst, recursing into mappings present in
+// both.
+func mergeParameters(dst, src map[string]any) {
+	for k, v := range src {
+		if sm, ok := v.(map[string]any); ok {
+			if dm, ok := dst[k].(map[string]any); ok {
+				mergeParameters(dm, sm)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// setParameter sets a dotted key, creating the mappings on its path.
+func setParameter(params map[string]any, key string, value any) error {
+	parts := strings.Split(key, ".")
+	m := params
+	for i, part := range parts[:len(parts)-1] {
+		switch next := m[part].(type) {
+		case map[string]any:
+			m = next
+		case nil:
+			child := map[string]any{}
+			m[part] = child
+			m = child
+		default:
+			return fmt.Errorf("%s is not a mapping", strings.Join(parts[:i+1], "."))
+		}
+	}
+	m[parts[len(parts)-1]] = value
+	return nil
+}
+
+// runEditor opens path in $VISUAL or $EDITOR (vi if neither is set). The
+// variable may include arguments, as in "code --wait".
+var runEditor = func(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := strings.Fields(editor)
+	c := exec.Command(args[0], append(args[1:], path)...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("editor %q: %w", editor, err)
+	}
+	return nil
+}
+
+// editModelConfigurationDoc lets the user edit the document until it reads
+// and passes validate. It returns nil if the file is left unchanged or
+// emptied.
+func editModelConfigurationDoc(mc *openapi.ModelConfiguration, doc *modelConfigurationDoc,
+	edit func(path string) error, validate func(*modelConfigurationDoc) error) (*modelConfigurationDoc, error) {
+	body, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	header := fmt.Sprintf("# Model configuration %d (project %d", utils.Deref(mc.Id), mc.Project)
+	if mc.TuningJob != nil {
+		header += fmt.Sprintf(", from tuning job %d", *mc.TuningJob)
+	}
+	header += ").\n# Lines starting with # are ignored. Save an empty file to cancel.\n"
+
+	f, err := os.CreateTemp("", "recotem-model-configuration-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	path := f.Name()
+	_ = f.Close()
+	defer os.Remove(path)
+
+	content := body
+	var problem error
+	for {
+		text := header
+		if problem != nil {
+			text = "# Error: " + strings.ReplaceAll(problem.Error(), "\n", "\n#   ") + "\n" + text
+		}
+		if err := os.WriteFile(path, append([]byte(text), content...), 0o600); err != nil {
+			return nil, err
+		}
+		if err := edit(path); err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		content = stripComments(data)
+		if len(bytes.TrimSpace(content)) == 0 || bytes.Equal(content, body) {
+			return nil, nil
+		}
+		edited, err := readModelConfigurationDoc(content)
+		if err == nil {
+			err = validate(edited)
+		}
+		if err == nil {
+			return edited, nil
+		}
+		problem = err
+	}
+}
+
+// stripComments drops whole-line comments, such as the header and any error
+// note, keeping the rest of the file as written.
+func stripComments(data []byte) []byte {
+	var out []byte
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if !bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+			out = append(out, line...)
+		}
+	}
+	return out
+}
+
+func readModelConfigurationDoc(data []byte) (*modelConfigurationDoc, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	doc := &modelConfigurationDoc{}
+	if err := dec.Decode(doc); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(doc.RecommenderClassName) == "" {
+		return nil, fmt.Errorf("recommender_class_name: required")
+	}
+	if doc.Parameters == nil {
+		doc.Parameters = map[string]any{}
+	}
+	if _, err := json.Marshal(doc.Parameters); err != nil {
+		return nil, fmt.Errorf("parameters: %w", err)
+	}
+	return doc, nil
+}
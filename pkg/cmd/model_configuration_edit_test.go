@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"recotem.org/cli/recotem/pkg/openapi"
+)
+
+func TestParseSetValue(t *testing.T) {
+	cases := map[string]any{
+		"64":     int64(64),
+		"0.01":   0.01,
+		"true":   true,
+		"null":   nil,
+		"ials":   "ials",
+		`"64"`:   "64",
+		"[1, 2]": []any{int64(1), int64(2)},
+		"1 2":    "1 2",
+	}
+	for in, want := range cases {
+		got, _ := json.Marshal(parseSetValue(in))
+		wantJSON, _ := json.Marshal(want)
+		if string(got) != string(wantJSON) {
+			t.Errorf("%q: expected %s, got %s", in, wantJSON, got)
+		}
+	}
+	if _, ok := parseSetValue("64").(int64); !ok {
+		t.Error("expected an integer to stay an integer")
+	}
+}
+
+func TestApplyParameterSets(t *testing.T) {
+	doc := &modelConfigurationDoc{
+		RecommenderClassName: "IALSRecommender",
+		Parameters:           map[string]any{"n_components": int64(64), "opt": map[string]any{"lr": 0.1}},
+	}
+	file := filepath.Join(t.TempDir(), "params.json")
+	if err := os.WriteFile(file, []byte(`{"opt": {"beta": 0.9}, "alpha": 1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	edited, err := applyParameterSets(doc, file, []string{"n_components=128", "opt.lr=0.05", "loss=bpr"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := json.Marshal(edited.Parameters)
+	want := `{"alpha":1,"loss":"bpr","n_components":128,"opt":{"beta":0.9,"lr":0.05}}`
+	if string(got) != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	if doc.Parameters["n_components"] != int64(64) {
+		t.Error("expected the original parameters to be left alone")
+	}
+
+	if edited, err := applyParameterSets(doc, "", []string{"n_components=64"}); err != nil || edited != nil {
+		t.Errorf("expected no change, got %v, %v", edited, err)
+	}
+	for _, bad := range []string{"n_components", "=1", "n_components.x=1"} {
+		if _, err := applyParameterSets(doc, "", []string{bad}); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestEditModelConfigurationDoc(t *testing.T) {
+	id, tuningJob := 3, 4
+	mc := &openapi.ModelConfiguration{Id: &id, Project: 1, TuningJob: &tuningJob,
+		RecommenderClassName: "IALSRecommender", ParametersJson: `{"n_components": 64}`}
+	doc, err := modelConfigurationToDoc(mc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noValidation := func(*modelConfigurationDoc) error { return nil }
+
+	// The first save is invalid; the editor is reopened with the error.
+	var seen []string
+	edits := []string{
+		"recommender_class_name: IALSRecommender\nparameters: {n_components: 128}\nmodel: x\n",
+		"# keep\nrecommender_class_name: IALSRecommender\nparameters:\n  n_components: 128\n",
+	}
+	edit := func(path string) error {
+		data, _ := os.ReadFile(path)
+		seen = append(seen, string(data))
+		return os.WriteFile(path, []byte(edits[len(seen)-1]), 0o600)
+	}
+	edited, err := editModelConfigurationDoc(mc, doc, edit, noValidation)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(seen[0], "from tuning job 4") || !strings.Contains(seen[0], "n_components: 64") {
+		t.Errorf("unexpected first document:\n%s", seen[0])
+	}
+	if !strings.HasPrefix(seen[1], "# Error:") || !strings.Contains(seen[1], "model") {
+		t.Errorf("expected the error at the top of the reopened file:\n%s", seen[1])
+	}
+	if edited.Parameters["n_components"] != 128 {
+		t.Errorf("unexpected parameters: %v", edited.Parameters)
+	}
+
+	unchanged := func(path string) error { return nil }
+	if edited, err := editModelConfigurationDoc(mc, doc, unchanged, noValidation); err != nil || edited != nil {
+		t.Errorf("expected an unchanged file to cancel, got %v, %v", edited, err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/tuning"
 	"recotem.org/cli/recotem/pkg/utils"
 )
 
@@ -19,6 +20,11 @@ func newParameterTuningJobCmd() *cobra.Command {
 	cmd.AddCommand(
 		newParameterTuningJobListCmd(),
 		newParameterTuningJobCreateCmd(),
+		newParameterTuningJobGetCmd(),
+		newParameterTuningJobWatchCmd(),
+		newParameterTuningJobWaitCmd(),
+		newParameterTuningJobLeaderboardCmd(),
+		newParameterTuningJobRerunCmd(),
 		newParameterTuningJobDeleteCmd(),
 	)
 
@@ -62,44 +68,52 @@ func newParameterTuningJobListCmd() *cobra.Command {
 }
 
 func newParameterTuningJobCreateCmd() *cobra.Command {
-	var data, split, evaluation string
-	var nTasksParallel, nTrials, memoryBudget, timeoutOverall, timeoutSinglestep, randomSeed string
-	var triedAlgorithmJSON, irspackVersion, trainAfterTuning, bestScore, tunedModel, bestConfig string
+	var file string
 
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a parameter tuning job",
+		Long: "Create a parameter tuning job from flags, a YAML job spec (-f) or both; flags\n" +
+			"override the file. Data, split and evaluation may be given by ID or by name.\n" +
+			"--preset (quick, balanced or thorough) supplies trial and time budgets that\n" +
+			"the file and flags can override. Every value is validated before the job is\n" +
+			"created.\n\n" +
+			"Example job.yaml:\n\n" +
+			"  preset: balanced\n" +
+			"  data: 12\n" +
+			"  split: default-split\n" +
+			"  evaluation: ndcg-at-10\n" +
+			"  n_trials: 60\n" +
+			"  algorithms: [IALSRecommender, RP3betaRecommender]",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := newClientFromCmd(cmd)
+			spec := tuning.Spec{}
+			if file != "" {
+				fromFile, err := tuning.LoadSpec(file)
+				if err != nil {
+					return err
+				}
+				spec = *fromFile
+			}
+			fromFlags, extra, err := tuningSpecFromFlags(cmd)
 			if err != nil {
 				return err
 			}
-			dataID, err := strconv.Atoi(data)
+			spec, err = spec.Override(fromFlags).Resolve()
 			if err != nil {
 				return err
 			}
-			splitID, err := strconv.Atoi(split)
-			if err != nil {
+			if err := spec.Validate(); err != nil {
 				return err
 			}
-			evalID, err := strconv.Atoi(evaluation)
+			if len(spec.Algorithms) > 0 && extra.triedAlgorithmsJSON != nil {
+				return fmt.Errorf("--tried-algorithm-json cannot be combined with algorithms")
+			}
+
+			client, err := newClientFromCmd(cmd)
 			if err != nil {
 				return err
 			}
-			ptj, err := client.CreateParameterTuningJob(
-				dataID, splitID, evalID,
-				utils.NilOrInt(nTasksParallel),
-				utils.NilOrInt(nTrials),
-				utils.NilOrInt(memoryBudget),
-				utils.NilOrInt(timeoutOverall),
-				utils.NilOrInt(timeoutSinglestep),
-				utils.NilOrInt(randomSeed),
-				utils.NilOrString(triedAlgorithmJSON),
-				utils.NilOrString(irspackVersion),
-				utils.NilOrBool(trainAfterTuning),
-				utils.NilOrFloat32(bestScore),
-				utils.NilOrInt(tunedModel),
-				utils.NilOrInt(bestConfig))
+			ptj, err := createParameterTuningJob(client, spec, extra)
 			if err != nil {
 				return err
 			}
@@ -108,24 +122,24 @@ func newParameterTuningJobCreateCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&data, "data", "d", "", "Data ID")
-	cmd.Flags().StringVarP(&split, "split", "s", "", "Split ID")
-	cmd.Flags().StringVarP(&evaluation, "evaluation", "e", "", "Evaluation ID")
-	cmd.Flags().StringVar(&nTasksParallel, "n-tasks-parallel", "", "N tasks parallel")
-	cmd.Flags().StringVar(&nTrials, "n-trials", "", "N trials")
-	cmd.Flags().StringVar(&memoryBudget, "memory-budget", "", "Memory budget")
-	cmd.Flags().StringVar(&timeoutOverall, "timeout-overall", "", "Timeout overall")
-	cmd.Flags().StringVar(&timeoutSinglestep, "timeout-singlestep", "", "Timeout singlestep")
-	cmd.Flags().StringVar(&randomSeed, "random-seed", "", "Random seed")
-	cmd.Flags().StringVar(&triedAlgorithmJSON, "tried-algorithm-json", "", "Tried algorithm JSON")
-	cmd.Flags().StringVar(&irspackVersion, "irspack-version", "", "irspack version")
-	cmd.Flags().StringVar(&trainAfterTuning, "train-after-tuning", "", "Train after tuning")
-	cmd.Flags().StringVar(&bestScore, "best-score", "", "Best score")
-	cmd.Flags().StringVar(&tunedModel, "tuned-model", "", "Tuned model ID")
-	cmd.Flags().StringVar(&bestConfig, "best-config", "", "Best config ID")
-	_ = cmd.MarkFlagRequired("data")
-	_ = cmd.MarkFlagRequired("split")
-	_ = cmd.MarkFlagRequired("evaluation")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Job spec file (YAML)")
+	cmd.Flags().String("preset", "", "Budget preset (quick, balanced, thorough)")
+	cmd.Flags().StringP("data", "d", "", "Data ID or name")
+	cmd.Flags().StringP("split", "s", "", "Split ID or name")
+	cmd.Flags().StringP("evaluation", "e", "", "Evaluation ID or name")
+	cmd.Flags().String("n-tasks-parallel", "", "N tasks parallel")
+	cmd.Flags().String("n-trials", "", "N trials")
+	cmd.Flags().String("memory-budget", "", "Memory budget")
+	cmd.Flags().String("timeout-overall", "", "Timeout overall (seconds)")
+	cmd.Flags().String("timeout-singlestep", "", "Timeout singlestep (seconds)")
+	cmd.Flags().String("random-seed", "", "Random seed")
+	cmd.Flags().StringSlice("algorithms", nil, "Algorithms to try (comma-separated recommender class names)")
+	cmd.Flags().String("tried-algorithm-json", "", "Tried algorithm JSON")
+	cmd.Flags().String("irspack-version", "", "irspack version")
+	cmd.Flags().String("train-after-tuning", "", "Train after tuning")
+	cmd.Flags().String("best-score", "", "Best score")
+	cmd.Flags().String("tuned-model", "", "Tuned model ID")
+	cmd.Flags().String("best-config", "", "Best config ID")
 
 	return cmd
 }
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/tuning"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+func newParameterTuningJobLeaderboardCmd() *cobra.Command {
+	var project, export, name string
+
+	cmd := &cobra.Command{
+		Use:   "leaderboard [id]",
+		Short: "Rank the algorithms tried by a tuning job",
+		Long: "Parse the tried algorithms and trial results of a parameter tuning job, or of\n" +
+			"every job of a project with --project, and rank the algorithms by their best\n" +
+			"score with the winning parameters and the number of trials.\n\n" +
+			"--export writes the winning parameters as a model-configuration create\n" +
+			"payload (JSON) and prints the equivalent command. With --export - the payload\n" +
+			"is written to stdout and the leaderboard table to stderr.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (len(args) == 1) == (project != "") {
+				return fmt.Errorf("specify either a tuning job ID or --project")
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+
+			var jobs []openapi.ParameterTuningJob
+			var projectID int
+			if project != "" {
+				if projectID, err = strconv.Atoi(project); err != nil {
+					return err
+				}
+				if jobs, err = listParameterTuningJobs(client, projectID); err != nil {
+					return err
+				}
+			} else {
+				id, err := strconv.Atoi(args[0])
+				if err != nil {
+					return err
+				}
+				job, err := client.GetParameterTuningJob(id)
+				if err != nil {
+					return err
+				}
+				jobs = []openapi.ParameterTuningJob{*job}
+			}
+
+			trials, err := jobTrials(jobs)
+			if err != nil {
+				return err
+			}
+			entries := tuning.Rank(trials)
+			if export == "-" {
+				writeLeaderboard(cmd.ErrOrStderr(), entries)
+			} else {
+				printLeaderboard(getOutputFormat(), entries)
+			}
+
+			if export == "" {
+				return nil
+			}
+			if len(entries) == 0 || entries[0].BestScore == nil {
+				return fmt.Errorf("no scored trials to export")
+			}
+			if projectID == 0 {
+				if projectID, err = trainingDataProject(client, jobs[0].Data); err != nil {
+					return err
+				}
+			}
+			payload, err := winningConfiguration(entries[0], projectID, utils.NilOrString(name))
+			if err != nil {
+				return err
+			}
+			if err := writeModelConfigurationPayload(export, payload, cmd.OutOrStdout()); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.ErrOrStderr(), modelConfigurationCreateCommand(payload))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&project, "project", "", "Rank across all tuning jobs of this project")
+	cmd.Flags().StringVar(&export, "export", "", "Write the winning parameters as a model-configuration create payload to this file (- for stdout)")
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Model configuration name for the exported payload")
+
+	return cmd
+}
+
+// jobTrials collects the trials of the jobs that recorded any.
+func jobTrials(jobs []openapi.ParameterTuningJob) ([]tuning.Trial, error) {
+	var trials []tuning.Trial
+	for _, job := range jobs {
+		if job.TriedAlgorithmsJson == nil {
+			continue
+		}
+		t, err := tuning.ParseTrials(utils.Deref(job.Id), *job.TriedAlgorithmsJson)
+		if err != nil {
+			return nil, fmt.Errorf("parameter tuning job %d: %w", utils.Deref(job.Id), err)
+		}
+		trials = append(trials, t...)
+	}
+	return trials, nil
+}
+
+func trainingDataProject(client api.Client, id int) (int, error) {
+	list, err := client.GetTrainingData(&id, nil, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	data := results(list.Results)
+	if len(data) == 0 {
+		return 0, fmt.Errorf("training data %d not found", id)
+	}
+	return data[0].Project, nil
+}
+
+// winningConfiguration turns a leaderboard entry into a model configuration
+// linked to the tuning job that found it.
+func winningConfiguration(e tuning.Entry, project int, name *string) (openapi.ModelConfigurationCreateJSONRequestBody, error) {
+	params, err := json.Marshal(e.Params)
+	if err != nil {
+		return openapi.ModelConfigurationCreateJSONRequestBody{}, err
+	}
+	job := e.Job
+	return openapi.ModelConfigurationCreateJSONRequestBody{
+		Name:                 name,
+		Project:              project,
+		RecommenderClassName: e.Algorithm,
+		ParametersJson:       string(params),
+		TuningJob:            &job,
+	}, nil
+}
+
+// writeModelConfigurationPayload writes the payload to path, or to stdout for
+// "-".
+func writeModelConfigurationPayload(path string, payload openapi.ModelConfigurationCreateJSONRequestBody, stdout io.Writer) error {
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func modelConfigurationCreateCommand(p openapi.ModelConfigurationCreateJSONRequestBody) string {
+	quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'" }
+	var b strings.Builder
+	fmt.Fprintf(&b, "recotem model-configuration create --project %d", p.Project)
+	if p.Name != nil {
+		fmt.Fprintf(&b, " --name %s", quote(*p.Name))
+	}
+	fmt.Fprintf(&b, " --recommender-class-name %s --parameters-json %s", p.RecommenderClassName, quote(p.ParametersJson))
+	return b.String()
+}
+
+func printLeaderboard(format string, entries []tuning.Entry) {
+	if format == "json" || format == "yaml" {
+		utils.PrintOutput(format, entries)
+		return
+	}
+	writeLeaderboard(os.Stdout, entries)
+}
+
+func writeLeaderboard(out io.Writer, entries []tuning.Entry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No trials recorded.")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RANK\tALGORITHM\tBEST SCORE\tTRIALS\tJOB\tPARAMETERS")
+	for _, e := range entries {
+		score := utils.NoValue
+		if e.BestScore != nil {
+			score = strconv.FormatFloat(*e.BestScore, 'f', -1, 64)
+		}
+		params, _ := json.Marshal(e.Params)
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\n", e.Rank, e.Algorithm, score, e.Trials, e.Job, params)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/tuning"
+)
+
+func TestJobTrials(t *testing.T) {
+	id1, id2 := 1, 2
+	tried := `[{"algorithm":"IALSRecommender","score":0.2}]`
+	bad := `"nope"`
+	jobs := []openapi.ParameterTuningJob{{Id: &id1, TriedAlgorithmsJson: &tried}, {Id: &id2}}
+	trials, err := jobTrials(jobs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trials) != 1 || trials[0].Job != 1 {
+		t.Errorf("expected one trial from job 1, got %+v", trials)
+	}
+
+	jobs[1].TriedAlgorithmsJson = &bad
+	if _, err := jobTrials(jobs); err == nil || !strings.Contains(err.Error(), "parameter tuning job 2") {
+		t.Errorf("expected an error naming job 2, got %v", err)
+	}
+}
+
+func TestWinningConfiguration(t *testing.T) {
+	score := 0.3
+	name := "ials-best"
+	e := tuning.Entry{Algorithm: "IALSRecommender", BestScore: &score, Job: 4, Params: map[string]any{"n_components": 128}}
+	payload, err := winningConfiguration(e, 2, &name)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"ials-best","parameters_json":"{\"n_components\":128}","project":2,"recommender_class_name":"IALSRecommender","tuning_job":4}`
+	if string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+
+	cmd := modelConfigurationCreateCommand(payload)
+	wantCmd := `recotem model-configuration create --project 2 --name 'ials-best' --recommender-class-name IALSRecommender --parameters-json '{"n_components":128}'`
+	if cmd != wantCmd {
+		t.Errorf("expected %q, got %q", wantCmd, cmd)
+	}
+}
+
+func TestWriteLeaderboard(t *testing.T) {
+	score := 0.25
+	var out bytes.Buffer
+	writeLeaderboard(&out, []tuning.Entry{
+		{Rank: 1, Algorithm: "IALSRecommender", BestScore: &score, Trials: 3, Job: 4, Params: map[string]any{"alpha": 1}},
+		{Rank: 2, Algorithm: "DenseSLIMRecommender", Trials: 1, Job: 4, Params: map[string]any{}},
+	})
+	want := "RANK  ALGORITHM             BEST SCORE  TRIALS  JOB  PARAMETERS\n" +
+		"1     IALSRecommender       0.25        3       4    {\"alpha\":1}\n" +
+		"2     DenseSLIMRecommender  <NA>        1       4    {}\n"
+	if out.String() != want {
+		t.Errorf("unexpected table:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	writeLeaderboard(&out, nil)
+	if out.String() != "No trials recorded.\n" {
+		t.Errorf("unexpected output for no trials: %q", out.String())
+	}
+}
+
+func TestWriteModelConfigurationPayloadToStdout(t *testing.T) {
+	payload := openapi.ModelConfigurationCreateJSONRequestBody{Project: 2, RecommenderClassName: "IALSRecommender", ParametersJson: "{}"}
+	var out bytes.Buffer
+	if err := writeModelConfigurationPayload("-", payload, &out); err != nil {
+		t.Fatal(err)
+	}
+	var decoded openapi.ModelConfigurationCreateJSONRequestBody
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("stdout should hold only the payload: %v\n%s", err, out.String())
+	}
+	if decoded.RecommenderClassName != "IALSRecommender" {
+		t.Errorf("unexpected payload %+v", decoded)
+	}
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/tuning"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+// tuningComparison compares a rerun with the job it was copied from.
+type tuningComparison struct {
+	OldJob       int      `json:"old_job" yaml:"old_job"`
+	NewJob       int      `json:"new_job" yaml:"new_job"`
+	OldData      int      `json:"old_data" yaml:"old_data"`
+	NewData      int      `json:"new_data" yaml:"new_data"`
+	OldBestScore *float32 `json:"old_best_score" yaml:"old_best_score"`
+	NewBestScore *float32 `json:"new_best_score" yaml:"new_best_score"`
+	Change       *float64 `json:"change" yaml:"change"`
+}
+
+func newParameterTuningJobRerunCmd() *cobra.Command {
+	var wait bool
+	var interval, timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "rerun <id>",
+		Short: "Run a tuning job again, optionally on new data",
+		Long: "Create a parameter tuning job with the split, evaluation, budgets, random seed,\n" +
+			"irspack version and algorithm list of an existing one, with any of them\n" +
+			"overridden by flags. With --wait, block until the new job finishes and compare\n" +
+			"its best score with the original's.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return err
+			}
+			overrides, _, err := tuningSpecFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			old, err := client.GetParameterTuningJob(id)
+			if err != nil {
+				return err
+			}
+			spec, extra, err := specFromJob(old)
+			if err != nil {
+				return err
+			}
+			spec = spec.Override(overrides)
+			if err := spec.Validate(); err != nil {
+				return err
+			}
+			job, err := createParameterTuningJob(client, spec, extra)
+			if err != nil {
+				return err
+			}
+
+			format := getOutputFormat()
+			if !wait {
+				printParameterTuningJob(format, *job)
+				return nil
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Created parameter tuning job %d; waiting for it to finish\n", utils.Deref(job.Id))
+			job, err = pollParameterTuningJob(client, utils.Deref(job.Id), interval, timeout, nil)
+			if err != nil {
+				return err
+			}
+			if err := tuningJobFailure(client, job, cmd.ErrOrStderr()); err != nil {
+				return err
+			}
+			c := compareTuningJobs(old, job)
+			if format == "json" || format == "yaml" {
+				utils.PrintOutput(format, c)
+			} else {
+				writeTuningComparison(os.Stdout, c)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("data", "d", "", "Data ID or name (default: the original job's)")
+	cmd.Flags().StringP("split", "s", "", "Split ID or name")
+	cmd.Flags().StringP("evaluation", "e", "", "Evaluation ID or name")
+	cmd.Flags().String("n-tasks-parallel", "", "N tasks parallel")
+	cmd.Flags().String("n-trials", "", "N trials")
+	cmd.Flags().String("memory-budget", "", "Memory budget")
+	cmd.Flags().String("timeout-overall", "", "Timeout overall (seconds)")
+	cmd.Flags().String("timeout-singlestep", "", "Timeout singlestep (seconds)")
+	cmd.Flags().String("random-seed", "", "Random seed")
+	cmd.Flags().StringSlice("algorithms", nil, "Algorithms to try (comma-separated recommender class names)")
+	cmd.Flags().String("train-after-tuning", "", "Train after tuning")
+	cmd.Flags().BoolVarP(&wait, "wait", "w", false, "Wait for the new job and compare best scores")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Second, "Polling interval with --wait")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Give up waiting after this long (0 waits forever)")
+
+	return cmd
+}
+
+// specFromJob copies the settings of an existing job.
+func specFromJob(job *openapi.ParameterTuningJob) (tuning.Spec, tuningJobExtras, error) {
+	data, split, evaluation := job.Data, job.Split, job.Evaluation
+	spec := tuning.Spec{
+		Data:              tuning.Ref{ID: &data},
+		Split:             tuning.Ref{ID: &split},
+		Evaluation:        tuning.Ref{ID: &evaluation},
+		NTrials:           job.NTrials,
+		NTasksParallel:    job.NTasksParallel,
+		MemoryBudget:      job.MemoryBudget,
+		TimeoutOverall:    job.TimeoutOverall,
+		TimeoutSinglestep: job.TimeoutSinglestep,
+		RandomSeed:        job.RandomSeed,
+		TrainAfterTuning:  job.TrainAfterTuning,
+	}
+	if job.TriedAlgorithmsJson != nil {
+		algorithms, err := tuning.Algorithms(*job.TriedAlgorithmsJson)
+		if err != nil {
+			return spec, tuningJobExtras{}, fmt.Errorf("parameter tuning job %d: %w", utils.Deref(job.Id), err)
+		}
+		spec.Algorithms = algorithms
+	}
+	return spec, tuningJobExtras{irspackVersion: job.IrspackVersion}, nil
+}
+
+func compareTuningJobs(old, rerun *openapi.ParameterTuningJob) tuningComparison {
+	c := tuningComparison{
+		OldJob:       utils.Deref(old.Id),
+		NewJob:       utils.Deref(rerun.Id),
+		OldData:      old.Data,
+		NewData:      rerun.Data,
+		OldBestScore: old.BestScore,
+		NewBestScore: rerun.BestScore,
+	}
+	if old.BestScore != nil && rerun.BestScore != nil {
+		change := float64(*rerun.BestScore) - float64(*old.BestScore)
+		c.Change = &change
+	}
+	return c
+}
+
+func writeTuningComparison(out io.Writer, c tuningComparison) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tORIGINAL\tRERUN")
+	fmt.Fprintf(w, "Job\t%d\t%d\n", c.OldJob, c.NewJob)
+	fmt.Fprintf(w, "Data\t%d\t%d\n", c.OldData, c.NewData)
+	fmt.Fprintf(w, "Best score\t%s\t%s\n", formatScore(c.OldBestScore), formatScore(c.NewBestScore))
+	_ = w.Flush()
+	if c.Change != nil {
+		fmt.Fprintf(out, "Change: %+.4f\n", *c.Change)
+	}
+}
+
+// formatScore prints a score with the precision it was stored with.
+func formatScore(v *float32) string {
+	if v == nil {
+		return utils.NoValue
+	}
+	return strconv.FormatFloat(float64(*v), 'f', -1, 32)
+}
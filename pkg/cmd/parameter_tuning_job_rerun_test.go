@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"recotem.org/cli/recotem/pkg/openapi"
+)
+
+func TestSpecFromJob(t *testing.T) {
+	id, nTrials, seed := 4, 40, 7
+	version := "0.4.0"
+	tried := `[{"algorithm":"IALSRecommender","score":0.2},{"algorithm":"TopPopRecommender","score":0.1}]`
+	job := &openapi.ParameterTuningJob{
+		Id: &id, Data: 12, Split: 2, Evaluation: 3, NTrials: &nTrials, RandomSeed: &seed,
+		IrspackVersion: &version, TriedAlgorithmsJson: &tried,
+	}
+	spec, extra, err := specFromJob(job)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *spec.Data.ID != 12 || *spec.Split.ID != 2 || *spec.Evaluation.ID != 3 {
+		t.Errorf("unexpected references: %+v", spec)
+	}
+	if *spec.NTrials != 40 || *spec.RandomSeed != 7 || *extra.irspackVersion != "0.4.0" {
+		t.Errorf("unexpected settings: %+v %+v", spec, extra)
+	}
+	if !reflect.DeepEqual(spec.Algorithms, []string{"IALSRecommender", "TopPopRecommender"}) {
+		t.Errorf("unexpected algorithms: %v", spec.Algorithms)
+	}
+}
+
+func TestRerunOverrides(t *testing.T) {
+	id, nTrials := 4, 40
+	job := &openapi.ParameterTuningJob{Id: &id, Data: 12, Split: 2, Evaluation: 3, NTrials: &nTrials}
+	spec, _, err := specFromJob(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newParameterTuningJobRerunCmd()
+	if err := cmd.ParseFlags([]string{"--data", "15", "--n-trials", "80"}); err != nil {
+		t.Fatal(err)
+	}
+	overrides, _, err := tuningSpecFromFlags(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec = spec.Override(overrides)
+	if *spec.Data.ID != 15 || *spec.NTrials != 80 || *spec.Split.ID != 2 {
+		t.Errorf("unexpected spec: %+v", spec)
+	}
+}
+
+func TestWriteTuningComparison(t *testing.T) {
+	id1, id2 := 4, 9
+	old, rerun := float32(0.2), float32(0.25)
+	c := compareTuningJobs(
+		&openapi.ParameterTuningJob{Id: &id1, Data: 12, BestScore: &old},
+		&openapi.ParameterTuningJob{Id: &id2, Data: 15, BestScore: &rerun},
+	)
+	var out bytes.Buffer
+	writeTuningComparison(&out, c)
+	want := "            ORIGINAL  RERUN\n" +
+		"Job         4         9\n" +
+		"Data        12        15\n" +
+		"Best score  0.2       0.25\n" +
+		"Change: +0.0500\n"
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%q\nwant:\n%q", out.String(), want)
+	}
+
+	c = compareTuningJobs(&openapi.ParameterTuningJob{Id: &id1}, &openapi.ParameterTuningJob{Id: &id2, BestScore: &rerun})
+	if c.Change != nil {
+		t.Errorf("expected no change without an original score, got %v", *c.Change)
+	}
+}
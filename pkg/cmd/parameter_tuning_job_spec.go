@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/tuning"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+// tuningJobExtras are the create fields that are not part of a job spec.
+type tuningJobExtras struct {
+	triedAlgorithmsJSON *string
+	irspackVersion      *string
+	bestScore           *float32
+	tunedModel          *int
+	bestConfig          *int
+}
+
+// tuningSpecFromFlags reads the flags that were given on the command line.
+// Every malformed value is reported, not just the first.
+func tuningSpecFromFlags(cmd *cobra.Command) (tuning.Spec, tuningJobExtras, error) {
+	var errs []error
+	flags := cmd.Flags()
+	str := func(name string) *string {
+		if !flags.Changed(name) {
+			return nil
+		}
+		v, _ := flags.GetString(name)
+		return &v
+	}
+	integer := func(name string) *int {
+		s := str(name)
+		if s == nil {
+			return nil
+		}
+		v, err := strconv.Atoi(strings.TrimSpace(*s))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("--%s: %q is not an integer", name, *s))
+			return nil
+		}
+		return &v
+	}
+	boolean := func(name string) *bool {
+		s := str(name)
+		if s == nil {
+			return nil
+		}
+		v, err := strconv.ParseBool(strings.TrimSpace(*s))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("--%s: %q is not true or false", name, *s))
+			return nil
+		}
+		return &v
+	}
+	ref := func(name string) tuning.Ref {
+		s := str(name)
+		if s == nil {
+			return tuning.Ref{}
+		}
+		if strings.TrimSpace(*s) == "" {
+			errs = append(errs, fmt.Errorf("--%s: must not be empty", name))
+			return tuning.Ref{}
+		}
+		return tuning.ParseRef(strings.TrimSpace(*s))
+	}
+
+	spec := tuning.Spec{
+		Preset:            utils.Deref(str("preset")),
+		Data:              ref("data"),
+		Split:             ref("split"),
+		Evaluation:        ref("evaluation"),
+		NTrials:           integer("n-trials"),
+		NTasksParallel:    integer("n-tasks-parallel"),
+		MemoryBudget:      integer("memory-budget"),
+		TimeoutOverall:    integer("timeout-overall"),
+		TimeoutSinglestep: integer("timeout-singlestep"),
+		RandomSeed:        integer("random-seed"),
+		TrainAfterTuning:  boolean("train-after-tuning"),
+	}
+	if flags.Changed("algorithms") {
+		spec.Algorithms, _ = flags.GetStringSlice("algorithms")
+	}
+
+	extra := tuningJobExtras{
+		triedAlgorithmsJSON: str("tried-algorithm-json"),
+		irspackVersion:      str("irspack-version"),
+		tunedModel:          integer("tuned-model"),
+		bestConfig:          integer("best-config"),
+	}
+	if s := str("best-score"); s != nil {
+		v, err := strconv.ParseFloat(strings.TrimSpace(*s), 32)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("--best-score: %q is not a number", *s))
+		} else {
+			f := float32(v)
+			extra.bestScore = &f
+		}
+	}
+	if extra.triedAlgorithmsJSON != nil && !json.Valid([]byte(*extra.triedAlgorithmsJSON)) {
+		errs = append(errs, fmt.Errorf("--tried-algorithm-json: not valid JSON"))
+	}
+	return spec, extra, errors.Join(errs...)
+}
+
+// createParameterTuningJob resolves the references of a validated spec and
+// creates the job.
+func createParameterTuningJob(client api.Client, spec tuning.Spec, extra tuningJobExtras) (*openapi.ParameterTuningJob, error) {
+	data, err := resolveTrainingData(client, spec.Data)
+	if err != nil {
+		return nil, err
+	}
+	split, err := resolveSplitConfig(client, spec.Split)
+	if err != nil {
+		return nil, err
+	}
+	evaluation, err := resolveEvaluationConfig(client, spec.Evaluation)
+	if err != nil {
+		return nil, err
+	}
+	tried := extra.triedAlgorithmsJSON
+	if len(spec.Algorithms) > 0 {
+		b, err := json.Marshal(spec.Algorithms)
+		if err != nil {
+			return nil, err
+		}
+		tried = utils.NilOrString(string(b))
+	}
+	return client.CreateParameterTuningJob(
+		data, split, evaluation,
+		spec.NTasksParallel,
+		spec.NTrials,
+		spec.MemoryBudget,
+		spec.TimeoutOverall,
+		spec.TimeoutSinglestep,
+		spec.RandomSeed,
+		tried,
+		extra.irspackVersion,
+		spec.TrainAfterTuning,
+		extra.bestScore,
+		extra.tunedModel,
+		extra.bestConfig)
+}
+
+// resolveTrainingData looks training data up by file name across projects.
+func resolveTrainingData(client api.Client, r tuning.Ref) (int, error) {
+	if r.ID != nil {
+		return *r.ID, nil
+	}
+	all, err := collectPages(func(page, pageSize *int) ([]openapi.TrainingData, bool, error) {
+		list, err := client.GetTrainingData(nil, page, pageSize, nil)
+		if err != nil {
+			return nil, false, err
+		}
+		return results(list.Results), list.Next != nil, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	var ids []int
+	for _, td := range all {
+		if utils.Deref(td.Basename) == r.Name {
+			ids = append(ids, utils.Deref(td.Id))
+		}
+	}
+	return uniqueMatch("training data", r.Name, ids)
+}
+
+func resolveSplitConfig(client api.Client, r tuning.Ref) (int, error) {
+	if r.ID != nil {
+		return *r.ID, nil
+	}
+	list, err := client.GetSplitConfigs(nil, &r.Name, nil)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int
+	for _, x := range *list {
+		if utils.Deref(x.Name) == r.Name {
+			ids = append(ids, utils.Deref(x.Id))
+		}
+	}
+	return uniqueMatch("split config", r.Name, ids)
+}
+
+func resolveEvaluationConfig(client api.Client, r tuning.Ref) (int, error) {
+	if r.ID != nil {
+		return *r.ID, nil
+	}
+	list, err := client.GetEvaluationConfigs(nil, &r.Name, nil)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int
+	for _, x := range *list {
+		if utils.Deref(x.Name) == r.Name {
+			ids = append(ids, utils.Deref(x.Id))
+		}
+	}
+	return uniqueMatch("evaluation config", r.Name, ids)
+}
+
+func uniqueMatch(kind, name string, ids []int) (int, error) {
+	switch len(ids) {
+	case 0:
+		return 0, fmt.Errorf("%s %q not found", kind, name)
+	case 1:
+		return ids[0], nil
+	}
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.Itoa(id)
+	}
+	return 0, fmt.Errorf("%s %q is ambiguous (IDs %s); use the ID", kind, name, strings.Join(s, ", "))
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTuningSpecFromFlags(t *testing.T) {
+	cmd := newParameterTuningJobCreateCmd()
+	err := cmd.ParseFlags([]string{
+		"--data", "12", "--split", "default", "--n-trials", "40",
+		"--train-after-tuning", "true", "--algorithms", "IALSRecommender,TopPopRecommender",
+		"--best-score", "0.5",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, extra, err := tuningSpecFromFlags(cmd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *spec.Data.ID != 12 || spec.Split.Name != "default" || !spec.Evaluation.IsZero() {
+		t.Errorf("unexpected references: %+v", spec)
+	}
+	if *spec.NTrials != 40 || !*spec.TrainAfterTuning || len(spec.Algorithms) != 2 {
+		t.Errorf("unexpected spec: %+v", spec)
+	}
+	if spec.MemoryBudget != nil {
+		t.Errorf("expected flags that were not given to stay unset")
+	}
+	if extra.bestScore == nil || *extra.bestScore != 0.5 {
+		t.Errorf("unexpected best score: %v", extra.bestScore)
+	}
+}
+
+func TestTuningSpecFromFlagsReportsEveryMalformedValue(t *testing.T) {
+	cmd := newParameterTuningJobCreateCmd()
+	err := cmd.ParseFlags([]string{
+		"--n-trials", "ten", "--train-after-tuning", "yes please", "--data", " ", "--tried-algorithm-json", "[",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = tuningSpecFromFlags(cmd)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{
+		`--n-trials: "ten" is not an integer`,
+		`--train-after-tuning: "yes please" is not true or false`,
+		"--data: must not be empty",
+		"--tried-algorithm-json: not valid JSON",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in:\n%s", want, err)
+		}
+	}
+}
+
+func TestUniqueMatch(t *testing.T) {
+	if id, err := uniqueMatch("split config", "a", []int{4}); err != nil || id != 4 {
+		t.Errorf("expected 4, got %d, %v", id, err)
+	}
+	if _, err := uniqueMatch("split config", "a", nil); err == nil || !strings.Contains(err.Error(), `split config "a" not found`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := uniqueMatch("split config", "a", []int{4, 5}); err == nil || !strings.Contains(err.Error(), "IDs 4, 5") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
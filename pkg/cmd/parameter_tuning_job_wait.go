@@ -0,0 +1,373 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+// tuningProgress is a snapshot of a running parameter tuning job.
+type tuningProgress struct {
+	ID             int      `json:"id" yaml:"id"`
+	Status         string   `json:"status" yaml:"status"`
+	TaskStatus     string   `json:"task_status,omitempty" yaml:"task_status,omitempty"`
+	Trials         int      `json:"trials" yaml:"trials"`
+	NTrials        *int     `json:"n_trials,omitempty" yaml:"n_trials,omitempty"`
+	BestScore      *float32 `json:"best_score,omitempty" yaml:"best_score,omitempty"`
+	ElapsedSeconds int      `json:"elapsed_seconds" yaml:"elapsed_seconds"`
+	TimeoutOverall *int     `json:"timeout_overall,omitempty" yaml:"timeout_overall,omitempty"`
+	Done           bool     `json:"done" yaml:"done"`
+	Failed         bool     `json:"failed" yaml:"failed"`
+}
+
+func newParameterTuningJobGetCmd() *cobra.Command {
+	var id string
+
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Show a parameter tuning job",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			idInt, err := strconv.Atoi(id)
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			job, err := client.GetParameterTuningJob(idInt)
+			if err != nil {
+				return err
+			}
+			printParameterTuningJobDetail(getOutputFormat(), job, time.Now())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&id, "id", "i", "", "Parameter tuning job ID")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func newParameterTuningJobWatchCmd() *cobra.Command {
+	var id string
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Follow the progress of a parameter tuning job",
+		Long: "Poll a parameter tuning job and show its status, the number of trials\n" +
+			"completed, the current best score and the elapsed time against\n" +
+			"timeout_overall until the job finishes. With -o json each snapshot is one line\n" +
+			"of JSON; with -o yaml each is a YAML document.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			idInt, err := strconv.Atoi(id)
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			format := getOutputFormat()
+			live := format == "text" && term.IsTerminal(int(os.Stdout.Fd()))
+			stream := utils.NewStreamPrinter(format)
+			var last string
+			_, err = pollParameterTuningJob(client, idInt, interval, 0, func(job *openapi.ParameterTuningJob) {
+				p := tuningJobProgress(job, time.Now())
+				switch {
+				case format == "json" || format == "yaml":
+					stream.Print(p)
+				case live:
+					fmt.Printf("\r\033[K%s", formatTuningProgress(p))
+					if p.Done {
+						fmt.Println()
+					}
+				default:
+					// Elapsed time changes on every poll; only print when
+					// something else does.
+					key := fmt.Sprint(p.Status, p.TaskStatus, p.Trials, utils.Ftoa(p.BestScore))
+					if key != last || p.Done {
+						fmt.Println(formatTuningProgress(p))
+						last = key
+					}
+				}
+			})
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&id, "id", "i", "", "Parameter tuning job ID")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Second, "Polling interval")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func newParameterTuningJobWaitCmd() *cobra.Command {
+	var id string
+	var interval, timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "wait",
+		Short: "Wait for a parameter tuning job to finish",
+		Long: "Block until a parameter tuning job reaches a terminal state. Exits with a\n" +
+			"non-zero status if the job fails or --timeout passes first; on failure the\n" +
+			"task traceback is printed to stderr.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			idInt, err := strconv.Atoi(id)
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			job, err := pollParameterTuningJob(client, idInt, interval, timeout, nil)
+			if err != nil {
+				return err
+			}
+			printParameterTuningJobDetail(getOutputFormat(), job, time.Now())
+			return tuningJobFailure(client, job, cmd.ErrOrStderr())
+		},
+	}
+
+	cmd.Flags().StringVarP(&id, "id", "i", "", "Parameter tuning job ID")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Second, "Polling interval")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Give up after this long (0 waits forever)")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+// pollParameterTuningJob fetches a job every interval, calling update with
+// each snapshot, until it finishes or the timeout (if non-zero) passes.
+func pollParameterTuningJob(client api.Client, id int, interval, timeout time.Duration,
+	update func(*openapi.ParameterTuningJob)) (*openapi.ParameterTuningJob, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("--interval must be positive")
+	}
+	start := time.Now()
+	for {
+		job, err := client.GetParameterTuningJob(id)
+		if err != nil {
+			return nil, err
+		}
+		if update != nil {
+			update(job)
+		}
+		done, _ := tuningJobState(job)
+		if done {
+			return job, nil
+		}
+		wait, ok := nextPoll(start, interval, timeout)
+		if !ok {
+			return nil, fmt.Errorf("timed out after %s waiting for parameter tuning job %d (status %s)",
+				timeout, id, tuningJobStatus(job))
+		}
+		time.Sleep(wait)
+	}
+}
+
+// nextPoll returns how long to wait before polling again: the interval, cut
+// short so the last poll happens at the deadline. It returns false once the
+// timeout (if non-zero) has passed.
+func nextPoll(start time.Time, interval, timeout time.Duration) (time.Duration, bool) {
+	if timeout <= 0 {
+		return interval, true
+	}
+	remaining := timeout - time.Since(start)
+	if remaining <= 0 {
+		return 0, false
+	}
+	return min(interval, remaining), true
+}
+
+// tuningJobFailure returns nil for a job that finished successfully.
+// Otherwise it writes the traceback of the failed task to stderr and returns
+// an error.
+func tuningJobFailure(client api.Client, job *openapi.ParameterTuningJob, stderr io.Writer) error {
+	if _, failed := tuningJobState(job); !failed {
+		return nil
+	}
+	id := utils.Deref(job.Id)
+	logs, err := client.GetParameterTuningJobTaskLogs(id)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to fetch task logs: %v\n", err)
+	}
+	if tb := tuningJobTraceback(job, logs); tb != "" {
+		fmt.Fprintln(stderr, tb)
+	}
+	return fmt.Errorf("parameter tuning job %d failed", id)
+}
+
+// tuningJobState reports whether a job has finished and whether it failed,
+// from its own status or, when that is not final, its latest task.
+func tuningJobState(job *openapi.ParameterTuningJob) (done, failed bool) {
+	if job.Status != nil {
+		switch *job.Status {
+		case openapi.ParameterTuningJobStatusCompleted:
+			return true, false
+		case openapi.ParameterTuningJobStatusFailed:
+			return true, true
+		}
+	}
+	if task := lastTuningTask(job); task != nil && task.Status != nil {
+		switch *task.Status {
+		case openapi.SUCCESS:
+			return true, false
+		case openapi.FAILURE, openapi.REVOKED:
+			return true, true
+		}
+	}
+	return false, false
+}
+
+func lastTuningTask(job *openapi.ParameterTuningJob) *openapi.TaskResult {
+	if job.TaskLinks == nil || len(*job.TaskLinks) == 0 {
+		return nil
+	}
+	return &(*job.TaskLinks)[len(*job.TaskLinks)-1].Task
+}
+
+func tuningJobStatus(job *openapi.ParameterTuningJob) string {
+	if job.Status != nil {
+		return string(*job.Status)
+	}
+	return utils.NoValue
+}
+
+func tuningJobProgress(job *openapi.ParameterTuningJob, now time.Time) tuningProgress {
+	done, failed := tuningJobState(job)
+	p := tuningProgress{
+		ID:             utils.Deref(job.Id),
+		Status:         tuningJobStatus(job),
+		Trials:         countTrials(job.TriedAlgorithmsJson),
+		NTrials:        job.NTrials,
+		BestScore:      job.BestScore,
+		TimeoutOverall: job.TimeoutOverall,
+		Done:           done,
+		Failed:         failed,
+	}
+	start, end := job.InsDatetime, &now
+	if task := lastTuningTask(job); task != nil {
+		if task.Status != nil {
+			p.TaskStatus = string(*task.Status)
+		}
+		if start == nil {
+			start = task.DateCreated
+		}
+		if done && task.DateDone != nil {
+			end = task.DateDone
+		}
+	}
+	if start != nil && end.After(*start) {
+		p.ElapsedSeconds = int(end.Sub(*start).Seconds())
+	}
+	return p
+}
+
+// countTrials counts the trials recorded in tried_algorithms_json, which is
+// either a list of trial records or a table of columns keyed by row. Before
+// tuning runs it holds the names of the algorithms to try, which are not
+// trials.
+func countTrials(s *string) int {
+	if s == nil || *s == "" {
+		return 0
+	}
+	var records []map[string]any
+	if err := json.Unmarshal([]byte(*s), &records); err == nil {
+		return len(records)
+	}
+	var columns map[string]map[string]any
+	if err := json.Unmarshal([]byte(*s), &columns); err == nil {
+		n := 0
+		for _, rows := range columns {
+			n = max(n, len(rows))
+		}
+		return n
+	}
+	return 0
+}
+
+func formatTuningProgress(p tuningProgress) string {
+	status := p.Status
+	if p.TaskStatus != "" {
+		status += " (task " + p.TaskStatus + ")"
+	}
+	trials := strconv.Itoa(p.Trials)
+	if p.NTrials != nil {
+		trials += "/" + strconv.Itoa(*p.NTrials)
+	}
+	elapsed := (time.Duration(p.ElapsedSeconds) * time.Second).String()
+	if p.TimeoutOverall != nil {
+		elapsed += " / " + (time.Duration(*p.TimeoutOverall) * time.Second).String()
+	}
+	return fmt.Sprintf("job %d  %s  trials %s  best score %s  elapsed %s",
+		p.ID, status, trials, utils.Ftoa(p.BestScore), elapsed)
+}
+
+// tuningJobTraceback returns the traceback of a failed job's task, falling
+// back to the last task log that contains one.
+func tuningJobTraceback(job *openapi.ParameterTuningJob, logs []openapi.TaskLog) string {
+	if task := lastTuningTask(job); task != nil && task.Traceback != nil && *task.Traceback != "" {
+		return strings.TrimRight(*task.Traceback, "\n")
+	}
+	for i := len(logs) - 1; i >= 0; i-- {
+		if c := utils.Deref(logs[i].Contents); strings.Contains(c, "Traceback") {
+			return strings.TrimRight(c, "\n")
+		}
+	}
+	if len(logs) > 0 {
+		return strings.TrimRight(utils.Deref(logs[len(logs)-1].Contents), "\n")
+	}
+	return ""
+}
+
+func printParameterTuningJobDetail(format string, job *openapi.ParameterTuningJob, now time.Time) {
+	if format == "json" || format == "yaml" {
+		utils.PrintOutput(format, job)
+		return
+	}
+	writeParameterTuningJobDetail(os.Stdout, job, now)
+}
+
+func writeParameterTuningJobDetail(out io.Writer, job *openapi.ParameterTuningJob, now time.Time) {
+	p := tuningJobProgress(job, now)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	row := func(label, value string) {
+		fmt.Fprintf(w, "%s:\t%s\n", label, value)
+	}
+	row("ID", utils.Itoa(job.Id))
+	row("Status", p.Status)
+	if p.TaskStatus != "" {
+		row("Task status", p.TaskStatus)
+	}
+	row("Data", strconv.Itoa(job.Data))
+	row("Split", strconv.Itoa(job.Split))
+	row("Evaluation", strconv.Itoa(job.Evaluation))
+	trials := strconv.Itoa(p.Trials)
+	if job.NTrials != nil {
+		trials += "/" + strconv.Itoa(*job.NTrials)
+	}
+	row("Trials", trials)
+	row("Best score", utils.Ftoa(job.BestScore))
+	row("Best config", utils.Itoa(job.BestConfig))
+	row("Tuned model", utils.Itoa(job.TunedModel))
+	row("Elapsed", (time.Duration(p.ElapsedSeconds) * time.Second).String())
+	row("Timeout overall", utils.Itoa(job.TimeoutOverall))
+	row("Created", utils.FormatTime(job.InsDatetime))
+	_ = w.Flush()
+}
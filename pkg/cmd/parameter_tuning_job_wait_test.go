@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"recotem.org/cli/recotem/pkg/openapi"
+)
+
+func TestTuningJobState(t *testing.T) {
+	status := func(s openapi.ParameterTuningJobStatus) *openapi.ParameterTuningJobStatus { return &s }
+	task := func(s openapi.StatusEnum) *[]openapi.TaskAndParameterJobLink {
+		return &[]openapi.TaskAndParameterJobLink{{Task: openapi.TaskResult{Status: &s}}}
+	}
+	tests := []struct {
+		name         string
+		job          openapi.ParameterTuningJob
+		done, failed bool
+	}{
+		{"pending", openapi.ParameterTuningJob{Status: status(openapi.ParameterTuningJobStatusPending)}, false, false},
+		{"running task", openapi.ParameterTuningJob{TaskLinks: task(openapi.STARTED)}, false, false},
+		{"completed", openapi.ParameterTuningJob{Status: status(openapi.ParameterTuningJobStatusCompleted)}, true, false},
+		{"failed", openapi.ParameterTuningJob{Status: status(openapi.ParameterTuningJobStatusFailed)}, true, true},
+		{"task succeeded", openapi.ParameterTuningJob{TaskLinks: task(openapi.SUCCESS)}, true, false},
+		{"task failed", openapi.ParameterTuningJob{
+			Status: status(openapi.ParameterTuningJobStatusRunning), TaskLinks: task(openapi.FAILURE)}, true, true},
+		{"task revoked", openapi.ParameterTuningJob{TaskLinks: task(openapi.REVOKED)}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done, failed := tuningJobState(&tt.job)
+			if done != tt.done || failed != tt.failed {
+				t.Errorf("expected done=%v failed=%v, got done=%v failed=%v", tt.done, tt.failed, done, failed)
+			}
+		})
+	}
+}
+
+func TestCountTrials(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want int
+	}{
+		{"empty", "", 0},
+		{"records", `[{"algorithm":"IALS","score":0.1},{"algorithm":"TopPop","score":0.05}]`, 2},
+		{"columns", `{"algorithm":{"0":"IALS","1":"TopPop","2":"RP3beta"},"score":{"0":0.1,"1":0.05,"2":0.2}}`, 3},
+		{"invalid", `not json`, 0},
+		{"algorithms not yet tried", `["IALSRecommender","TopPopRecommender"]`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countTrials(&tt.json); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+	if got := countTrials(nil); got != 0 {
+		t.Errorf("expected 0 for nil, got %d", got)
+	}
+}
+
+func TestTuningJobProgress(t *testing.T) {
+	id, nTrials, timeout := 4, 40, 3600
+	score := float32(0.25)
+	running := openapi.ParameterTuningJobStatusRunning
+	started := openapi.STARTED
+	created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	trials := `[{},{},{}]`
+	job := &openapi.ParameterTuningJob{
+		Id: &id, Status: &running, NTrials: &nTrials, TimeoutOverall: &timeout, BestScore: &score,
+		InsDatetime: &created, TriedAlgorithmsJson: &trials,
+		TaskLinks: &[]openapi.TaskAndParameterJobLink{{Task: openapi.TaskResult{Status: &started}}},
+	}
+
+	p := tuningJobProgress(job, created.Add(200*time.Second))
+	if p.Trials != 3 || p.ElapsedSeconds != 200 || p.Done {
+		t.Errorf("unexpected progress: %+v", p)
+	}
+	want := "job 4  running (task STARTED)  trials 3/40  best score 0.25  elapsed 3m20s / 1h0m0s"
+	if got := formatTuningProgress(p); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestTuningJobProgressStopsAtDateDone(t *testing.T) {
+	created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	finished := created.Add(90 * time.Second)
+	success := openapi.SUCCESS
+	job := &openapi.ParameterTuningJob{
+		InsDatetime: &created,
+		TaskLinks:   &[]openapi.TaskAndParameterJobLink{{Task: openapi.TaskResult{Status: &success, DateDone: &finished}}},
+	}
+	if p := tuningJobProgress(job, created.Add(time.Hour)); p.ElapsedSeconds != 90 || !p.Done {
+		t.Errorf("expected 90s elapsed and done, got %+v", p)
+	}
+}
+
+func TestTuningJobTraceback(t *testing.T) {
+	failure := openapi.FAILURE
+	tb := "Traceback (most recent call last):\nValueError: boom\n"
+	log := func(s string) openapi.TaskLog { return openapi.TaskLog{Contents: &s} }
+
+	withTask := &openapi.ParameterTuningJob{
+		TaskLinks: &[]openapi.TaskAndParameterJobLink{{Task: openapi.TaskResult{Status: &failure, Traceback: &tb}}},
+	}
+	if got := tuningJobTraceback(withTask, nil); got != strings.TrimRight(tb, "\n") {
+		t.Errorf("expected the task traceback, got %q", got)
+	}
+
+	logs := []openapi.TaskLog{log("started"), log(tb), log("cleaning up")}
+	if got := tuningJobTraceback(&openapi.ParameterTuningJob{}, logs); !strings.HasSuffix(got, "ValueError: boom") {
+		t.Errorf("expected the traceback from the logs, got %q", got)
+	}
+	if got := tuningJobTraceback(&openapi.ParameterTuningJob{}, []openapi.TaskLog{log("out of memory")}); got != "out of memory" {
+		t.Errorf("expected the last log, got %q", got)
+	}
+	if got := tuningJobTraceback(&openapi.ParameterTuningJob{}, nil); got != "" {
+		t.Errorf("expected no traceback, got %q", got)
+	}
+}
+
+func TestWriteParameterTuningJobDetail(t *testing.T) {
+	id, model := 4, 9
+	completed := openapi.ParameterTuningJobStatusCompleted
+	job := &openapi.ParameterTuningJob{Id: &id, Data: 1, Split: 2, Evaluation: 3, Status: &completed, TunedModel: &model}
+
+	var out bytes.Buffer
+	writeParameterTuningJobDetail(&out, job, time.Now())
+	for _, want := range []string{"ID:               4\n", "Status:           completed\n", "Tuned model:      9\n", "Best score:       <NA>\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestNextPoll(t *testing.T) {
+	start := time.Now()
+	if wait, ok := nextPoll(start, 5*time.Second, 0); !ok || wait != 5*time.Second {
+		t.Errorf("no timeout: got %s, %v", wait, ok)
+	}
+	if wait, ok := nextPoll(start, 5*time.Second, time.Hour); !ok || wait != 5*time.Second {
+		t.Errorf("far deadline: got %s, %v", wait, ok)
+	}
+	if wait, ok := nextPoll(start, 5*time.Second, 3*time.Second); !ok || wait > 3*time.Second {
+		t.Errorf("near deadline: got %s, %v", wait, ok)
+	}
+	if _, ok := nextPoll(start.Add(-time.Minute), 5*time.Second, 3*time.Second); ok {
+		t.Error("expected a timeout after the deadline")
+	}
+}
+
+func TestPollParameterTuningJobTimeoutShorterThanInterval(t *testing.T) {
+	var polls atomic.Int32
+	server, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		polls.Add(1)
+		jsonResponse(w, http.StatusOK, map[string]any{
+			"id": 7, "data": 1, "split": 1, "evaluation": 1, "status": "running",
+		})
+	})
+	defer server.Close()
+
+	start := time.Now()
+	timeout := 200 * time.Millisecond
+	_, err := pollParameterTuningJob(client, 7, 5*time.Second, timeout, nil)
+	elapsed := time.Since(start)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if elapsed < timeout || elapsed > 3*time.Second {
+		t.Errorf("expected to give up at the deadline, took %s", elapsed)
+	}
+	if n := polls.Load(); n != 2 {
+		t.Errorf("expected a poll at the start and at the deadline, got %d", n)
+	}
+}
@@ -0,0 +1,457 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/ledger"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/pipeline"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+func newPipelineCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pipeline",
+		Short: "Run end-to-end training pipelines",
+	}
+
+	cmd.AddCommand(
+		newPipelineRunCmd(),
+	)
+
+	return cmd
+}
+
+func newPipelineRunCmd() *cobra.Command {
+	var file, statePath string
+	var restart bool
+	var interval, timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run a training pipeline from a file",
+		Long: "Take a project from local data files to a served model: upload the training\n" +
+			"data (and item metadata), reuse or create the split and evaluation configs,\n" +
+			"tune with train_after_tuning, wait for the tuned model, request a sample\n" +
+			"recommendation as a smoke test and optionally point a deployment slot at the\n" +
+			"model. Data files are converted, validated and pseudonymized as by the upload\n" +
+			"commands; input_format and pseudonymize in the file take the place of their\n" +
+			"--input-format and --pseudonymize flags.\n\n" +
+			"The ID of every resource is recorded in a state file (pipeline.yaml.state.json\n" +
+			"by default) after each step, so running the same pipeline again continues\n" +
+			"from the last completed step. --restart ignores the state.\n\n" +
+			"Example pipeline.yaml:\n\n" +
+			"  project: shop\n" +
+			"  training_data: ./interactions.csv\n" +
+			"  item_meta_data: ./items.csv\n" +
+			"  pseudonymize: hmac\n" +
+			"  split:\n" +
+			"    name: default\n" +
+			"    scheme: RG\n" +
+			"    heldout_ratio: 0.1\n" +
+			"  evaluation:\n" +
+			"    name: ndcg-at-10\n" +
+			"    cutoff: 10\n" +
+			"    target_metric: ndcg\n" +
+			"  tuning:\n" +
+			"    preset: balanced\n" +
+			"  deployment_slot: main",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := pipeline.Load(file)
+			if err != nil {
+				return err
+			}
+			if statePath == "" {
+				statePath = pipeline.StatePath(file)
+			}
+			var state *pipeline.State
+			if restart {
+				state = pipeline.NewState(statePath, p)
+			} else if state, err = pipeline.LoadState(statePath, p); err != nil {
+				return err
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			run := &pipelineRun{
+				client:   client,
+				pipeline: p,
+				state:    state,
+				out:      cmd.ErrOrStderr(),
+				interval: interval,
+				timeout:  timeout,
+			}
+			if err := run.run(); err != nil {
+				return err
+			}
+			printPipelineState(getOutputFormat(), state)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Pipeline file (YAML)")
+	cmd.Flags().StringVar(&statePath, "state", "", "State file (default: the pipeline file with .state.json appended)")
+	cmd.Flags().BoolVar(&restart, "restart", false, "Ignore the state of earlier runs and start from the first step")
+	cmd.Flags().DurationVar(&interval, "interval", 10*time.Second, "Polling interval while waiting")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Give up waiting for tuning or training after this long (0 waits forever)")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+// pipelineStep is one step of a pipeline run. done reports whether an
+// earlier run completed it; skip whether the pipeline does not use it.
+type pipelineStep struct {
+	name string
+	skip bool
+	done func() bool
+	run  func() (string, error)
+}
+
+type pipelineRun struct {
+	client   api.Client
+	pipeline *pipeline.Pipeline
+	state    *pipeline.State
+	out      io.Writer
+	interval time.Duration
+	timeout  time.Duration
+}
+
+func (r *pipelineRun) run() error {
+	for _, step := range r.steps() {
+		switch {
+		case step.skip:
+			fmt.Fprintf(r.out, "%s: skipped\n", step.name)
+			continue
+		case step.done():
+			fmt.Fprintf(r.out, "%s: done in an earlier run\n", step.name)
+			continue
+		}
+		msg, err := step.run()
+		if err != nil {
+			return fmt.Errorf("%s: %w", step.name, err)
+		}
+		if err := r.state.Save(); err != nil {
+			return fmt.Errorf("%s: completed, but saving the pipeline state failed: %w", step.name, err)
+		}
+		fmt.Fprintf(r.out, "%s: %s\n", step.name, msg)
+	}
+	return nil
+}
+
+func (r *pipelineRun) steps() []pipelineStep {
+	p, s := r.pipeline, r.state
+	return []pipelineStep{
+		{name: "project", done: func() bool { return s.Project != nil }, run: r.resolveProject},
+		{name: "training data", done: func() bool { return s.TrainingData != nil }, run: func() (string, error) {
+			return r.upload(ledger.KindTrainingData, p.Path(p.TrainingData), &s.TrainingData)
+		}},
+		{name: "item meta data", skip: p.ItemMetaData == "", done: func() bool { return s.ItemMetaData != nil }, run: func() (string, error) {
+			return r.upload(ledger.KindItemMetaData, p.Path(p.ItemMetaData), &s.ItemMetaData)
+		}},
+		{name: "split config", done: func() bool { return s.Split != nil }, run: r.splitConfig},
+		{name: "evaluation config", done: func() bool { return s.Evaluation != nil }, run: r.evaluationConfig},
+		{name: "tuning job", done: func() bool { return s.TuningJob != nil }, run: r.createTuningJob},
+		{name: "trained model", done: func() bool { return s.TrainedModel != nil }, run: r.waitForModel},
+		{name: "smoke test", skip: !p.SmokeTestEnabled(), done: func() bool { return s.SmokeTested }, run: r.smokeTest},
+		{name: "deployment slot", skip: p.DeploymentSlot.IsZero(), done: func() bool { return s.DeploymentSlot != nil }, run: r.deploy},
+	}
+}
+
+func (r *pipelineRun) resolveProject() (string, error) {
+	ref := r.pipeline.Project
+	var project *openapi.Project
+	if ref.ID != nil {
+		x, err := getProject(r.client, *ref.ID)
+		if err != nil {
+			return "", err
+		}
+		project = x
+	} else {
+		projects, err := r.client.GetProjects(nil, &ref.Name)
+		if err != nil {
+			return "", err
+		}
+		for i := range *projects {
+			if (*projects)[i].Name == ref.Name {
+				project = &(*projects)[i]
+			}
+		}
+		if project == nil {
+			return "", fmt.Errorf("project %q not found", ref.Name)
+		}
+	}
+	r.state.Project = project.Id
+	return fmt.Sprintf("%s (%d)", project.Name, utils.Deref(project.Id)), nil
+}
+
+// upload converts and sends a file like the upload commands do, unless the
+// ledger shows the same content was already uploaded to the project with the
+// same options, in which case the earlier upload is reused.
+func (r *pipelineRun) upload(kind ledger.Kind, path string, id **int) (string, error) {
+	project := *r.state.Project
+	source := &uploadSource{file: path, inputFormat: r.pipeline.InputFormat, pseudonymize: r.pipeline.Pseudonymize}
+	book, err := loadLedger(r.client)
+	if err != nil {
+		return "", err
+	}
+	sha, err := ledger.HashFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	var dup *ledger.Entry
+	var uploaded *int
+	switch kind {
+	case ledger.KindTrainingData:
+		dup, _, err = findDuplicate(book, kind, project, sha, source.options(),
+			func(x int) (*openapi.TrainingData, error) { return findTrainingData(r.client, x) },
+			func(x *openapi.TrainingData) (*string, *int) { return x.Basename, x.Filesize })
+		if err == nil && dup == nil {
+			var x *openapi.TrainingData
+			if x, err = source.uploadTrainingData(r.client, book, project, sha); err == nil {
+				uploaded = x.Id
+			}
+		}
+	case ledger.KindItemMetaData:
+		dup, _, err = findDuplicate(book, kind, project, sha, source.options(),
+			func(x int) (*openapi.ItemMetaData, error) { return findItemMetaData(r.client, x) },
+			func(x *openapi.ItemMetaData) (*string, *int) { return x.Basename, x.Filesize })
+		if err == nil && dup == nil {
+			if err = source.checkItemMetaData(r.client, project, r.out); err == nil {
+				var x *openapi.ItemMetaData
+				if x, err = source.uploadItemMetaData(r.client, book, project, sha); err == nil {
+					uploaded = x.Id
+				}
+			}
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if dup != nil {
+		*id = &dup.ID
+		return fmt.Sprintf("reused %d, uploaded earlier from the same file", dup.ID), nil
+	}
+	*id = uploaded
+	return fmt.Sprintf("uploaded %s as %d", filepath.Base(path), utils.Deref(uploaded)), nil
+}
+
+func (r *pipelineRun) splitConfig() (string, error) {
+	x := r.pipeline.Split
+	list, err := r.client.GetSplitConfigs(nil, &x.Name, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range *list {
+		if utils.Deref(c.Name) == x.Name {
+			r.state.Split = c.Id
+			return fmt.Sprintf("reused %q (%d)", x.Name, utils.Deref(c.Id)), nil
+		}
+	}
+	created, err := r.client.CreateSplitConfig(&x.Name, x.Scheme, x.HeldoutRatio, x.NHeldout,
+		x.TestUserRatio, x.NTestUsers, x.RandomSeed)
+	if err != nil {
+		return "", err
+	}
+	r.state.Split = created.Id
+	return fmt.Sprintf("created %q (%d)", x.Name, utils.Deref(created.Id)), nil
+}
+
+func (r *pipelineRun) evaluationConfig() (string, error) {
+	x := r.pipeline.Evaluation
+	list, err := r.client.GetEvaluationConfigs(nil, &x.Name, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range *list {
+		if utils.Deref(c.Name) == x.Name {
+			r.state.Evaluation = c.Id
+			return fmt.Sprintf("reused %q (%d)", x.Name, utils.Deref(c.Id)), nil
+		}
+	}
+	created, err := r.client.CreateEvaluationConfig(&x.Name, x.Cutoff, x.TargetMetric)
+	if err != nil {
+		return "", err
+	}
+	r.state.Evaluation = created.Id
+	return fmt.Sprintf("created %q (%d)", x.Name, utils.Deref(created.Id)), nil
+}
+
+func (r *pipelineRun) createTuningJob() (string, error) {
+	s := r.state
+	spec, err := r.pipeline.JobSpec(*s.TrainingData, *s.Split, *s.Evaluation)
+	if err != nil {
+		return "", err
+	}
+	job, err := createParameterTuningJob(r.client, spec, tuningJobExtras{})
+	if err != nil {
+		return "", err
+	}
+	s.TuningJob = job.Id
+	return fmt.Sprintf("started %d", utils.Deref(job.Id)), nil
+}
+
+// waitForModel waits for the tuning job and then for the training of the
+// model it produced. A job that fails, in tuning or in training, is
+// forgotten, so the next run starts a new one.
+func (r *pipelineRun) waitForModel() (string, error) {
+	jobID := *r.state.TuningJob
+	fmt.Fprintf(r.out, "trained model: waiting for tuning job %d\n", jobID)
+	start := time.Now()
+	var job *openapi.ParameterTuningJob
+	for job == nil || job.TunedModel == nil {
+		var err error
+		job, err = pollParameterTuningJob(r.client, jobID, r.interval, r.remaining(start), nil)
+		if err != nil {
+			return "", err
+		}
+		if err := tuningJobFailure(r.client, job, r.out); err != nil {
+			return "", r.forgetTuningJob(err)
+		}
+		if job.TunedModel == nil {
+			if err := r.sleep(start); err != nil {
+				return "", fmt.Errorf("tuning job %d finished without a tuned model: %w", jobID, err)
+			}
+		}
+	}
+
+	modelID := *job.TunedModel
+	for {
+		model, err := findTrainedModel(r.client, modelID)
+		if err != nil {
+			return "", err
+		}
+		if model == nil {
+			return "", fmt.Errorf("trained model %d not found", modelID)
+		}
+		if done, failed := trainedModelState(model); failed {
+			if task := lastModelTask(model); task != nil && utils.Deref(task.Traceback) != "" {
+				fmt.Fprintln(r.out, strings.TrimRight(*task.Traceback, "\n"))
+			}
+			return "", r.forgetTuningJob(fmt.Errorf("training of model %d failed", modelID))
+		} else if done {
+			r.state.TrainedModel = &modelID
+			return fmt.Sprintf("%d (best score %s)", modelID, formatScore(job.BestScore)), nil
+		}
+		if err := r.sleep(start); err != nil {
+			return "", fmt.Errorf("waiting for trained model %d: %w", modelID, err)
+		}
+	}
+}
+
+// forgetTuningJob clears the failed tuning job from the state, so the next
+// run creates a new one, and returns err.
+func (r *pipelineRun) forgetTuningJob(err error) error {
+	r.state.TuningJob = nil
+	if saveErr := r.state.Save(); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// remaining is the time left of --timeout, or 0 for no limit.
+func (r *pipelineRun) remaining(start time.Time) time.Duration {
+	if r.timeout <= 0 {
+		return 0
+	}
+	return max(r.timeout-time.Since(start), time.Nanosecond)
+}
+
+// sleep waits until the next poll, or fails once --timeout has passed.
+func (r *pipelineRun) sleep(start time.Time) error {
+	wait, ok := nextPoll(start, r.interval, r.timeout)
+	if !ok {
+		return fmt.Errorf("timed out after %s", r.timeout)
+	}
+	time.Sleep(wait)
+	return nil
+}
+
+func (r *pipelineRun) smokeTest() (string, error) {
+	rec, err := r.client.SampleRecommend(*r.state.TrainedModel)
+	if err != nil {
+		return "", err
+	}
+	if len(rec.Recommendations) == 0 {
+		return "", fmt.Errorf("sample recommendation for user %q returned no items", rec.UserId)
+	}
+	r.state.SmokeTested = true
+	return fmt.Sprintf("%d items recommended for sample user %q", len(rec.Recommendations), rec.UserId), nil
+}
+
+func (r *pipelineRun) deploy() (string, error) {
+	ref := r.pipeline.DeploymentSlot
+	slots, err := listDeploymentSlots(r.client, *r.state.Project)
+	if err != nil {
+		return "", err
+	}
+	var slot *openapi.DeploymentSlot
+	for i, x := range slots {
+		if (ref.ID != nil && utils.Deref(x.Id) == *ref.ID) || (ref.ID == nil && x.Name == ref.Name) {
+			slot = &slots[i]
+		}
+	}
+	if slot == nil {
+		return "", fmt.Errorf("deployment slot %s not found in project %d", ref, *r.state.Project)
+	}
+	if _, err := r.client.UpdateDeploymentSlot(utils.Deref(slot.Id), nil, r.state.TrainedModel, nil); err != nil {
+		return "", err
+	}
+	r.state.DeploymentSlot = slot.Id
+	return fmt.Sprintf("%s now serves model %d", slot.Name, *r.state.TrainedModel), nil
+}
+
+// findTrainedModel returns nil if there is no trained model with the ID.
+func findTrainedModel(client api.Client, id int) (*openapi.TrainedModel, error) {
+	list, err := client.GetTrainedModels(nil, nil, &id, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if list.Results == nil || len(*list.Results) == 0 {
+		return nil, nil
+	}
+	return &(*list.Results)[0], nil
+}
+
+func lastModelTask(model *openapi.TrainedModel) *openapi.TaskResult {
+	if model.TaskLinks == nil || len(*model.TaskLinks) == 0 {
+		return nil
+	}
+	return &(*model.TaskLinks)[len(*model.TaskLinks)-1].Task
+}
+
+func printPipelineState(format string, s *pipeline.State) {
+	if format == "json" || format == "yaml" {
+		utils.PrintOutput(format, s)
+		return
+	}
+	writePipelineState(os.Stdout, s)
+}
+
+func writePipelineState(out io.Writer, s *pipeline.State) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	row := func(label string, v *int) {
+		if v != nil {
+			fmt.Fprintf(w, "%s:\t%d\n", label, *v)
+		}
+	}
+	row("Project", s.Project)
+	row("Training data", s.TrainingData)
+	row("Item meta data", s.ItemMetaData)
+	row("Split config", s.Split)
+	row("Evaluation config", s.Evaluation)
+	row("Tuning job", s.TuningJob)
+	row("Trained model", s.TrainedModel)
+	row("Deployment slot", s.DeploymentSlot)
+	_ = w.Flush()
+}
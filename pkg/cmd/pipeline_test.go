@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/pipeline"
+)
+
+func TestPipelineRunResumesCompletedSteps(t *testing.T) {
+	p, err := pipeline.Read(strings.NewReader(`
+project: 1
+training_data: a.csv
+split: {name: default}
+evaluation: {name: ndcg}
+smoke_test: false
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ptr := func(v int) *int { return &v }
+	state := pipeline.NewState(t.TempDir()+"/state.json", p)
+	state.Project, state.TrainingData, state.Split, state.Evaluation = ptr(1), ptr(2), ptr(3), ptr(4)
+	state.TuningJob, state.TrainedModel = ptr(5), ptr(6)
+
+	// Every step is either done or skipped, so no client is needed.
+	var out bytes.Buffer
+	run := &pipelineRun{pipeline: p, state: state, out: &out}
+	if err := run.run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "project: done in an earlier run\n" +
+		"training data: done in an earlier run\n" +
+		"item meta data: skipped\n" +
+		"split config: done in an earlier run\n" +
+		"evaluation config: done in an earlier run\n" +
+		"tuning job: done in an earlier run\n" +
+		"trained model: done in an earlier run\n" +
+		"smoke test: skipped\n" +
+		"deployment slot: skipped\n"
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestWritePipelineState(t *testing.T) {
+	ptr := func(v int) *int { return &v }
+	var buf bytes.Buffer
+	writePipelineState(&buf, &pipeline.State{Project: ptr(1), TrainingData: ptr(2), TrainedModel: ptr(6)})
+	got := buf.String()
+	for _, want := range []string{"Project:        1", "Training data:  2", "Trained model:  6"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Deployment slot") {
+		t.Errorf("expected unset steps to be left out:\n%s", got)
+	}
+}
+
+// fakePipelineServer serves the endpoints a pipeline run uses. Tuning jobs
+// complete on their second poll; trainingFails makes the training of their
+// model fail.
+type fakePipelineServer struct {
+	t             *testing.T
+	mu            sync.Mutex
+	trainingFails bool
+	uploads       map[string][]string // path -> uploaded file contents
+	jobs          int
+	polls         map[string]int
+	slotModel     *int
+}
+
+func (f *fakePipelineServer) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	page := func(results ...any) map[string]any {
+		return map[string]any{"count": len(results), "results": results}
+	}
+	job := func(id int, status string, tunedModel *int) map[string]any {
+		return map[string]any{"id": id, "data": 10, "split": 3, "evaluation": 4, "status": status,
+			"tuned_model": tunedModel, "best_score": 0.25}
+	}
+
+	switch key := r.Method + " " + r.URL.Path; key {
+	case "GET /api/v1/project/":
+		jsonResponse(w, http.StatusOK, []any{map[string]any{"id": 1, "name": "shop", "user_column": "user_id", "item_column": "item_id"}})
+	case "POST /api/v1/training-data/", "POST /api/v1/item-meta-data/":
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			f.t.Errorf("%s: %v", key, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		f.uploads[r.URL.Path] = append(f.uploads[r.URL.Path], string(data))
+		id := 10
+		if strings.Contains(key, "item") {
+			id = 20
+		}
+		jsonResponse(w, http.StatusCreated, map[string]any{"id": id, "project": 1, "basename": header.Filename, "filesize": len(data)})
+	case "GET /api/v1/training-data/", "GET /api/v1/item-meta-data/":
+		uploads := f.uploads[r.URL.Path]
+		if len(uploads) == 0 {
+			jsonResponse(w, http.StatusOK, page())
+			return
+		}
+		id, basename := 10, "interactions.csv"
+		if strings.Contains(key, "item") {
+			id, basename = 20, "items.csv"
+		}
+		jsonResponse(w, http.StatusOK, page(map[string]any{"id": id, "project": 1, "basename": basename,
+			"filesize": len(uploads[len(uploads)-1])}))
+	case "GET /api/v1/split-config/":
+		jsonResponse(w, http.StatusOK, []any{map[string]any{"id": 3, "name": "default"}})
+	case "GET /api/v1/evaluation-config/":
+		jsonResponse(w, http.StatusOK, []any{})
+	case "POST /api/v1/evaluation-config/":
+		jsonResponse(w, http.StatusCreated, map[string]any{"id": 4, "name": "ndcg"})
+	case "POST /api/v1/parameter-tuning-job/":
+		f.jobs++
+		jsonResponse(w, http.StatusCreated, job(4+f.jobs, "pending", nil))
+	case "GET /api/v1/parameter-tuning-job/5/", "GET /api/v1/parameter-tuning-job/6/":
+		f.polls[key]++
+		if f.polls[key] == 1 {
+			jsonResponse(w, http.StatusOK, job(4+f.jobs, "running", nil))
+			return
+		}
+		model := 100 + f.jobs
+		jsonResponse(w, http.StatusOK, job(4+f.jobs, "completed", &model))
+	case "GET /api/v1/trained-model/":
+		status := "SUCCESS"
+		if f.trainingFails {
+			status = "FAILURE"
+		}
+		jsonResponse(w, http.StatusOK, page(map[string]any{"id": 100 + f.jobs, "configuration": 1, "data_loc": 10,
+			"task_links": []any{map[string]any{"task": map[string]any{"task_id": "t", "status": status, "traceback": "Traceback: boom"}}}}))
+	case "POST /api/v1/trained-model/101/sample-recommend/", "POST /api/v1/trained-model/102/sample-recommend/":
+		jsonResponse(w, http.StatusOK, map[string]any{"user_id": "u", "user_profile": []string{},
+			"recommendations": []any{map[string]any{"item_id": "i", "score": 0.5}}})
+	case "GET /api/v1/deployment-slot/":
+		jsonResponse(w, http.StatusOK, page(map[string]any{"id": 7, "name": "main", "project": 1, "is_active": true}))
+	case "PATCH /api/v1/deployment-slot/7/":
+		var body struct {
+			TrainedModel *int `json:"trained_model"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.slotModel = body.TrainedModel
+		jsonResponse(w, http.StatusOK, map[string]any{"id": 7, "name": "main", "project": 1, "is_active": true,
+			"trained_model": body.TrainedModel})
+	default:
+		f.t.Errorf("unexpected request %s", key)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// newPipelineTest writes a pipeline with its data files to a temporary
+// directory, which is also used as the home directory for the upload ledger
+// and pseudonym mappings.
+func newPipelineTest(t *testing.T) (*fakePipelineServer, api.Client, *pipeline.Pipeline, string) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("RECOTEM_PSEUDONYM_KEY", "")
+	files := map[string]string{
+		"interactions.csv": "user_id,item_id\nalice,apple\nbob,banana\n",
+		"items.csv":        "item_id,title\napple,Apple\nbanana,Banana\n",
+		"pipeline.yaml": `project: shop
+training_data: interactions.csv
+item_meta_data: items.csv
+pseudonymize: hmac
+split: {name: default}
+evaluation: {name: ndcg, cutoff: 10}
+tuning: {n_trials: 5}
+deployment_slot: main
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p, err := pipeline.Load(filepath.Join(dir, "pipeline.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakePipelineServer{t: t, uploads: map[string][]string{}, polls: map[string]int{}}
+	server, client := newTestServer(f.handle)
+	t.Cleanup(server.Close)
+	return f, client, p, pipeline.StatePath(filepath.Join(dir, "pipeline.yaml"))
+}
+
+func runPipeline(t *testing.T, client api.Client, p *pipeline.Pipeline, state *pipeline.State) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	run := &pipelineRun{client: client, pipeline: p, state: state, out: &out, interval: time.Millisecond}
+	err := run.run()
+	return out.String(), err
+}
+
+func TestPipelineRun(t *testing.T) {
+	f, client, p, statePath := newPipelineTest(t)
+
+	state := pipeline.NewState(statePath, p)
+	out, err := runPipeline(t, client, p, state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"project: shop (1)",
+		"training data: uploaded interactions.csv as 10",
+		"item meta data: uploaded items.csv as 20",
+		`split config: reused "default" (3)`,
+		`evaluation config: created "ndcg" (4)`,
+		"tuning job: started 5",
+		"trained model: 101 (best score 0.25",
+		"smoke test: 1 items recommended",
+		"deployment slot: main now serves model 101",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if f.slotModel == nil || *f.slotModel != 101 {
+		t.Errorf("expected the slot to serve model 101, got %v", f.slotModel)
+	}
+	for path, uploads := range f.uploads {
+		for _, raw := range []string{"alice", "bob", "apple", "banana"} {
+			if strings.Contains(uploads[0], raw) {
+				t.Errorf("%s: raw ID %q was uploaded:\n%s", path, raw, uploads[0])
+			}
+		}
+	}
+	if !strings.Contains(f.uploads["/api/v1/item-meta-data/"][0], "Apple") {
+		t.Errorf("expected the titles to be kept:\n%s", f.uploads["/api/v1/item-meta-data/"][0])
+	}
+
+	saved, err := pipeline.LoadState(statePath, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.DeploymentSlot == nil || *saved.DeploymentSlot != 7 || !saved.SmokeTested {
+		t.Errorf("expected every step in the saved state, got %+v", saved)
+	}
+
+	// Starting over finds the training data in the upload ledger.
+	out, err = runPipeline(t, client, p, pipeline.NewState(statePath, p))
+	if err != nil {
+		t.Fatalf("unexpected error on restart: %v\n%s", err, out)
+	}
+	for _, want := range []string{"training data: reused 10", "item meta data: reused 20"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	for path, uploads := range f.uploads {
+		if len(uploads) != 1 {
+			t.Errorf("%s: expected one upload, got %d", path, len(uploads))
+		}
+	}
+}
+
+func TestPipelineRunResumesAfterTrainingFailure(t *testing.T) {
+	f, client, p, statePath := newPipelineTest(t)
+	f.trainingFails = true
+
+	out, err := runPipeline(t, client, p, pipeline.NewState(statePath, p))
+	if err == nil || !strings.Contains(err.Error(), "training of model 101 failed") {
+		t.Fatalf("expected the training to fail, got %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Traceback: boom") {
+		t.Errorf("expected the traceback in:\n%s", out)
+	}
+	state, err := pipeline.LoadState(statePath, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.TuningJob != nil || state.TrainingData == nil || state.Evaluation == nil {
+		t.Errorf("expected the failed job to be forgotten and earlier steps kept, got %+v", state)
+	}
+
+	f.trainingFails = false
+	out, err = runPipeline(t, client, p, state)
+	if err != nil {
+		t.Fatalf("unexpected error on resume: %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"training data: done in an earlier run",
+		"evaluation config: done in an earlier run",
+		"tuning job: started 6",
+		"deployment slot: main now serves model 102",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if n := len(f.uploads["/api/v1/training-data/"]); n != 1 {
+		t.Errorf("expected the training data to be uploaded once, got %d", n)
+	}
+}
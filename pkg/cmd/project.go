@@ -2,9 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"sort"
 	"strconv"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/api"
 	"recotem.org/cli/recotem/pkg/openapi"
 	"recotem.org/cli/recotem/pkg/utils"
 )
@@ -19,8 +23,13 @@ func newProjectCmd() *cobra.Command {
 	cmd.AddCommand(
 		newProjectListCmd(),
 		newProjectCreateCmd(),
+		newProjectGetCmd(),
+		newProjectUpdateCmd(),
 		newProjectDeleteCmd(),
 		newProjectSummaryCmd(),
+		newProjectExportCmd(),
+		newProjectImportCmd(),
+		newProjectTreeCmd(),
 	)
 
 	return cmd
@@ -88,12 +97,12 @@ func newProjectCreateCmd() *cobra.Command {
 	return cmd
 }
 
-func newProjectDeleteCmd() *cobra.Command {
+func newProjectGetCmd() *cobra.Command {
 	var id string
 
 	cmd := &cobra.Command{
-		Use:   "delete",
-		Short: "Delete a project",
+		Use:   "get",
+		Short: "Get a project",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, err := newClientFromCmd(cmd)
 			if err != nil {
@@ -103,11 +112,11 @@ func newProjectDeleteCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			err = client.DeleteProject(idInt)
+			project, err := client.GetProject(idInt)
 			if err != nil {
 				return err
 			}
-			utils.PrintId(getOutputFormat(), idInt)
+			printProject(getOutputFormat(), *project)
 			return nil
 		},
 	}
@@ -118,6 +127,58 @@ func newProjectDeleteCmd() *cobra.Command {
 	return cmd
 }
 
+func newProjectUpdateCmd() *cobra.Command {
+	var id, name, userColumn, itemColumn, timeColumn string
+
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update a project",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			idInt, err := strconv.Atoi(id)
+			if err != nil {
+				return err
+			}
+			changed := func(name, value string) *string {
+				if !cmd.Flags().Changed(name) {
+					return nil
+				}
+				return &value
+			}
+			namePtr := changed("name", name)
+			userColumnPtr := changed("user-column", userColumn)
+			itemColumnPtr := changed("item-column", itemColumn)
+			timeColumnPtr := changed("time-column", timeColumn)
+			if namePtr == nil && userColumnPtr == nil && itemColumnPtr == nil && timeColumnPtr == nil {
+				return fmt.Errorf("nothing to update: give --name, --user-column, --item-column or --time-column")
+			}
+			if (namePtr != nil && *namePtr == "") ||
+				(userColumnPtr != nil && *userColumnPtr == "") ||
+				(itemColumnPtr != nil && *itemColumnPtr == "") {
+				return fmt.Errorf("--name, --user-column and --item-column cannot be empty")
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			project, err := client.UpdateProject(idInt, namePtr, userColumnPtr, itemColumnPtr, timeColumnPtr)
+			if err != nil {
+				return err
+			}
+			printProject(getOutputFormat(), *project)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&id, "id", "i", "", "Project ID")
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Project name")
+	cmd.Flags().StringVarP(&userColumn, "user-column", "u", "", "User column")
+	cmd.Flags().StringVar(&itemColumn, "item-column", "", "Item column")
+	cmd.Flags().StringVarP(&timeColumn, "time-column", "t", "", "Time column (empty to clear)")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
 func newProjectSummaryCmd() *cobra.Command {
 	var id string
 
@@ -137,7 +198,7 @@ func newProjectSummaryCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			fmt.Println(string(summary))
+			printProjectSummary(getOutputFormat(), summary)
 			return nil
 		},
 	}
@@ -166,3 +227,51 @@ func printProject(format string, x openapi.Project) {
 			utils.Atoa(x.TimeColumn))
 	}
 }
+
+type summaryRow struct {
+	Label string
+	Value string
+}
+
+// summaryRows lists the summary counts in report order. Counts the server did
+// not return are omitted; unknown keys follow in alphabetical order.
+func summaryRows(s *api.ProjectSummary) []summaryRow {
+	var rows []summaryRow
+	add := func(label string, v *int) {
+		if v != nil {
+			rows = append(rows, summaryRow{label, strconv.Itoa(*v)})
+		}
+	}
+	add("Training data", s.NData)
+	add("Item metadata", s.NItemMetaData)
+	add("Completed tuning jobs", s.NCompleteJobs)
+	add("Trained models", s.NModels)
+	add("Model configurations", s.NModelConfigurations)
+	add("Deployment slots", s.NDeploymentSlots)
+	add("A/B tests", s.NAbTests)
+	add("Retraining schedules", s.NRetrainingSchedules)
+	if s.InsDatetime != nil {
+		rows = append(rows, summaryRow{"Created", utils.FormatTime(s.InsDatetime)})
+	}
+	keys := make([]string, 0, len(s.Extra))
+	for k := range s.Extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		rows = append(rows, summaryRow{k, fmt.Sprint(s.Extra[k])})
+	}
+	return rows
+}
+
+func printProjectSummary(format string, s *api.ProjectSummary) {
+	if format == "json" || format == "yaml" {
+		utils.PrintOutput(format, s)
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, row := range summaryRows(s) {
+		fmt.Fprintf(w, "%s:\t%s\n", row.Label, row.Value)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"recotem.org/cli/recotem/pkg/bundle"
+	"recotem.org/cli/recotem/pkg/openapi"
+)
+
+func TestRemapSlots(t *testing.T) {
+	slots := map[int]int{1: 10, 2: 20}
+
+	ids, missing := remapSlots([]int{2, 1}, slots)
+	if missing != nil || !reflect.DeepEqual(ids, []int{20, 10}) {
+		t.Errorf("expected [20 10], got %v (missing %v)", ids, missing)
+	}
+
+	_, missing = remapSlots([]int{1, 3}, slots)
+	if missing == nil || *missing != 3 {
+		t.Errorf("expected slot 3 to be missing, got %v", missing)
+	}
+}
+
+func TestBundleResources(t *testing.T) {
+	id := func(i int) *int { return &i }
+	name := "main"
+	m := &bundle.Manifest{
+		Project:             openapi.Project{Id: id(1), Name: "shop"},
+		TrainingData:        []bundle.File{{ID: 4, Basename: "a.csv"}},
+		SplitConfigs:        []openapi.SplitConfig{{Id: id(2)}},
+		ModelConfigurations: []openapi.ModelConfiguration{{Id: id(3), Name: &name}},
+		RetrainingSchedules: []openapi.RetrainingSchedule{{Id: id(5), CronExpression: "0 3 * * *"}},
+	}
+
+	expected := []bundleResource{
+		{Kind: "project", Name: "shop", ID: 1},
+		{Kind: "training-data", Name: "a.csv", ID: 4},
+		{Kind: "split-config", Name: "<NA>", ID: 2},
+		{Kind: "model-configuration", Name: "main", ID: 3},
+		{Kind: "retraining-schedule", Name: "0 3 * * *", ID: 5},
+	}
+	if got := bundleResources(m); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+// projectDependencies is everything deleted together with a project.
+type projectDependencies struct {
+	TrainingData        []openapi.TrainingData
+	ItemMetaData        []openapi.ItemMetaData
+	TrainedModels       []openapi.TrainedModel
+	DeploymentSlots     []openapi.DeploymentSlot
+	AbTests             []openapi.AbTest
+	RetrainingSchedules []openapi.RetrainingSchedule
+}
+
+func newProjectDeleteCmd() *cobra.Command {
+	var id string
+	var yes, force bool
+
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a project",
+		Long: "Delete a project and everything that belongs to it. The training data, item\n" +
+			"meta data, trained models, deployment slots, A/B tests and retraining schedules\n" +
+			"that will be lost are listed first, and the project name must be typed to\n" +
+			"confirm unless --yes is given. Projects with a running A/B test are not\n" +
+			"deleted unless --force is given.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			idInt, err := strconv.Atoi(id)
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			project, err := client.GetProject(idInt)
+			if err != nil {
+				return err
+			}
+			deps, err := fetchProjectDependencies(client, idInt)
+			if err != nil {
+				return err
+			}
+
+			stderr := cmd.ErrOrStderr()
+			printProjectDependencies(stderr, project, deps)
+			if running := runningAbTests(deps.AbTests); len(running) > 0 && !force {
+				return fmt.Errorf("project %q has running A/B tests (%s); stop them first or use --force",
+					project.Name, strings.Join(running, ", "))
+			}
+			if !yes {
+				if err := confirmProjectName(cmd.InOrStdin(), stderr, project.Name); err != nil {
+					return err
+				}
+			}
+
+			err = client.DeleteProject(idInt)
+			if err != nil {
+				return err
+			}
+			utils.PrintId(getOutputFormat(), idInt)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&id, "id", "i", "", "Project ID")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking for confirmation")
+	cmd.Flags().BoolVar(&force, "force", false, "Delete even if an A/B test is running")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func fetchProjectDependencies(client api.Client, project int) (*projectDependencies, error) {
+	deps := &projectDependencies{}
+	var err error
+	if deps.TrainingData, err = listTrainingData(client, project); err != nil {
+		return nil, err
+	}
+	if deps.ItemMetaData, err = listItemMetaData(client, project); err != nil {
+		return nil, err
+	}
+	if deps.TrainedModels, err = listTrainedModels(client, project); err != nil {
+		return nil, err
+	}
+	if deps.DeploymentSlots, err = listDeploymentSlots(client, project); err != nil {
+		return nil, err
+	}
+	if deps.AbTests, err = listAbTests(client, project); err != nil {
+		return nil, err
+	}
+	if deps.RetrainingSchedules, err = listRetrainingSchedules(client, deps.DeploymentSlots); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+func runningAbTests(tests []openapi.AbTest) []string {
+	var names []string
+	for _, t := range tests {
+		if t.Status == openapi.AbTestStatusRunning {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+func printProjectDependencies(out io.Writer, project *openapi.Project, deps *projectDependencies) {
+	fmt.Fprintf(out, "Deleting project %s %q will also delete:\n", utils.Itoa(project.Id), project.Name)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	var serving []string
+	for _, s := range deps.DeploymentSlots {
+		if s.TrainedModel != nil {
+			serving = append(serving, fmt.Sprintf("%s serves model %d", s.Name, *s.TrainedModel))
+		}
+	}
+	var active int
+	for _, s := range deps.RetrainingSchedules {
+		if s.IsActive {
+			active++
+		}
+	}
+	var tests []string
+	for _, t := range deps.AbTests {
+		if t.Status == openapi.AbTestStatusRunning || t.Status == openapi.AbTestStatusDraft {
+			tests = append(tests, fmt.Sprintf("%s is %s", t.Name, t.Status))
+		}
+	}
+
+	row := func(label string, n int, details []string) {
+		if len(details) == 0 {
+			fmt.Fprintf(w, "  %s\t%d\n", label, n)
+		} else {
+			fmt.Fprintf(w, "  %s\t%d\t%s\n", label, n, strings.Join(details, ", "))
+		}
+	}
+	row("training data", len(deps.TrainingData), nil)
+	row("item meta data", len(deps.ItemMetaData), nil)
+	row("trained models", len(deps.TrainedModels), nil)
+	row("deployment slots", len(deps.DeploymentSlots), serving)
+	row("A/B tests", len(deps.AbTests), tests)
+	var scheduleDetails []string
+	if len(deps.RetrainingSchedules) > 0 {
+		scheduleDetails = []string{fmt.Sprintf("%d active", active)}
+	}
+	row("retraining schedules", len(deps.RetrainingSchedules), scheduleDetails)
+	_ = w.Flush()
+}
+
+// confirmProjectName asks for the project name and fails unless it is typed
+// exactly.
+func confirmProjectName(in io.Reader, out io.Writer, name string) error {
+	fmt.Fprintf(out, "Type the project name %q to confirm: ", name)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		fmt.Fprintln(out)
+		return fmt.Errorf("confirmation required: type the project name or use --yes")
+	}
+	if strings.TrimSpace(line) != name {
+		return fmt.Errorf("project name does not match; nothing was deleted")
+	}
+	return nil
+}
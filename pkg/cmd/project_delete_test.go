@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"recotem.org/cli/recotem/pkg/openapi"
+)
+
+func TestConfirmProjectName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"matching name", "shop\n", ""},
+		{"matching name without newline", "shop", ""},
+		{"surrounding spaces", "  shop  \n", ""},
+		{"wrong name", "shop2\n", "does not match"},
+		{"no input", "", "use --yes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := confirmProjectName(strings.NewReader(tt.input), &out, "shop")
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if !strings.Contains(out.String(), `Type the project name "shop"`) {
+				t.Errorf("expected a prompt, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestRunningAbTests(t *testing.T) {
+	tests := []openapi.AbTest{
+		{Name: "a", Status: openapi.AbTestStatusRunning},
+		{Name: "b", Status: openapi.AbTestStatusStopped},
+		{Name: "c", Status: openapi.AbTestStatusRunning},
+	}
+	if got := runningAbTests(tests); !reflect.DeepEqual(got, []string{"a", "c"}) {
+		t.Errorf("expected [a c], got %v", got)
+	}
+}
+
+func TestPrintProjectDependencies(t *testing.T) {
+	id, model := 3, 12
+	deps := &projectDependencies{
+		TrainingData:    []openapi.TrainingData{{}, {}},
+		DeploymentSlots: []openapi.DeploymentSlot{{Name: "main", TrainedModel: &model}, {Name: "canary"}},
+		AbTests:         []openapi.AbTest{{Name: "exp", Status: openapi.AbTestStatusRunning}},
+		RetrainingSchedules: []openapi.RetrainingSchedule{
+			{IsActive: true}, {IsActive: false},
+		},
+	}
+	var out bytes.Buffer
+	printProjectDependencies(&out, &openapi.Project{Id: &id, Name: "shop"}, deps)
+
+	for _, want := range []string{
+		`Deleting project 3 "shop" will also delete:`,
+		"training data         2\n",
+		"main serves model 12",
+		"exp is running",
+		"retraining schedules  2  1 active",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/bundle"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+const (
+	bundleTrainingData = "training-data"
+	bundleItemMetaData = "item-meta-data"
+)
+
+// bundleResource is one resource written to or created from a bundle.
+type bundleResource struct {
+	Kind  string `json:"kind" yaml:"kind"`
+	Name  string `json:"name" yaml:"name"`
+	ID    int    `json:"id" yaml:"id"`
+	NewID *int   `json:"new_id,omitempty" yaml:"new_id,omitempty"`
+	Note  string `json:"note,omitempty" yaml:"note,omitempty"`
+}
+
+func newProjectExportCmd() *cobra.Command {
+	var id, file string
+	var withData bool
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export a project and its configuration as a bundle",
+		Long: "Write the project definition, split/evaluation configs used by its tuning jobs,\n" +
+			"model configurations, deployment slots, retraining schedules and A/B tests to a\n" +
+			"tar.gz bundle that \"project import\" can recreate on another server.\n" +
+			"With --with-data the training data and item meta data files are included too.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			idInt, err := strconv.Atoi(id)
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			m, err := collectBundle(client, idInt)
+			if err != nil {
+				return err
+			}
+			if err := writeBundle(client, m, file, withData); err != nil {
+				return err
+			}
+			printBundleResources(getOutputFormat(), bundleResources(m), false)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&id, "id", "i", "", "Project ID")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Bundle file to write (.tar.gz)")
+	cmd.Flags().BoolVar(&withData, "with-data", false, "Include training data and item meta data files")
+	_ = cmd.MarkFlagRequired("id")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+// collectBundle fetches the exported resources of a project. Split and
+// evaluation configs are not owned by a project, so the ones referenced by
+// its tuning jobs are exported.
+func collectBundle(client api.Client, projectID int) (*bundle.Manifest, error) {
+	project, err := client.GetProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	m := &bundle.Manifest{
+		Version:    bundle.FormatVersion,
+		ExportedAt: time.Now().UTC(),
+		Server:     client.Config.Url,
+		Project:    *project,
+	}
+
+	jobs, err := listParameterTuningJobs(client, projectID)
+	if err != nil {
+		return nil, err
+	}
+	splits, evaluations := map[int]bool{}, map[int]bool{}
+	for _, job := range jobs {
+		splits[job.Split] = true
+		evaluations[job.Evaluation] = true
+	}
+	for _, id := range sortedKeys(splits) {
+		x, err := getSplitConfig(client, id)
+		if err != nil {
+			return nil, err
+		}
+		if x != nil {
+			m.SplitConfigs = append(m.SplitConfigs, *x)
+		}
+	}
+	for _, id := range sortedKeys(evaluations) {
+		x, err := getEvaluationConfig(client, id)
+		if err != nil {
+			return nil, err
+		}
+		if x != nil {
+			m.EvaluationConfigs = append(m.EvaluationConfigs, *x)
+		}
+	}
+
+	if m.ModelConfigurations, err = listModelConfigurations(client, projectID); err != nil {
+		return nil, err
+	}
+	if m.DeploymentSlots, err = listDeploymentSlots(client, projectID); err != nil {
+		return nil, err
+	}
+	if m.RetrainingSchedules, err = listRetrainingSchedules(client, m.DeploymentSlots); err != nil {
+		return nil, err
+	}
+	if m.AbTests, err = listAbTests(client, projectID); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// writeBundle writes the bundle to file, downloading the data files into it
+// when withData is set. A partially written file is removed on failure.
+func writeBundle(client api.Client, m *bundle.Manifest, file string, withData bool) (err error) {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(file)
+		}
+	}()
+
+	w := bundle.NewWriter(f)
+	if withData {
+		projectID := utils.Deref(m.Project.Id)
+		trainingData, err := listTrainingData(client, projectID)
+		if err != nil {
+			return err
+		}
+		for _, td := range trainingData {
+			id := utils.Deref(td.Id)
+			path, _, remove, err := downloadTrainingData(client, id)
+			if err != nil {
+				return err
+			}
+			entry := bundle.File{ID: id, Basename: utils.Deref(td.Basename)}
+			entry.Path = bundle.DataPath(bundleTrainingData, id, entry.Basename)
+			err = w.AddFile(entry.Path, path)
+			remove()
+			if err != nil {
+				return err
+			}
+			m.TrainingData = append(m.TrainingData, entry)
+		}
+		itemMetaData, err := listItemMetaData(client, projectID)
+		if err != nil {
+			return err
+		}
+		for _, imd := range itemMetaData {
+			id := utils.Deref(imd.Id)
+			path, _, remove, err := downloadItemMetaData(client, id)
+			if err != nil {
+				return err
+			}
+			entry := bundle.File{ID: id, Basename: utils.Deref(imd.Basename)}
+			entry.Path = bundle.DataPath(bundleItemMetaData, id, entry.Basename)
+			err = w.AddFile(entry.Path, path)
+			remove()
+			if err != nil {
+				return err
+			}
+			m.ItemMetaData = append(m.ItemMetaData, entry)
+		}
+	}
+	return w.Close(m)
+}
+
+// bundleResources lists the resources in a manifest in import order.
+func bundleResources(m *bundle.Manifest) []bundleResource {
+	var rows []bundleResource
+	add := func(kind string, id *int, name string) {
+		rows = append(rows, bundleResource{Kind: kind, ID: utils.Deref(id), Name: name})
+	}
+	add("project", m.Project.Id, m.Project.Name)
+	for _, f := range m.TrainingData {
+		add(bundleTrainingData, &f.ID, f.Basename)
+	}
+	for _, f := range m.ItemMetaData {
+		add(bundleItemMetaData, &f.ID, f.Basename)
+	}
+	for _, x := range m.SplitConfigs {
+		add("split-config", x.Id, utils.Atoa(x.Name))
+	}
+	for _, x := range m.EvaluationConfigs {
+		add("evaluation-config", x.Id, utils.Atoa(x.Name))
+	}
+	for _, x := range m.ModelConfigurations {
+		add("model-configuration", x.Id, utils.Atoa(x.Name))
+	}
+	for _, x := range m.DeploymentSlots {
+		add("deployment-slot", x.Id, x.Name)
+	}
+	for _, x := range m.RetrainingSchedules {
+		add("retraining-schedule", x.Id, x.CronExpression)
+	}
+	for _, x := range m.AbTests {
+		add("ab-test", x.Id, x.Name)
+	}
+	return rows
+}
+
+// printBundleResources prints exported resources, or with imported set the
+// IDs they were created with.
+func printBundleResources(format string, rows []bundleResource, imported bool) {
+	if format == "json" || format == "yaml" {
+		if rows == nil {
+			rows = []bundleResource{}
+		}
+		utils.PrintOutput(format, rows)
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if imported {
+		fmt.Fprintln(w, "KIND\tNAME\tFROM\tTO\tNOTE")
+	} else {
+		fmt.Fprintln(w, "KIND\tNAME\tID")
+	}
+	for _, r := range rows {
+		if imported {
+			newID := utils.NoValue
+			if r.NewID != nil {
+				newID = strconv.Itoa(*r.NewID)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", r.Kind, r.Name, r.ID, newID, r.Note)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%d\n", r.Kind, r.Name, r.ID)
+		}
+	}
+	_ = w.Flush()
+}
+
+func sortedKeys(m map[int]bool) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/bundle"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+func newProjectImportCmd() *cobra.Command {
+	var file, name string
+	var skipData bool
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Recreate a project from a bundle",
+		Long: "Create the project and resources of a bundle written by \"project export\" on the\n" +
+			"current server, remapping the references between them to the new IDs.\n" +
+			"Trained models are not part of a bundle, so deployment slots are created\n" +
+			"without a model and A/B tests are created as drafts.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			dir, err := os.MkdirTemp("", "recotem-bundle-")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(dir)
+			m, err := bundle.Extract(f, dir)
+			if err != nil {
+				return err
+			}
+			if name != "" {
+				m.Project.Name = name
+			}
+
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			existing, err := client.GetProjects(nil, &m.Project.Name)
+			if err != nil {
+				return err
+			}
+			if existing != nil && len(*existing) > 0 {
+				return fmt.Errorf("project %q already exists; choose another name with --name", m.Project.Name)
+			}
+
+			imp := bundleImport{client: client, dir: dir, skipData: skipData}
+			err = imp.run(m)
+			printBundleResources(getOutputFormat(), imp.created, true)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Bundle file to read (.tar.gz)")
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Project name (defaults to the exported name)")
+	cmd.Flags().BoolVar(&skipData, "skip-data", false, "Do not upload data files contained in the bundle")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+// bundleImport creates the resources of a bundle in dependency order and
+// records each one, so a failure part way still reports what was created.
+type bundleImport struct {
+	client   api.Client
+	dir      string
+	skipData bool
+	created  []bundleResource
+}
+
+func (b *bundleImport) add(kind, name string, oldID *int, newID *int, note string) {
+	b.created = append(b.created, bundleResource{
+		Kind:  kind,
+		Name:  name,
+		ID:    utils.Deref(oldID),
+		NewID: newID,
+		Note:  note,
+	})
+}
+
+func (b *bundleImport) run(m *bundle.Manifest) error {
+	p := m.Project
+	project, err := b.client.CreateProject(p.Name, p.UserColumn, p.ItemColumn, p.TimeColumn)
+	if err != nil {
+		return fmt.Errorf("project %q: %w", p.Name, err)
+	}
+	b.add("project", project.Name, p.Id, project.Id, "")
+	projectID := utils.Deref(project.Id)
+
+	if !b.skipData {
+		for _, f := range m.TrainingData {
+			td, err := b.client.UploadTrainingData(projectID, bundle.LocalPath(b.dir, f.Path))
+			if err != nil {
+				return fmt.Errorf("training data %d: %w", f.ID, err)
+			}
+			b.add(bundleTrainingData, f.Basename, &f.ID, td.Id, "")
+		}
+		for _, f := range m.ItemMetaData {
+			imd, err := b.client.UploadItemMetaData(projectID, bundle.LocalPath(b.dir, f.Path))
+			if err != nil {
+				return fmt.Errorf("item meta data %d: %w", f.ID, err)
+			}
+			b.add(bundleItemMetaData, f.Basename, &f.ID, imd.Id, "")
+		}
+	}
+
+	for _, x := range m.SplitConfigs {
+		sc, err := b.client.CreateSplitConfig(x.Name, x.Scheme, x.HeldoutRatio, x.NHeldout,
+			x.TestUserRatio, x.NTestUsers, x.RandomSeed)
+		if err != nil {
+			return fmt.Errorf("split config %s: %w", utils.Itoa(x.Id), err)
+		}
+		b.add("split-config", utils.Atoa(x.Name), x.Id, sc.Id, "")
+	}
+	for _, x := range m.EvaluationConfigs {
+		ec, err := b.client.CreateEvaluationConfig(x.Name, x.Cutoff, x.TargetMetric)
+		if err != nil {
+			return fmt.Errorf("evaluation config %s: %w", utils.Itoa(x.Id), err)
+		}
+		b.add("evaluation-config", utils.Atoa(x.Name), x.Id, ec.Id, "")
+	}
+	for _, x := range m.ModelConfigurations {
+		mc, err := b.client.CreateModelConfiguration(x.Name, projectID, x.RecommenderClassName, x.ParametersJson)
+		if err != nil {
+			return fmt.Errorf("model configuration %s: %w", utils.Itoa(x.Id), err)
+		}
+		b.add("model-configuration", utils.Atoa(x.Name), x.Id, mc.Id, "")
+	}
+
+	slots := map[int]int{}
+	for _, x := range m.DeploymentSlots {
+		slot, err := b.client.CreateDeploymentSlot(x.Name, projectID, nil, x.IsActive)
+		if err != nil {
+			return fmt.Errorf("deployment slot %q: %w", x.Name, err)
+		}
+		note := ""
+		if x.TrainedModel != nil {
+			note = fmt.Sprintf("trained model %d not imported; assign one with deployment-slot update", *x.TrainedModel)
+		}
+		b.add("deployment-slot", x.Name, x.Id, slot.Id, note)
+		if x.Id != nil && slot.Id != nil {
+			slots[*x.Id] = *slot.Id
+		}
+	}
+	for _, x := range m.RetrainingSchedules {
+		slot, ok := slots[x.DeploymentSlot]
+		if !ok {
+			b.add("retraining-schedule", x.CronExpression, x.Id, nil,
+				fmt.Sprintf("skipped: deployment slot %d is not in the bundle", x.DeploymentSlot))
+			continue
+		}
+		rs, err := b.client.CreateRetrainingSchedule(slot, x.CronExpression, x.IsActive)
+		if err != nil {
+			return fmt.Errorf("retraining schedule %s: %w", utils.Itoa(x.Id), err)
+		}
+		b.add("retraining-schedule", x.CronExpression, x.Id, rs.Id, "")
+	}
+	for _, x := range m.AbTests {
+		ids, missing := remapSlots(x.Slots, slots)
+		if missing != nil {
+			b.add("ab-test", x.Name, x.Id, nil,
+				fmt.Sprintf("skipped: deployment slot %d is not in the bundle", *missing))
+			continue
+		}
+		ab, err := b.client.CreateAbTest(x.Name, projectID, ids)
+		if err != nil {
+			return fmt.Errorf("A/B test %q: %w", x.Name, err)
+		}
+		note := ""
+		if x.Status != openapi.AbTestStatusDraft {
+			note = fmt.Sprintf("was %s; created as draft", x.Status)
+		}
+		b.add("ab-test", x.Name, x.Id, ab.Id, note)
+	}
+	return nil
+}
+
+// remapSlots translates exported slot IDs, returning the first ID that has
+// no counterpart.
+func remapSlots(old []int, slots map[int]int) ([]int, *int) {
+	ids := make([]int, 0, len(old))
+	for _, id := range old {
+		newID, ok := slots[id]
+		if !ok {
+			return nil, &id
+		}
+		ids = append(ids, newID)
+	}
+	return ids, nil
+}
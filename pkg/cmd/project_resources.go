@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/openapi"
+)
+
+const listPageSize = 100
+
+// collectPages follows the pagination of a list endpoint. fetch returns the
+// results of a page and whether there is a next one.
+func collectPages[T any](fetch func(page, pageSize *int) ([]T, bool, error)) ([]T, error) {
+	var all []T
+	pageSize := listPageSize
+	for page := 1; ; page++ {
+		results, next, err := fetch(&page, &pageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, results...)
+		if !next || len(results) == 0 {
+			return all, nil
+		}
+	}
+}
+
+func results[T any](r *[]T) []T {
+	if r == nil {
+		return nil
+	}
+	return *r
+}
+
+// listTrainingData returns every training data of a project.
+func listTrainingData(client api.Client, project int) ([]openapi.TrainingData, error) {
+	return collectPages(func(page, pageSize *int) ([]openapi.TrainingData, bool, error) {
+		list, err := client.GetTrainingData(nil, page, pageSize, &project)
+		if err != nil {
+			return nil, false, err
+		}
+		return results(list.Results), list.Next != nil, nil
+	})
+}
+
+func listItemMetaData(client api.Client, project int) ([]openapi.ItemMetaData, error) {
+	return collectPages(func(page, pageSize *int) ([]openapi.ItemMetaData, bool, error) {
+		list, err := client.GetItemMetaData(nil, page, pageSize, &project)
+		if err != nil {
+			return nil, false, err
+		}
+		return results(list.Results), list.Next != nil, nil
+	})
+}
+
+func listModelConfigurations(client api.Client, project int) ([]openapi.ModelConfiguration, error) {
+	return collectPages(func(page, pageSize *int) ([]openapi.ModelConfiguration, bool, error) {
+		list, err := client.GetModelConfigurations(nil, page, pageSize, &project)
+		if err != nil {
+			return nil, false, err
+		}
+		return results(list.Results), list.Next != nil, nil
+	})
+}
+
+func listParameterTuningJobs(client api.Client, project int) ([]openapi.ParameterTuningJob, error) {
+	return collectPages(func(page, pageSize *int) ([]openapi.ParameterTuningJob, bool, error) {
+		list, err := client.GetParameterTuningJobs(nil, &project, nil, page, pageSize)
+		if err != nil {
+			return nil, false, err
+		}
+		return results(list.Results), list.Next != nil, nil
+	})
+}
+
+func listTrainedModels(client api.Client, project int) ([]openapi.TrainedModel, error) {
+	return collectPages(func(page, pageSize *int) ([]openapi.TrainedModel, bool, error) {
+		list, err := client.GetTrainedModels(nil, &project, nil, page, pageSize)
+		if err != nil {
+			return nil, false, err
+		}
+		return results(list.Results), list.Next != nil, nil
+	})
+}
+
+func listDeploymentSlots(client api.Client, project int) ([]openapi.DeploymentSlot, error) {
+	return collectPages(func(page, pageSize *int) ([]openapi.DeploymentSlot, bool, error) {
+		list, err := client.GetDeploymentSlots(page, pageSize, &project)
+		if err != nil {
+			return nil, false, err
+		}
+		return results(list.Results), list.Next != nil, nil
+	})
+}
+
+// listRetrainingSchedules returns the schedules of the given deployment slots.
+func listRetrainingSchedules(client api.Client, slots []openapi.DeploymentSlot) ([]openapi.RetrainingSchedule, error) {
+	var all []openapi.RetrainingSchedule
+	for _, slot := range slots {
+		if slot.Id == nil {
+			continue
+		}
+		schedules, err := collectPages(func(page, pageSize *int) ([]openapi.RetrainingSchedule, bool, error) {
+			list, err := client.GetRetrainingSchedules(page, pageSize, slot.Id)
+			if err != nil {
+				return nil, false, err
+			}
+			return results(list.Results), list.Next != nil, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, schedules...)
+	}
+	return all, nil
+}
+
+func listAbTests(client api.Client, project int) ([]openapi.AbTest, error) {
+	return collectPages(func(page, pageSize *int) ([]openapi.AbTest, bool, error) {
+		list, err := client.GetAbTests(page, pageSize, &project)
+		if err != nil {
+			return nil, false, err
+		}
+		return results(list.Results), list.Next != nil, nil
+	})
+}
+
+// getSplitConfig returns nil if there is no split config with the ID.
+func getSplitConfig(client api.Client, id int) (*openapi.SplitConfig, error) {
+	list, err := client.GetSplitConfigs(&id, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil || len(*list) == 0 {
+		return nil, nil
+	}
+	return &(*list)[0], nil
+}
+
+// getEvaluationConfig returns nil if there is no evaluation config with the ID.
+func getEvaluationConfig(client api.Client, id int) (*openapi.EvaluationConfig, error) {
+	list, err := client.GetEvaluationConfigs(&id, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil || len(*list) == 0 {
+		return nil, nil
+	}
+	return &(*list)[0], nil
+}
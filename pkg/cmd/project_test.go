@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"recotem.org/cli/recotem/pkg/api"
+)
+
+func TestSummaryRows(t *testing.T) {
+	var s api.ProjectSummary
+	body := `{"n_data": 2, "n_models": 3, "n_ab_tests": 0, "total_users": 10, "project_id": 1}`
+	if err := json.Unmarshal([]byte(body), &s); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []summaryRow{
+		{"Training data", "2"},
+		{"Trained models", "3"},
+		{"A/B tests", "0"},
+		{"project_id", "1"},
+		{"total_users", "10"},
+	}
+	if got := summaryRows(&s); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestProjectUpdateRequiresAField(t *testing.T) {
+	cmd := newProjectUpdateCmd()
+	cmd.SetArgs([]string{"--id", "1"})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error when no field is given")
+	}
+}
@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+// projectResources is every resource of a project, as shown by the tree.
+type projectResources struct {
+	Project             *openapi.Project
+	TrainingData        []openapi.TrainingData
+	ItemMetaData        []openapi.ItemMetaData
+	TuningJobs          []openapi.ParameterTuningJob
+	ModelConfigurations []openapi.ModelConfiguration
+	TrainedModels       []openapi.TrainedModel
+	DeploymentSlots     []openapi.DeploymentSlot
+	RetrainingSchedules []openapi.RetrainingSchedule
+	AbTests             []openapi.AbTest
+}
+
+// treeNode is a resource in the project tree.
+type treeNode struct {
+	Kind     string      `json:"kind" yaml:"kind"`
+	ID       int         `json:"id" yaml:"id"`
+	Name     string      `json:"name,omitempty" yaml:"name,omitempty"`
+	Status   string      `json:"status,omitempty" yaml:"status,omitempty"`
+	Time     *time.Time  `json:"time,omitempty" yaml:"time,omitempty"`
+	Detail   string      `json:"detail,omitempty" yaml:"detail,omitempty"`
+	Children []*treeNode `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+func newProjectTreeCmd() *cobra.Command {
+	var id string
+
+	cmd := &cobra.Command{
+		Use:   "tree",
+		Short: "Show the resources of a project as a tree",
+		Long: "Fetch all resources of a project and show how they relate: training data,\n" +
+			"tuning jobs, model configurations, trained models, deployment slots, and the\n" +
+			"A/B tests and retraining schedules of each slot, with statuses and timestamps.\n" +
+			"Resources that are not attached to anything are listed at the end.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			idInt, err := strconv.Atoi(id)
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			res, err := fetchProjectResources(client, idInt)
+			if err != nil {
+				return err
+			}
+			root := buildProjectTree(res)
+			format := getOutputFormat()
+			if format == "json" || format == "yaml" {
+				utils.PrintOutput(format, root)
+			} else {
+				writeTree(os.Stdout, root)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&id, "id", "i", "", "Project ID")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+// fetchProjectResources lists the resources of a project concurrently.
+func fetchProjectResources(client api.Client, projectID int) (*projectResources, error) {
+	res := &projectResources{}
+	err := runConcurrently(
+		func() (err error) {
+			res.Project, err = client.GetProject(projectID)
+			return err
+		},
+		func() (err error) {
+			res.TrainingData, err = listTrainingData(client, projectID)
+			return err
+		},
+		func() (err error) {
+			res.ItemMetaData, err = listItemMetaData(client, projectID)
+			return err
+		},
+		func() (err error) {
+			res.TuningJobs, err = listParameterTuningJobs(client, projectID)
+			return err
+		},
+		func() (err error) {
+			res.ModelConfigurations, err = listModelConfigurations(client, projectID)
+			return err
+		},
+		func() (err error) {
+			res.TrainedModels, err = listTrainedModels(client, projectID)
+			return err
+		},
+		func() (err error) {
+			if res.DeploymentSlots, err = listDeploymentSlots(client, projectID); err != nil {
+				return err
+			}
+			res.RetrainingSchedules, err = listRetrainingSchedules(client, res.DeploymentSlots)
+			return err
+		},
+		func() (err error) {
+			res.AbTests, err = listAbTests(client, projectID)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// runConcurrently runs the functions in parallel and returns the first error.
+func runConcurrently(fns ...func() error) error {
+	errs := make([]error, len(fns))
+	var wg sync.WaitGroup
+	for i, fn := range fns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = fn()
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildProjectTree arranges the resources as training data -> tuning jobs ->
+// model configurations -> trained models -> deployment slots -> A/B tests
+// and retraining schedules. Model configurations not created by a tuning job
+// on the same data hang directly off the training data their models were
+// trained on.
+func buildProjectTree(res *projectResources) *treeNode {
+	root := &treeNode{
+		Kind: "project",
+		ID:   utils.Deref(res.Project.Id),
+		Name: res.Project.Name,
+		Time: res.Project.InsDatetime,
+	}
+
+	configs := map[int]openapi.ModelConfiguration{}
+	for _, mc := range res.ModelConfigurations {
+		configs[utils.Deref(mc.Id)] = mc
+	}
+	attachedSlots := map[int]bool{}
+	usedConfigs := map[int]bool{}
+	placedModels := map[int]bool{}
+
+	slotNode := func(s openapi.DeploymentSlot) *treeNode {
+		id := utils.Deref(s.Id)
+		attachedSlots[id] = true
+		n := &treeNode{Kind: "deployment-slot", ID: id, Name: s.Name, Status: activeStatus(s.IsActive), Time: s.UpdatedAt}
+		for _, rs := range res.RetrainingSchedules {
+			if rs.DeploymentSlot != id {
+				continue
+			}
+			detail := ""
+			if rs.NextRunAt != nil {
+				detail = "next run " + utils.FormatTime(rs.NextRunAt)
+			}
+			n.Children = append(n.Children, &treeNode{Kind: "retraining-schedule", ID: utils.Deref(rs.Id),
+				Name: rs.CronExpression, Status: activeStatus(rs.IsActive), Time: rs.LastRunAt, Detail: detail})
+		}
+		for _, ab := range res.AbTests {
+			for _, slot := range ab.Slots {
+				if slot == id {
+					n.Children = append(n.Children, &treeNode{Kind: "ab-test", ID: utils.Deref(ab.Id),
+						Name: ab.Name, Status: string(ab.Status), Time: ab.StartTime})
+					break
+				}
+			}
+		}
+		return n
+	}
+	modelNode := func(m openapi.TrainedModel) *treeNode {
+		id := utils.Deref(m.Id)
+		placedModels[id] = true
+		n := &treeNode{Kind: "trained-model", ID: id, Name: utils.Deref(m.Basename), Status: lastTaskStatus(m.TaskLinks),
+			Time: m.InsDatetime}
+		for _, s := range res.DeploymentSlots {
+			if s.TrainedModel != nil && *s.TrainedModel == id {
+				n.Children = append(n.Children, slotNode(s))
+			}
+		}
+		return n
+	}
+	configNode := func(mc openapi.ModelConfiguration, data int) *treeNode {
+		id := utils.Deref(mc.Id)
+		usedConfigs[id] = true
+		n := &treeNode{Kind: "model-configuration", ID: id, Name: utils.Deref(mc.Name),
+			Detail: mc.RecommenderClassName, Time: mc.InsDatetime}
+		for _, m := range res.TrainedModels {
+			if m.Configuration == id && m.DataLoc == data {
+				n.Children = append(n.Children, modelNode(m))
+			}
+		}
+		return n
+	}
+
+	for _, td := range res.TrainingData {
+		tdID := utils.Deref(td.Id)
+		tdNode := &treeNode{Kind: "training-data", ID: tdID, Name: utils.Deref(td.Basename), Time: td.InsDatetime}
+		jobConfigs := map[int]bool{}
+		for _, job := range res.TuningJobs {
+			if job.Data != tdID {
+				continue
+			}
+			jobID := utils.Deref(job.Id)
+			detail := ""
+			if job.BestScore != nil {
+				detail = "best score " + utils.Ftoa(job.BestScore)
+			}
+			jobNode := &treeNode{Kind: "tuning-job", ID: jobID, Status: string(utils.Deref(job.Status)),
+				Time: job.InsDatetime, Detail: detail}
+			for _, mc := range res.ModelConfigurations {
+				if mc.TuningJob != nil && *mc.TuningJob == jobID {
+					jobConfigs[utils.Deref(mc.Id)] = true
+					jobNode.Children = append(jobNode.Children, configNode(mc, tdID))
+				}
+			}
+			tdNode.Children = append(tdNode.Children, jobNode)
+		}
+		seen := map[int]bool{}
+		for _, m := range res.TrainedModels {
+			if m.DataLoc != tdID || jobConfigs[m.Configuration] || seen[m.Configuration] {
+				continue
+			}
+			seen[m.Configuration] = true
+			if mc, ok := configs[m.Configuration]; ok {
+				tdNode.Children = append(tdNode.Children, configNode(mc, tdID))
+			}
+		}
+		root.Children = append(root.Children, tdNode)
+	}
+
+	for _, imd := range res.ItemMetaData {
+		root.Children = append(root.Children, &treeNode{Kind: "item-meta-data", ID: utils.Deref(imd.Id),
+			Name: utils.Deref(imd.Basename), Time: imd.InsDatetime})
+	}
+
+	// Anything not reached from the training data.
+	for _, m := range res.TrainedModels {
+		if !placedModels[utils.Deref(m.Id)] {
+			root.Children = append(root.Children, modelNode(m))
+		}
+	}
+	for _, mc := range res.ModelConfigurations {
+		if !usedConfigs[utils.Deref(mc.Id)] {
+			root.Children = append(root.Children, &treeNode{Kind: "model-configuration", ID: utils.Deref(mc.Id),
+				Name: utils.Deref(mc.Name), Detail: mc.RecommenderClassName, Time: mc.InsDatetime})
+		}
+	}
+	for _, s := range res.DeploymentSlots {
+		if !attachedSlots[utils.Deref(s.Id)] {
+			root.Children = append(root.Children, slotNode(s))
+		}
+	}
+	return root
+}
+
+func activeStatus(active bool) string {
+	if active {
+		return "active"
+	}
+	return "inactive"
+}
+
+func lastTaskStatus(links *[]openapi.TaskAndTrainedModelLink) string {
+	if links == nil || len(*links) == 0 {
+		return ""
+	}
+	task := (*links)[len(*links)-1].Task
+	if task.Status == nil {
+		return ""
+	}
+	return string(*task.Status)
+}
+
+func writeTree(w io.Writer, root *treeNode) {
+	fmt.Fprintln(w, treeLabel(root))
+	writeTreeChildren(w, root.Children, "")
+}
+
+func writeTreeChildren(w io.Writer, nodes []*treeNode, prefix string) {
+	for i, n := range nodes {
+		branch, indent := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, indent = "└── ", "    "
+		}
+		fmt.Fprintln(w, prefix+branch+treeLabel(n))
+		writeTreeChildren(w, n.Children, prefix+indent)
+	}
+}
+
+func treeLabel(n *treeNode) string {
+	parts := []string{n.Kind, strconv.Itoa(n.ID)}
+	if n.Name != "" {
+		parts = append(parts, n.Name)
+	}
+	if n.Status != "" {
+		parts = append(parts, "["+n.Status+"]")
+	}
+	if n.Detail != "" {
+		parts = append(parts, n.Detail)
+	}
+	if n.Time != nil {
+		parts = append(parts, utils.FormatTime(n.Time))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"recotem.org/cli/recotem/pkg/openapi"
+)
+
+func TestBuildProjectTree(t *testing.T) {
+	ptr := func(v int) *int { return &v }
+	name := func(v string) *string { return &v }
+	completed := openapi.ParameterTuningJobStatusCompleted
+	success := openapi.SUCCESS
+	score := float32(0.25)
+
+	res := &projectResources{
+		Project:      &openapi.Project{Id: ptr(1), Name: "shop"},
+		TrainingData: []openapi.TrainingData{{Id: ptr(10), Basename: name("a.csv")}},
+		TuningJobs: []openapi.ParameterTuningJob{
+			{Id: ptr(20), Data: 10, Status: &completed, BestScore: &score},
+		},
+		ModelConfigurations: []openapi.ModelConfiguration{
+			{Id: ptr(30), TuningJob: ptr(20), RecommenderClassName: "IALSRecommender"},
+			{Id: ptr(31), Name: name("manual"), RecommenderClassName: "TopPopRecommender"},
+			{Id: ptr(32), Name: name("unused"), RecommenderClassName: "TopPopRecommender"},
+		},
+		TrainedModels: []openapi.TrainedModel{
+			{Id: ptr(40), Configuration: 30, DataLoc: 10, TaskLinks: &[]openapi.TaskAndTrainedModelLink{
+				{Task: openapi.TaskResult{Status: &success}},
+			}},
+			{Id: ptr(41), Configuration: 31, DataLoc: 10},
+		},
+		DeploymentSlots: []openapi.DeploymentSlot{
+			{Id: ptr(50), Name: "main", TrainedModel: ptr(40), IsActive: true},
+			{Id: ptr(51), Name: "spare"},
+		},
+		RetrainingSchedules: []openapi.RetrainingSchedule{
+			{Id: ptr(60), DeploymentSlot: 50, CronExpression: "0 3 * * *", IsActive: true},
+		},
+		AbTests: []openapi.AbTest{
+			{Id: ptr(70), Name: "exp", Slots: []int{50, 51}, Status: openapi.AbTestStatusRunning},
+		},
+	}
+
+	var out bytes.Buffer
+	writeTree(&out, buildProjectTree(res))
+	want := `project 1 shop
+├── training-data 10 a.csv
+│   ├── tuning-job 20 [completed] best score 0.25
+│   │   └── model-configuration 30 IALSRecommender
+│   │       └── trained-model 40 [SUCCESS]
+│   │           └── deployment-slot 50 main [active]
+│   │               ├── retraining-schedule 60 0 3 * * * [active]
+│   │               └── ab-test 70 exp [running]
+│   └── model-configuration 31 manual TopPopRecommender
+│       └── trained-model 41
+├── model-configuration 32 unused TopPopRecommender
+└── deployment-slot 51 spare [inactive]
+    └── ab-test 70 exp [running]
+`
+	if out.String() != want {
+		t.Errorf("unexpected tree:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestRunConcurrently(t *testing.T) {
+	boom := errors.New("boom")
+	calls := make([]bool, 3)
+	err := runConcurrently(
+		func() error { calls[0] = true; return nil },
+		func() error { calls[1] = true; return boom },
+		func() error { calls[2] = true; return nil },
+	)
+	if !errors.Is(err, boom) {
+		t.Errorf("expected boom, got %v", err)
+	}
+	for i, called := range calls {
+		if !called {
+			t.Errorf("function %d was not called", i)
+		}
+	}
+}
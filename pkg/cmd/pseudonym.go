@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/cfg"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/pseudonym"
+)
+
+// pseudonymDir is where mappings for the configured server are kept:
+// ~/.recotem/pseudonyms/<server>/project-<id>.map
+func pseudonymDir(client api.Client) (string, error) {
+	return cfg.StateDir("pseudonyms", client.Config.ServerKey())
+}
+
+func pseudonymSecret() ([]byte, error) {
+	keyFile, err := cfg.StateDir("pseudonym.key")
+	if err != nil {
+		return nil, err
+	}
+	return pseudonym.LoadKey(keyFile)
+}
+
+func pseudonymPath(client api.Client, projectID int) (string, error) {
+	dir, err := pseudonymDir(client)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("project-%d.map", projectID)), nil
+}
+
+func openPseudonymStore(client api.Client, projectID int, mode pseudonym.Mode) (*pseudonym.Store, error) {
+	path, err := pseudonymPath(client, projectID)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := pseudonymSecret()
+	if err != nil {
+		return nil, err
+	}
+	return pseudonym.Open(path, secret, mode)
+}
+
+// modelPseudonyms returns the mapping of the project a trained model was
+// trained in, or nil if IDs of that project were never pseudonymized.
+func modelPseudonyms(client api.Client, modelID int) (*pseudonym.Mapping, error) {
+	dir, err := pseudonymDir(client)
+	if err != nil {
+		return nil, err
+	}
+	// Avoid the extra lookups below for servers that never had
+	// pseudonymized uploads.
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) == 0 {
+		return nil, nil
+	}
+
+	models, err := client.GetTrainedModels(nil, nil, &modelID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if models.Results == nil || len(*models.Results) == 0 {
+		return nil, fmt.Errorf("trained model %d not found", modelID)
+	}
+	dataLoc := (*models.Results)[0].DataLoc
+	data, err := client.GetTrainingData(&dataLoc, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if data.Results == nil || len(*data.Results) == 0 {
+		return nil, fmt.Errorf("training data %d not found", dataLoc)
+	}
+
+	path, err := pseudonymPath(client, (*data.Results)[0].Project)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	secret, err := pseudonymSecret()
+	if err != nil {
+		return nil, err
+	}
+	store, err := pseudonym.Load(path, secret)
+	if err != nil {
+		return nil, err
+	}
+	return store.Mapping, nil
+}
+
+func addRawIDsFlag(cmd *cobra.Command, rawIDs *bool) {
+	cmd.Flags().BoolVar(rawIDs, "raw-ids", false, "Skip translation through the local pseudonym mapping")
+}
+
+// recommendPseudonyms is modelPseudonyms unless --raw-ids was given.
+func recommendPseudonyms(client api.Client, modelID int, rawIDs bool) (*pseudonym.Mapping, error) {
+	if rawIDs {
+		return nil, nil
+	}
+	return modelPseudonyms(client, modelID)
+}
+
+// pseudonymizeIDs translates raw IDs given on the command line into the
+// pseudonyms the server knows them by.
+func pseudonymizeIDs(m *pseudonym.Mapping, kind pseudonym.Kind, ids []string) ([]string, error) {
+	if m == nil {
+		return ids, nil
+	}
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		p, ok := m.Lookup(kind, id)
+		if !ok {
+			return nil, fmt.Errorf("%s %q has no pseudonym in the local mapping (use --raw-ids to pass server IDs)", kind, id)
+		}
+		out[i] = p
+	}
+	return out, nil
+}
+
+// revealRecommendation replaces pseudonyms in a server response with the
+// original IDs. Unknown pseudonyms are left unchanged.
+func revealRecommendation(m *pseudonym.Mapping, r *openapi.RawRecommendation) {
+	if m == nil || r == nil {
+		return
+	}
+	if raw, ok := m.Reveal(pseudonym.KindUser, r.UserId); ok {
+		r.UserId = raw
+	}
+	for i, item := range r.UserProfile {
+		if raw, ok := m.Reveal(pseudonym.KindItem, item); ok {
+			r.UserProfile[i] = raw
+		}
+	}
+	for i, rec := range r.Recommendations {
+		if raw, ok := m.Reveal(pseudonym.KindItem, rec.ItemId); ok {
+			r.Recommendations[i].ItemId = raw
+		}
+	}
+}
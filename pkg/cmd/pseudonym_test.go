@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/pseudonym"
+)
+
+func TestPseudonymTranslation(t *testing.T) {
+	store, err := pseudonym.Open(filepath.Join(t.TempDir(), "project-1.map"), []byte("secret"), pseudonym.ModeSequential)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := store.Mapping
+	m.Pseudonym(pseudonym.KindUser, "alice")
+	m.Pseudonym(pseudonym.KindItem, "apple")
+	m.Pseudonym(pseudonym.KindItem, "banana")
+
+	ids, err := pseudonymizeIDs(m, pseudonym.KindItem, []string{"banana", "apple"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []string{"item-2", "item-1"}) {
+		t.Errorf("pseudonymizeIDs = %v", ids)
+	}
+	if _, err := pseudonymizeIDs(m, pseudonym.KindUser, []string{"bob"}); err == nil {
+		t.Error("expected error for an unknown user")
+	}
+	if ids, _ := pseudonymizeIDs(nil, pseudonym.KindUser, []string{"bob"}); ids[0] != "bob" {
+		t.Error("IDs should pass through without a mapping")
+	}
+
+	r := &openapi.RawRecommendation{
+		UserId:      "user-1",
+		UserProfile: []string{"item-1"},
+		Recommendations: []openapi.IDAndScore{
+			{ItemId: "item-2", Score: 0.9},
+			{ItemId: "item-99", Score: 0.1},
+		},
+	}
+	revealRecommendation(m, r)
+	if r.UserId != "alice" || r.UserProfile[0] != "apple" {
+		t.Errorf("unexpected user fields: %+v", r)
+	}
+	if r.Recommendations[0].ItemId != "banana" || r.Recommendations[1].ItemId != "item-99" {
+		t.Errorf("unexpected recommendations: %+v", r.Recommendations)
+	}
+}
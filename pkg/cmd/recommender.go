@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/recommender"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+func newRecommenderCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recommender",
+		Short: "Browse the built-in catalog of recommender classes",
+	}
+
+	cmd.AddCommand(
+		newRecommenderListCmd(),
+		newRecommenderDescribeCmd(),
+	)
+
+	return cmd
+}
+
+func newRecommenderListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the recommender classes model configurations can use",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := getOutputFormat()
+			if format == "json" || format == "yaml" {
+				utils.PrintOutput(format, recommender.Classes())
+				return nil
+			}
+			writeRecommenderList(os.Stdout, recommender.Classes())
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newRecommenderDescribeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "describe <class>",
+		Short:   "Show the parameters of a recommender class",
+		Example: "  recotem recommender describe IALSRecommender",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := recommender.Find(args[0])
+			if err != nil {
+				return err
+			}
+			format := getOutputFormat()
+			if format == "json" || format == "yaml" {
+				utils.PrintOutput(format, c)
+				return nil
+			}
+			writeRecommenderClass(os.Stdout, c)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func writeRecommenderList(out io.Writer, classes []recommender.Class) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLASS\tPARAMETERS\tDESCRIPTION")
+	for _, c := range classes {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", c.Name, len(c.Parameters), c.Description)
+	}
+	_ = w.Flush()
+}
+
+func writeRecommenderClass(out io.Writer, c *recommender.Class) {
+	fmt.Fprintf(out, "%s: %s\n", c.Name, c.Description)
+	if len(c.Parameters) == 0 {
+		fmt.Fprintln(out, "No parameters.")
+		return
+	}
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PARAMETER\tTYPE\tDEFAULT\tRANGE\tDESCRIPTION")
+	for _, p := range c.Parameters {
+		def := utils.NoValue
+		if p.Default != nil {
+			def = fmt.Sprint(p.Default)
+		} else if p.Nullable {
+			def = "null"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Name, p.Type, def, p.Range(), p.Description)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"recotem.org/cli/recotem/pkg/recommender"
+)
+
+func TestWriteRecommenderClass(t *testing.T) {
+	c, _ := recommender.Lookup("RP3betaRecommender")
+	var buf bytes.Buffer
+	writeRecommenderClass(&buf, c)
+	got := buf.String()
+	for _, want := range []string{"RP3betaRecommender: ", "PARAMETER", "alpha", ">= 0", "top_k", "null"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+
+	top, _ := recommender.Lookup("TopPopRecommender")
+	buf.Reset()
+	writeRecommenderClass(&buf, top)
+	if !strings.Contains(buf.String(), "No parameters.") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestValidateModelConfigurationJSON(t *testing.T) {
+	var warn bytes.Buffer
+	if err := validateModelConfigurationJSON("IALSRecommender", `{"n_components": 64}`, &warn); err != nil || warn.Len() > 0 {
+		t.Errorf("unexpected problems: %v, %q", err, warn.String())
+	}
+	if err := validateModelConfigurationJSON("IALSRecommender", `{"n_components": 64, "block_size": 8}`, &warn); err != nil {
+		t.Errorf("expected an unknown parameter not to be an error, got %v", err)
+	}
+	if want := "warning: block_size: not a parameter of IALSRecommender\n"; warn.String() != want {
+		t.Errorf("expected %q, got %q", want, warn.String())
+	}
+	if err := validateModelConfigurationJSON("IALSRecommender", `{"n_components": 64`, io.Discard); err == nil || !strings.Contains(err.Error(), "--parameters-json") {
+		t.Errorf("expected a JSON error, got %v", err)
+	}
+	if err := validateModelConfigurationJSON("IALS", `{}`, io.Discard); err == nil {
+		t.Error("expected an unknown class error")
+	}
+}
@@ -34,6 +34,7 @@ func NewRootCmd(version, commit, buildTime string) *cobra.Command {
 		newItemMetaDataCmd(),
 		newTrainedModelCmd(),
 		newModelConfigurationCmd(),
+		newRecommenderCmd(),
 		newEvaluationConfigCmd(),
 		newSplitConfigCmd(),
 		newParameterTuningJobCmd(),
@@ -44,7 +45,12 @@ func NewRootCmd(version, commit, buildTime string) *cobra.Command {
 		newRetrainingScheduleCmd(),
 		newRetrainingRunCmd(),
 		newTaskLogCmd(),
+		newTaskCmd(),
 		newUserCmd(),
+		newPlanCmd(),
+		newApplyCmd(),
+		newDriftCmd(),
+		newPipelineCmd(),
 	)
 
 	return rootCmd
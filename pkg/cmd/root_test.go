@@ -95,6 +95,7 @@ func TestRootCmdSubcommands(t *testing.T) {
 		"item-meta-data",
 		"trained-model",
 		"model-configuration",
+		"recommender",
 		"evaluation-config",
 		"split-config",
 		"parameter-tuning-job",
@@ -105,14 +106,19 @@ func TestRootCmdSubcommands(t *testing.T) {
 		"retraining-schedule",
 		"retraining-run",
 		"task-log",
+		"task",
 		"user",
+		"plan",
+		"apply",
+		"drift",
+		"pipeline",
 	}
 
 	assertSubcommands(t, cmd, expectedSubcommands)
 
 	// Verify the total count of registered subcommands.
 	// Cobra may add a built-in "help" command, so we check that at least
-	// all 21 explicitly registered commands are present.
+	// all 27 explicitly registered commands are present.
 	registered := cmd.Commands()
 	if len(registered) < len(expectedSubcommands) {
 		t.Errorf("expected at least %d subcommands, got %d", len(expectedSubcommands), len(registered))
@@ -128,7 +134,7 @@ func TestProjectCmdSubcommands(t *testing.T) {
 
 	assertAlias(t, projectCmd, "p")
 
-	expected := []string{"list", "create", "delete", "summary"}
+	expected := []string{"list", "create", "get", "update", "delete", "summary", "export", "import", "tree"}
 	assertSubcommands(t, projectCmd, expected)
 }
 
@@ -141,7 +147,7 @@ func TestTrainingDataCmdSubcommands(t *testing.T) {
 
 	assertAlias(t, tdCmd, "td")
 
-	expected := []string{"list", "upload", "delete", "download", "preview"}
+	expected := []string{"list", "upload", "delete", "download", "preview", "stats", "prepare", "append", "diff", "versions"}
 	assertSubcommands(t, tdCmd, expected)
 }
 
@@ -154,7 +160,7 @@ func TestItemMetaDataCmdSubcommands(t *testing.T) {
 
 	assertAlias(t, imdCmd, "imd")
 
-	expected := []string{"list", "upload", "delete", "download"}
+	expected := []string{"list", "upload", "delete", "download", "inspect", "coverage"}
 	assertSubcommands(t, imdCmd, expected)
 }
 
@@ -167,7 +173,7 @@ func TestTrainedModelCmdSubcommands(t *testing.T) {
 
 	assertAlias(t, tmCmd, "tm")
 
-	expected := []string{"list", "create", "delete", "download", "recommend", "sample-recommend", "recommend-profile"}
+	expected := []string{"list", "create", "delete", "download", "recommend", "recommend-batch", "sample-recommend", "recommend-profile"}
 	assertSubcommands(t, tmCmd, expected)
 }
 
@@ -180,10 +186,21 @@ func TestModelConfigurationCmdSubcommands(t *testing.T) {
 
 	assertAlias(t, mcCmd, "mc")
 
-	expected := []string{"list", "create", "delete", "update"}
+	expected := []string{"list", "create", "delete", "update", "edit"}
 	assertSubcommands(t, mcCmd, expected)
 }
 
+func TestRecommenderCmdSubcommands(t *testing.T) {
+	cmd := NewRootCmd("1.0.0", "abc123", "2024-01-01")
+	recCmd := findSubcommand(cmd, "recommender")
+	if recCmd == nil {
+		t.Fatal("expected recommender command to exist")
+	}
+
+	expected := []string{"list", "describe"}
+	assertSubcommands(t, recCmd, expected)
+}
+
 func TestEvaluationConfigCmdSubcommands(t *testing.T) {
 	cmd := NewRootCmd("1.0.0", "abc123", "2024-01-01")
 	ecCmd := findSubcommand(cmd, "evaluation-config")
@@ -219,7 +236,7 @@ func TestParameterTuningJobCmdSubcommands(t *testing.T) {
 
 	assertAlias(t, ptjCmd, "ptj")
 
-	expected := []string{"list", "create", "delete"}
+	expected := []string{"list", "create", "get", "watch", "wait", "leaderboard", "rerun", "delete"}
 	assertSubcommands(t, ptjCmd, expected)
 }
 
@@ -310,10 +327,32 @@ func TestTaskLogCmdSubcommands(t *testing.T) {
 
 	assertAlias(t, tlCmd, "tl")
 
-	expected := []string{"list"}
+	expected := []string{"list", "tail"}
 	assertSubcommands(t, tlCmd, expected)
 }
 
+func TestPipelineCmdSubcommands(t *testing.T) {
+	cmd := NewRootCmd("1.0.0", "abc123", "2024-01-01")
+	pipelineCmd := findSubcommand(cmd, "pipeline")
+	if pipelineCmd == nil {
+		t.Fatal("expected pipeline command to exist")
+	}
+
+	expected := []string{"run"}
+	assertSubcommands(t, pipelineCmd, expected)
+}
+
+func TestTaskCmdSubcommands(t *testing.T) {
+	cmd := NewRootCmd("1.0.0", "abc123", "2024-01-01")
+	taskCmd := findSubcommand(cmd, "task")
+	if taskCmd == nil {
+		t.Fatal("expected task command to exist")
+	}
+
+	expected := []string{"list", "get"}
+	assertSubcommands(t, taskCmd, expected)
+}
+
 func TestUserCmdSubcommands(t *testing.T) {
 	cmd := NewRootCmd("1.0.0", "abc123", "2024-01-01")
 	userCmd := findSubcommand(cmd, "user")
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+// taskSummary is one task of a resource's history.
+type taskSummary struct {
+	Number          int        `json:"number" yaml:"number"`
+	TaskID          string     `json:"task_id" yaml:"task_id"`
+	Status          string     `json:"status" yaml:"status"`
+	DateCreated     *time.Time `json:"date_created,omitempty" yaml:"date_created,omitempty"`
+	DateDone        *time.Time `json:"date_done,omitempty" yaml:"date_done,omitempty"`
+	DurationSeconds *float64   `json:"duration_seconds,omitempty" yaml:"duration_seconds,omitempty"`
+	Finished        bool       `json:"finished" yaml:"finished"`
+	Failed          bool       `json:"failed" yaml:"failed"`
+	Traceback       *string    `json:"traceback,omitempty" yaml:"traceback,omitempty"`
+}
+
+// taskOwner is a resource that runs tasks, as given to --for.
+type taskOwner struct {
+	kind string
+	id   int
+}
+
+func (o taskOwner) String() string {
+	return fmt.Sprintf("%s %d", o.kind, o.id)
+}
+
+func newTaskCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "task",
+		Short: "Inspect the tasks run for trained models and tuning jobs",
+	}
+
+	cmd.AddCommand(
+		newTaskListCmd(),
+		newTaskGetCmd(),
+	)
+
+	return cmd
+}
+
+func newTaskListCmd() *cobra.Command {
+	var owner string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show the task history of a resource",
+		Long: "Show every task run for a trained model or parameter tuning job, oldest\n" +
+			"first, with its duration and, for failures, the exception it ended with.\n" +
+			"Use `task get` for the full traceback.",
+		Example: "  recotem task list --for trained-model:3\n" +
+			"  recotem task list --for parameter-tuning-job:4",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := parseTaskOwner(owner)
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			tasks, err := fetchTaskHistory(client, o)
+			if err != nil {
+				return err
+			}
+			summaries := summarizeTasks(tasks, time.Now())
+			format := getOutputFormat()
+			if format == "json" || format == "yaml" {
+				utils.PrintOutput(format, summaries)
+				return nil
+			}
+			if len(summaries) == 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "No tasks have run for %s\n", o)
+				return nil
+			}
+			writeTaskHistory(os.Stdout, summaries)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&owner, "for", "", "Resource as kind:id (trained-model or parameter-tuning-job)")
+	_ = cmd.MarkFlagRequired("for")
+
+	return cmd
+}
+
+func newTaskGetCmd() *cobra.Command {
+	var owner string
+
+	cmd := &cobra.Command{
+		Use:   "get [task-id]",
+		Short: "Show a task of a resource with its traceback",
+		Long: "Show one task run for a trained model or parameter tuning job: the latest,\n" +
+			"or the one whose Celery task ID starts with task-id. The traceback of a\n" +
+			"failed task is printed in full.",
+		Example: "  recotem task get --for trained-model:3\n" +
+			"  recotem task get --for ptj:4 5f0c",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := parseTaskOwner(owner)
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			tasks, err := fetchTaskHistory(client, o)
+			if err != nil {
+				return err
+			}
+			var prefix string
+			if len(args) > 0 {
+				prefix = args[0]
+			}
+			s, err := selectTask(summarizeTasks(tasks, time.Now()), o, prefix)
+			if err != nil {
+				return err
+			}
+			format := getOutputFormat()
+			if format == "json" || format == "yaml" {
+				utils.PrintOutput(format, s)
+				return nil
+			}
+			writeTaskDetail(os.Stdout, s, term.IsTerminal(int(os.Stdout.Fd())))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&owner, "for", "", "Resource as kind:id (trained-model or parameter-tuning-job)")
+	_ = cmd.MarkFlagRequired("for")
+
+	return cmd
+}
+
+// parseTaskOwner reads kind:id, accepting the command names and aliases of
+// the resource kinds.
+func parseTaskOwner(s string) (taskOwner, error) {
+	kind, id, ok := strings.Cut(s, ":")
+	if !ok {
+		return taskOwner{}, fmt.Errorf("--for: expected kind:id, got %q", s)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil {
+		return taskOwner{}, fmt.Errorf("--for: %q is not an ID", id)
+	}
+	switch strings.TrimSpace(kind) {
+	case "trained-model", "tm", "model":
+		return taskOwner{kind: "trained-model", id: n}, nil
+	case "parameter-tuning-job", "ptj", "tuning-job":
+		return taskOwner{kind: "parameter-tuning-job", id: n}, nil
+	}
+	return taskOwner{}, fmt.Errorf("--for: unknown kind %q (use trained-model or parameter-tuning-job)", kind)
+}
+
+func fetchTaskHistory(client api.Client, o taskOwner) ([]openapi.TaskResult, error) {
+	var tasks []openapi.TaskResult
+	switch o.kind {
+	case "trained-model":
+		m, err := findTrainedModel(client, o.id)
+		if err != nil {
+			return nil, err
+		}
+		if m == nil {
+			return nil, fmt.Errorf("trained model %d not found", o.id)
+		}
+		if m.TaskLinks != nil {
+			for _, l := range *m.TaskLinks {
+				tasks = append(tasks, l.Task)
+			}
+		}
+	default:
+		job, err := client.GetParameterTuningJob(o.id)
+		if err != nil {
+			return nil, err
+		}
+		if job.TaskLinks != nil {
+			for _, l := range *job.TaskLinks {
+				tasks = append(tasks, l.Task)
+			}
+		}
+	}
+	return tasks, nil
+}
+
+// summarizeTasks numbers tasks from 1 in the order the server links them.
+// Unfinished tasks are timed up to now.
+func summarizeTasks(tasks []openapi.TaskResult, now time.Time) []taskSummary {
+	summaries := make([]taskSummary, len(tasks))
+	for i, t := range tasks {
+		s := taskSummary{
+			Number:      i + 1,
+			TaskID:      t.TaskId,
+			Status:      utils.NoValue,
+			DateCreated: t.DateCreated,
+			DateDone:    t.DateDone,
+		}
+		if t.Status != nil {
+			s.Status = string(*t.Status)
+			switch *t.Status {
+			case openapi.SUCCESS:
+				s.Finished = true
+			case openapi.FAILURE, openapi.REVOKED:
+				s.Finished, s.Failed = true, true
+			}
+		}
+		if t.Traceback != nil && strings.TrimSpace(*t.Traceback) != "" {
+			s.Traceback = t.Traceback
+		}
+		end := now
+		if s.Finished && t.DateDone != nil {
+			end = *t.DateDone
+		}
+		if t.DateCreated != nil && !end.Before(*t.DateCreated) {
+			d := end.Sub(*t.DateCreated).Seconds()
+			s.DurationSeconds = &d
+		}
+		summaries[i] = s
+	}
+	return summaries
+}
+
+func selectTask(summaries []taskSummary, o taskOwner, prefix string) (taskSummary, error) {
+	if len(summaries) == 0 {
+		return taskSummary{}, fmt.Errorf("no tasks have run for %s", o)
+	}
+	if prefix == "" {
+		return summaries[len(summaries)-1], nil
+	}
+	var found []taskSummary
+	for _, s := range summaries {
+		if strings.HasPrefix(s.TaskID, prefix) {
+			found = append(found, s)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return taskSummary{}, fmt.Errorf("%s has no task %q", o, prefix)
+	case 1:
+		return found[0], nil
+	}
+	return taskSummary{}, fmt.Errorf("task ID %q is ambiguous for %s; give more of it", prefix, o)
+}
+
+func formatTaskDuration(s taskSummary) string {
+	if s.DurationSeconds == nil {
+		return utils.NoValue
+	}
+	d := (time.Duration(*s.DurationSeconds * float64(time.Second))).Round(time.Second).String()
+	if !s.Finished {
+		d += " so far"
+	}
+	return d
+}
+
+// exceptionLine is the line a Python traceback ends with, naming the
+// exception.
+func exceptionLine(tb string) string {
+	lines := strings.Split(strings.TrimRight(tb, "\n"), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func writeTaskHistory(out io.Writer, summaries []taskSummary) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tTASK ID\tSTATUS\tCREATED\tDURATION\tERROR")
+	for _, s := range summaries {
+		errLine := ""
+		if s.Failed && s.Traceback != nil {
+			errLine = exceptionLine(*s.Traceback)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			s.Number, s.TaskID, s.Status, utils.FormatTime(s.DateCreated), formatTaskDuration(s), errLine)
+	}
+	_ = w.Flush()
+}
+
+func writeTaskDetail(out io.Writer, s taskSummary, color bool) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Task:\t%d\n", s.Number)
+	fmt.Fprintf(w, "Task ID:\t%s\n", s.TaskID)
+	fmt.Fprintf(w, "Status:\t%s\n", s.Status)
+	fmt.Fprintf(w, "Created:\t%s\n", utils.FormatTime(s.DateCreated))
+	fmt.Fprintf(w, "Done:\t%s\n", utils.FormatTime(s.DateDone))
+	fmt.Fprintf(w, "Duration:\t%s\n", formatTaskDuration(s))
+	_ = w.Flush()
+	if s.Traceback != nil {
+		fmt.Fprintln(out)
+		fmt.Fprint(out, formatTraceback(*s.Traceback, color))
+	}
+}
+
+// formatTraceback indents a traceback under the task details, expands tabs
+// and, with color, dims the frame lines and highlights the exception.
+func formatTraceback(tb string, color bool) string {
+	lines := strings.Split(strings.TrimRight(tb, "\n"), "\n")
+	var b strings.Builder
+	for i, line := range lines {
+		line = strings.TrimRight(strings.ReplaceAll(line, "\t", "    "), " \r")
+		switch {
+		case !color:
+		case i == len(lines)-1:
+			line = "\033[1;31m" + line + "\033[0m"
+		case strings.HasPrefix(strings.TrimSpace(line), "File "):
+			line = "\033[2m" + line + "\033[0m"
+		}
+		b.WriteString("  " + line + "\n")
+	}
+	return b.String()
+}
@@ -1,10 +1,18 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/openapi"
 	"recotem.org/cli/recotem/pkg/utils"
 )
 
@@ -17,6 +25,7 @@ func newTaskLogCmd() *cobra.Command {
 
 	cmd.AddCommand(
 		newTaskLogListCmd(),
+		newTaskLogTailCmd(),
 	)
 
 	return cmd
@@ -33,7 +42,7 @@ func newTaskLogListCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			result, err := client.GetTaskLogs(
+			logs, err := client.GetTaskLogs(
 				utils.NilOrInt(page),
 				utils.NilOrInt(pageSize),
 				utils.NilOrInt(task))
@@ -42,13 +51,12 @@ func newTaskLogListCmd() *cobra.Command {
 			}
 			format := getOutputFormat()
 			if format == "json" || format == "yaml" {
-				var data any
-				if err := json.Unmarshal(result, &data); err != nil {
-					return fmt.Errorf("failed to parse response: %w", err)
-				}
-				utils.PrintOutput(format, data)
+				utils.PrintOutput(format, logs)
 			} else {
-				fmt.Println(string(result))
+				sortTaskLogs(logs)
+				for _, l := range logs {
+					writeTaskLog(os.Stdout, l, nil)
+				}
 			}
 			return nil
 		},
@@ -60,3 +68,310 @@ func newTaskLogListCmd() *cobra.Command {
 
 	return cmd
 }
+
+func newTaskLogTailCmd() *cobra.Command {
+	var task, tuningJob, model, since, grep string
+	var follow bool
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "tail",
+		Short: "Show the logs of a task, optionally following new entries",
+		Long: "Show the logs of a task, given by its ID or by the parameter tuning job or\n" +
+			"trained model it ran for. With --follow, poll for new entries until the task\n" +
+			"finishes; the command then exits with a non-zero status if it failed.\n\n" +
+			"The server reports the state of tasks only through their tuning job or\n" +
+			"model, so with --task, --follow keeps polling until interrupted.\n\n" +
+			"With -o json or -o yaml the entries are printed as one list; with --follow\n" +
+			"each entry is printed as it arrives, as a line of JSON or a YAML document.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := newTaskLogFilter(since, grep, time.Now())
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			src, err := taskLogSourceFromFlags(client, task, tuningJob, model)
+			if err != nil {
+				return err
+			}
+			output := newTaskLogOutput(getOutputFormat(), filter, follow)
+			fetch := func(after *int) ([]openapi.TaskLog, error) {
+				params := src.params
+				params.IdGt = after
+				return client.QueryTaskLogs(params)
+			}
+			state := src.state
+			if !follow {
+				state = nil
+			} else if state == nil {
+				state = func() (bool, bool, error) { return false, false, nil }
+			}
+			failed, err := followTaskLogs(fetch, state, interval, output.write)
+			if err != nil {
+				return err
+			}
+			output.flush()
+			if failed {
+				return fmt.Errorf("%s failed", src.name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&task, "task", "", "Task ID")
+	cmd.Flags().StringVar(&tuningJob, "tuning-job", "", "Show the tasks of a parameter tuning job")
+	cmd.Flags().StringVar(&model, "model", "", "Show the tasks of a trained model")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Poll for new entries until the task finishes")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Polling interval with --follow")
+	cmd.Flags().StringVar(&since, "since", "", "Only entries newer than a duration (e.g. 10m) or an RFC 3339 time")
+	cmd.Flags().StringVar(&grep, "grep", "", "Only lines matching a regular expression")
+	cmd.MarkFlagsMutuallyExclusive("task", "tuning-job", "model")
+	cmd.MarkFlagsOneRequired("task", "tuning-job", "model")
+
+	return cmd
+}
+
+// taskLogOutput prints the entries of a tail. Text is written line by line;
+// JSON and YAML are collected into one list, or streamed one entry at a time
+// when following, since a follow may not end.
+type taskLogOutput struct {
+	format  string
+	filter  *taskLogFilter
+	stream  *utils.StreamPrinter
+	entries []openapi.TaskLog
+}
+
+func newTaskLogOutput(format string, filter *taskLogFilter, follow bool) *taskLogOutput {
+	o := &taskLogOutput{format: format, filter: filter, entries: []openapi.TaskLog{}}
+	if follow {
+		o.stream = utils.NewStreamPrinter(format)
+	}
+	return o
+}
+
+func (o *taskLogOutput) write(l openapi.TaskLog) {
+	switch {
+	case o.format != "json" && o.format != "yaml":
+		writeTaskLog(os.Stdout, l, o.filter)
+	case !o.filter.match(l):
+	case o.stream != nil:
+		o.stream.Print(l)
+	default:
+		o.entries = append(o.entries, l)
+	}
+}
+
+// flush prints the collected entries, if any were held back.
+func (o *taskLogOutput) flush() {
+	if o.stream == nil && (o.format == "json" || o.format == "yaml") {
+		utils.PrintOutput(o.format, o.entries)
+	}
+}
+
+// taskLogSource is what a tail reads: a task log query and, where the server
+// can tell, the state of the task behind it.
+type taskLogSource struct {
+	name   string
+	params openapi.TaskLogListParams
+	state  func() (done, failed bool, err error)
+}
+
+func taskLogSourceFromFlags(client api.Client, task, tuningJob, model string) (taskLogSource, error) {
+	parse := func(flag, s string) (int, error) {
+		id, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return 0, fmt.Errorf("--%s: %q is not an ID", flag, s)
+		}
+		return id, nil
+	}
+	switch {
+	case tuningJob != "":
+		id, err := parse("tuning-job", tuningJob)
+		if err != nil {
+			return taskLogSource{}, err
+		}
+		return taskLogSource{
+			name:   fmt.Sprintf("parameter tuning job %d", id),
+			params: openapi.TaskLogListParams{TuningJobId: &id},
+			state: func() (bool, bool, error) {
+				job, err := client.GetParameterTuningJob(id)
+				if err != nil {
+					return false, false, err
+				}
+				done, failed := tuningJobState(job)
+				return done, failed, nil
+			},
+		}, nil
+	case model != "":
+		id, err := parse("model", model)
+		if err != nil {
+			return taskLogSource{}, err
+		}
+		return taskLogSource{
+			name:   fmt.Sprintf("trained model %d", id),
+			params: openapi.TaskLogListParams{ModelId: &id},
+			state: func() (bool, bool, error) {
+				m, err := findTrainedModel(client, id)
+				if err != nil {
+					return false, false, err
+				}
+				if m == nil {
+					return false, false, fmt.Errorf("trained model %d not found", id)
+				}
+				done, failed := trainedModelState(m)
+				return done, failed, nil
+			},
+		}, nil
+	default:
+		id, err := parse("task", task)
+		if err != nil {
+			return taskLogSource{}, err
+		}
+		return taskLogSource{
+			name:   fmt.Sprintf("task %d", id),
+			params: openapi.TaskLogListParams{Task: &id},
+		}, nil
+	}
+}
+
+// trainedModelState reports whether a model's latest task has finished and
+// whether it failed. Models trained by their tuning job have no task of their
+// own and count as finished.
+func trainedModelState(m *openapi.TrainedModel) (done, failed bool) {
+	task := lastModelTask(m)
+	if task == nil {
+		return true, false
+	}
+	if task.Status == nil {
+		return false, false
+	}
+	switch *task.Status {
+	case openapi.SUCCESS:
+		return true, false
+	case openapi.FAILURE, openapi.REVOKED:
+		return true, true
+	}
+	return false, false
+}
+
+// followTaskLogs passes every new entry to write, oldest first, once each.
+// Without state it fetches once. Otherwise it polls until state reports the
+// task done; the state is checked before each fetch, so entries written
+// before the task finished are never missed.
+func followTaskLogs(fetch func(after *int) ([]openapi.TaskLog, error), state func() (bool, bool, error),
+	interval time.Duration, write func(openapi.TaskLog)) (failed bool, err error) {
+	if state != nil && interval <= 0 {
+		return false, fmt.Errorf("--interval must be positive")
+	}
+	seen := map[int]bool{}
+	var after *int
+	for {
+		done := true
+		if state != nil {
+			if done, failed, err = state(); err != nil {
+				return false, err
+			}
+		}
+		logs, err := fetch(after)
+		if err != nil {
+			return false, err
+		}
+		sortTaskLogs(logs)
+		for _, l := range logs {
+			if l.Id != nil {
+				if seen[*l.Id] {
+					continue
+				}
+				seen[*l.Id] = true
+				if after == nil || *l.Id > *after {
+					id := *l.Id
+					after = &id
+				}
+			}
+			write(l)
+		}
+		if done {
+			return failed, nil
+		}
+		time.Sleep(interval)
+	}
+}
+
+func sortTaskLogs(logs []openapi.TaskLog) {
+	sort.SliceStable(logs, func(i, j int) bool {
+		return utils.Deref(logs[i].Id) < utils.Deref(logs[j].Id)
+	})
+}
+
+// taskLogFilter selects entries by time and lines by pattern. A nil filter
+// selects everything.
+type taskLogFilter struct {
+	since   *time.Time
+	pattern *regexp.Regexp
+}
+
+// newTaskLogFilter parses --since, a duration before now or an RFC 3339
+// time, and --grep.
+func newTaskLogFilter(since, grep string, now time.Time) (*taskLogFilter, error) {
+	f := &taskLogFilter{}
+	if since != "" {
+		if d, err := time.ParseDuration(since); err == nil {
+			t := now.Add(-d)
+			f.since = &t
+		} else if t, err := time.Parse(time.RFC3339, since); err == nil {
+			f.since = &t
+		} else {
+			return nil, fmt.Errorf("--since: %q is neither a duration nor an RFC 3339 time", since)
+		}
+	}
+	if grep != "" {
+		re, err := regexp.Compile(grep)
+		if err != nil {
+			return nil, fmt.Errorf("--grep: %w", err)
+		}
+		f.pattern = re
+	}
+	return f, nil
+}
+
+func (f *taskLogFilter) selects(l openapi.TaskLog) bool {
+	return f == nil || f.since == nil || (l.InsDatetime != nil && !l.InsDatetime.Before(*f.since))
+}
+
+func (f *taskLogFilter) matchLine(line string) bool {
+	return f == nil || f.pattern == nil || f.pattern.MatchString(line)
+}
+
+// match reports whether any line of an entry is selected.
+func (f *taskLogFilter) match(l openapi.TaskLog) bool {
+	if !f.selects(l) {
+		return false
+	}
+	for _, line := range taskLogLines(l) {
+		if f.matchLine(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func taskLogLines(l openapi.TaskLog) []string {
+	return strings.Split(strings.TrimRight(utils.Deref(l.Contents), "\n"), "\n")
+}
+
+// writeTaskLog writes the selected lines of an entry, each prefixed with the
+// time and the task so multi-line entries stay greppable.
+func writeTaskLog(out io.Writer, l openapi.TaskLog, f *taskLogFilter) {
+	if !f.selects(l) {
+		return
+	}
+	prefix := fmt.Sprintf("%s [task %d]", utils.FormatTime(l.InsDatetime), l.Task)
+	for _, line := range taskLogLines(l) {
+		if f.matchLine(line) {
+			fmt.Fprintf(out, "%s %s\n", prefix, line)
+		}
+	}
+}
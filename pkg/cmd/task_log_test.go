@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"recotem.org/cli/recotem/pkg/openapi"
+)
+
+func taskLog(id int, at time.Time, contents string) openapi.TaskLog {
+	return openapi.TaskLog{Id: &id, Task: 5, InsDatetime: &at, Contents: &contents}
+}
+
+func TestFollowTaskLogsDeduplicatesUntilDone(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// The server ignores id_gt here and returns overlapping pages.
+	pages := [][]openapi.TaskLog{
+		{taskLog(2, at, "b"), taskLog(1, at, "a")},
+		{taskLog(2, at, "b"), taskLog(3, at, "c")},
+		{taskLog(3, at, "c"), taskLog(4, at, "d")},
+	}
+	var afters []int
+	fetch := func(after *int) ([]openapi.TaskLog, error) {
+		if after != nil {
+			afters = append(afters, *after)
+		}
+		page := pages[0]
+		pages = pages[1:]
+		return page, nil
+	}
+	polls := 0
+	state := func() (bool, bool, error) {
+		polls++
+		return polls == 3, polls == 3, nil
+	}
+	var got []int
+	failed, err := followTaskLogs(fetch, state, time.Nanosecond, func(l openapi.TaskLog) {
+		got = append(got, *l.Id)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !failed {
+		t.Error("expected the failure to be reported")
+	}
+	if len(got) != 4 || got[0] != 1 || got[3] != 4 {
+		t.Errorf("expected each entry once in order, got %v", got)
+	}
+	if len(afters) != 2 || afters[0] != 2 || afters[1] != 3 {
+		t.Errorf("expected polls after the newest entry, got %v", afters)
+	}
+}
+
+func TestFollowTaskLogsWithoutStateFetchesOnce(t *testing.T) {
+	calls := 0
+	fetch := func(after *int) ([]openapi.TaskLog, error) {
+		calls++
+		return nil, nil
+	}
+	if _, err := followTaskLogs(fetch, nil, 0, func(openapi.TaskLog) {}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected one fetch, got %d", calls)
+	}
+}
+
+func TestTaskLogFilter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	f, err := newTaskLogFilter("10m", "(?i)error", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	writeTaskLog(&buf, taskLog(1, now.Add(-time.Hour), "ERROR old"), f)
+	writeTaskLog(&buf, taskLog(2, now.Add(-time.Minute), "loading\nError: out of memory\n"), f)
+	want := "2024-01-01T11:59:00Z [task 5] Error: out of memory\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+	if f.match(taskLog(3, now, "fine")) {
+		t.Error("expected an entry without a matching line not to match")
+	}
+
+	f, err = newTaskLogFilter("2024-01-01T11:00:00Z", "", now)
+	if err != nil || !f.since.Equal(now.Add(-time.Hour)) {
+		t.Errorf("expected an RFC 3339 time, got %v, %v", f, err)
+	}
+	for _, bad := range [][2]string{{"yesterday", ""}, {"", "("}} {
+		if _, err := newTaskLogFilter(bad[0], bad[1], now); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestTaskLogOutputJSON(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	f, err := newTaskLogFilter("", "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs := []openapi.TaskLog{taskLog(1, now, "a"), taskLog(2, now, "b")}
+	tail := func(follow bool) string {
+		return captureStdout(t, func() {
+			o := newTaskLogOutput("json", f, follow)
+			for _, l := range logs {
+				o.write(l)
+			}
+			o.flush()
+		})
+	}
+
+	var list []openapi.TaskLog
+	if err := json.Unmarshal([]byte(tail(false)), &list); err != nil || len(list) != 2 {
+		t.Errorf("expected one list of 2 entries, got %v, %v", list, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(tail(true)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per entry when following, got %q", lines)
+	}
+	for _, line := range lines {
+		var l openapi.TaskLog
+		if err := json.Unmarshal([]byte(line), &l); err != nil {
+			t.Errorf("invalid line %q: %v", line, err)
+		}
+	}
+}
+
+func TestTrainedModelState(t *testing.T) {
+	status := func(s openapi.StatusEnum) *openapi.TrainedModel {
+		return &openapi.TrainedModel{TaskLinks: &[]openapi.TaskAndTrainedModelLink{{Task: openapi.TaskResult{Status: &s}}}}
+	}
+	cases := []struct {
+		model        *openapi.TrainedModel
+		done, failed bool
+	}{
+		{&openapi.TrainedModel{}, true, false},
+		{status(openapi.STARTED), false, false},
+		{status(openapi.SUCCESS), true, false},
+		{status(openapi.REVOKED), true, true},
+	}
+	for i, c := range cases {
+		done, failed := trainedModelState(c.model)
+		if done != c.done || failed != c.failed {
+			t.Errorf("case %d: expected %v/%v, got %v/%v", i, c.done, c.failed, done, failed)
+		}
+	}
+}
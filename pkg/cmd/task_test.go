@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"recotem.org/cli/recotem/pkg/openapi"
+)
+
+func TestParseTaskOwner(t *testing.T) {
+	o, err := parseTaskOwner("tm:3")
+	if err != nil || o.kind != "trained-model" || o.id != 3 {
+		t.Errorf("unexpected owner %v, %v", o, err)
+	}
+	o, err = parseTaskOwner("parameter-tuning-job:4")
+	if err != nil || o.kind != "parameter-tuning-job" || o.id != 4 {
+		t.Errorf("unexpected owner %v, %v", o, err)
+	}
+	for _, bad := range []string{"3", "tm:x", "dataset:1"} {
+		if _, err := parseTaskOwner(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestSummarizeTasks(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	done := created.Add(90 * time.Second)
+	now := created.Add(time.Hour)
+	failure, started := openapi.FAILURE, openapi.STARTED
+	tb := "Traceback (most recent call last):\n  File \"fit.py\", line 3, in fit\nValueError: empty matrix\n"
+
+	s := summarizeTasks([]openapi.TaskResult{
+		{TaskId: "aaa", Status: &failure, DateCreated: &created, DateDone: &done, Traceback: &tb},
+		{TaskId: "bbb", Status: &started, DateCreated: &created},
+	}, now)
+
+	if !s[0].Failed || *s[0].DurationSeconds != 90 || formatTaskDuration(s[0]) != "1m30s" {
+		t.Errorf("unexpected summary: %+v", s[0])
+	}
+	if s[1].Finished || formatTaskDuration(s[1]) != "1h0m0s so far" {
+		t.Errorf("expected a running task timed up to now, got %+v", s[1])
+	}
+
+	var buf bytes.Buffer
+	writeTaskHistory(&buf, s)
+	if !strings.Contains(buf.String(), "ValueError: empty matrix") {
+		t.Errorf("expected the exception in the history:\n%s", buf.String())
+	}
+
+	o := taskOwner{kind: "trained-model", id: 3}
+	if got, err := selectTask(s, o, ""); err != nil || got.TaskID != "bbb" {
+		t.Errorf("expected the latest task, got %v, %v", got, err)
+	}
+	if got, err := selectTask(s, o, "aa"); err != nil || got.TaskID != "aaa" {
+		t.Errorf("expected the task by prefix, got %v, %v", got, err)
+	}
+	if _, err := selectTask(s, o, "c"); err == nil {
+		t.Error("expected an error for an unknown task")
+	}
+	if _, err := selectTask(nil, o, ""); err == nil || !strings.Contains(err.Error(), "trained-model 3") {
+		t.Errorf("expected a no tasks error, got %v", err)
+	}
+}
+
+func TestFormatTraceback(t *testing.T) {
+	tb := "Traceback (most recent call last):\n\tFile \"fit.py\", line 3\nValueError: empty\n"
+	if got := formatTraceback(tb, false); got != "  Traceback (most recent call last):\n      File \"fit.py\", line 3\n  ValueError: empty\n" {
+		t.Errorf("unexpected plain traceback %q", got)
+	}
+	colored := formatTraceback(tb, true)
+	if !strings.Contains(colored, "\033[1;31mValueError: empty\033[0m") || !strings.Contains(colored, "\033[2m") {
+		t.Errorf("expected the exception highlighted and frames dimmed, got %q", colored)
+	}
+}
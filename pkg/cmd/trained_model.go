@@ -6,6 +6,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/pseudonym"
 	"recotem.org/cli/recotem/pkg/utils"
 )
 
@@ -22,6 +23,7 @@ func newTrainedModelCmd() *cobra.Command {
 		newTrainedModelDeleteCmd(),
 		newTrainedModelDownloadCmd(),
 		newTrainedModelRecommendCmd(),
+		newTrainedModelRecommendBatchCmd(),
 		newTrainedModelSampleRecommendCmd(),
 		newTrainedModelRecommendProfileCmd(),
 	)
@@ -186,6 +188,7 @@ func newTrainedModelDownloadCmd() *cobra.Command {
 func newTrainedModelRecommendCmd() *cobra.Command {
 	var id, userID string
 	var nItems int
+	var rawIDs bool
 
 	cmd := &cobra.Command{
 		Use:   "recommend",
@@ -199,10 +202,19 @@ func newTrainedModelRecommendCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			result, err := client.Recommend(idInt, userID, nItems)
+			mapping, err := recommendPseudonyms(client, idInt, rawIDs)
 			if err != nil {
 				return err
 			}
+			users, err := pseudonymizeIDs(mapping, pseudonym.KindUser, []string{userID})
+			if err != nil {
+				return err
+			}
+			result, err := client.Recommend(idInt, users[0], nItems)
+			if err != nil {
+				return err
+			}
+			revealRecommendation(mapping, result)
 			utils.PrintOutput(getOutputFormat(), result)
 			return nil
 		},
@@ -211,6 +223,7 @@ func newTrainedModelRecommendCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&id, "id", "i", "", "Trained model ID")
 	cmd.Flags().StringVar(&userID, "user-id", "", "User ID")
 	cmd.Flags().IntVarP(&nItems, "n-items", "n", 10, "Number of items to recommend")
+	addRawIDsFlag(cmd, &rawIDs)
 	_ = cmd.MarkFlagRequired("id")
 	_ = cmd.MarkFlagRequired("user-id")
 
@@ -219,6 +232,7 @@ func newTrainedModelRecommendCmd() *cobra.Command {
 
 func newTrainedModelSampleRecommendCmd() *cobra.Command {
 	var id string
+	var rawIDs bool
 
 	cmd := &cobra.Command{
 		Use:   "sample-recommend",
@@ -232,16 +246,22 @@ func newTrainedModelSampleRecommendCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			mapping, err := recommendPseudonyms(client, idInt, rawIDs)
+			if err != nil {
+				return err
+			}
 			result, err := client.SampleRecommend(idInt)
 			if err != nil {
 				return err
 			}
+			revealRecommendation(mapping, result)
 			utils.PrintOutput(getOutputFormat(), result)
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVarP(&id, "id", "i", "", "Trained model ID")
+	addRawIDsFlag(cmd, &rawIDs)
 	_ = cmd.MarkFlagRequired("id")
 
 	return cmd
@@ -251,6 +271,7 @@ func newTrainedModelRecommendProfileCmd() *cobra.Command {
 	var id string
 	var itemIDs []string
 	var nItems int
+	var rawIDs bool
 
 	cmd := &cobra.Command{
 		Use:   "recommend-profile",
@@ -264,10 +285,19 @@ func newTrainedModelRecommendProfileCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			result, err := client.RecommendProfile(idInt, itemIDs, nItems)
+			mapping, err := recommendPseudonyms(client, idInt, rawIDs)
+			if err != nil {
+				return err
+			}
+			items, err := pseudonymizeIDs(mapping, pseudonym.KindItem, itemIDs)
+			if err != nil {
+				return err
+			}
+			result, err := client.RecommendProfile(idInt, items, nItems)
 			if err != nil {
 				return err
 			}
+			revealRecommendation(mapping, result)
 			utils.PrintOutput(getOutputFormat(), result)
 			return nil
 		},
@@ -276,6 +306,7 @@ func newTrainedModelRecommendProfileCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&id, "id", "i", "", "Trained model ID")
 	cmd.Flags().StringSliceVar(&itemIDs, "item-ids", nil, "Item IDs (comma-separated)")
 	cmd.Flags().IntVarP(&nItems, "n-items", "n", 10, "Number of items to recommend")
+	addRawIDsFlag(cmd, &rawIDs)
 	_ = cmd.MarkFlagRequired("id")
 	_ = cmd.MarkFlagRequired("item-ids")
 
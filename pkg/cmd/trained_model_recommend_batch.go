@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"recotem.org/cli/recotem/pkg/batch"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/pseudonym"
+)
+
+func newTrainedModelRecommendBatchCmd() *cobra.Command {
+	var id, users, output, format string
+	var nItems, concurrency, retries int
+	var rate float64
+	var resume, rawIDs bool
+
+	cmd := &cobra.Command{
+		Use:   "recommend-batch",
+		Short: "Get recommendations for many users from a file",
+		Long: "Request recommendations for every user ID in a file (one per line, or - for\n" +
+			"stdin) with a bounded number of concurrent requests and an optional rate\n" +
+			"limit. Failed requests are retried with exponential backoff; client errors\n" +
+			"other than 408 and 429 are not retried.\n\n" +
+			"The output has one row per recommended item with the columns user, rank, item\n" +
+			"and score, in CSV, NDJSON or Parquet. The rows of each user are written\n" +
+			"together as soon as they arrive, so after an interruption --resume continues\n" +
+			"the same output and only requests the users that are missing. Parquet output\n" +
+			"collects in <output>.partial until the run completes.",
+		Example: "  recotem trained-model recommend-batch --id 3 --users users.txt -O recs.csv\n" +
+			"  cut -d, -f1 users.csv | recotem trained-model recommend-batch --id 3 --users - \\\n" +
+			"    -O recs.parquet --concurrency 8 --rate 50 --resume",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			idInt, err := strconv.Atoi(id)
+			if err != nil {
+				return err
+			}
+			f := batch.FormatFromPath(output)
+			if format != "" {
+				if f, err = batch.ParseFormat(format); err != nil {
+					return err
+				}
+			}
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be at least 1")
+			}
+			userIDs, err := readBatchUsers(users, cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			mapping, err := recommendPseudonyms(client, idInt, rawIDs)
+			if err != nil {
+				return err
+			}
+
+			var out *batch.Output
+			done := map[string]bool{}
+			if resume {
+				out, done, err = batch.Resume(output, f)
+			} else {
+				out, err = batch.Create(output, f, cmd.OutOrStdout())
+			}
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			stderr := cmd.ErrOrStderr()
+			opts := batch.Options{
+				Workers:   concurrency,
+				Rate:      rate,
+				Retries:   retries,
+				Backoff:   500 * time.Millisecond,
+				Retryable: retryableRecommendError,
+			}
+			live := output != "" && output != "-" && term.IsTerminal(int(os.Stderr.Fd()))
+			if live {
+				opts.Progress = func(s batch.Stats) {
+					fmt.Fprintf(stderr, "\r\033[K%d/%d users", s.Skipped+s.Succeeded+s.Failed, s.Total)
+				}
+			}
+			fetch := func(_ context.Context, user string) ([]batch.Item, error) {
+				return recommendForBatch(client, mapping, idInt, user, nItems)
+			}
+			stats, failures, runErr := batch.Run(ctx, userIDs, done, out, fetch, opts)
+			if live {
+				fmt.Fprintln(stderr)
+			}
+			if err := out.Close(runErr == nil); err != nil {
+				return err
+			}
+			return reportRecommendBatch(stderr, output, stats, failures, runErr)
+		},
+	}
+
+	cmd.Flags().StringVarP(&id, "id", "i", "", "Trained model ID")
+	cmd.Flags().StringVar(&users, "users", "", "File of user IDs, one per line (- for stdin)")
+	cmd.Flags().StringVarP(&output, "output", "O", "", "Output file (default: stdout)")
+	cmd.Flags().StringVar(&format, "format", "", "Output format: csv, ndjson, parquet (default: from the output file name, else csv)")
+	cmd.Flags().IntVarP(&nItems, "n-items", "n", 10, "Number of items to recommend per user")
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "c", 4, "Number of concurrent requests")
+	cmd.Flags().Float64Var(&rate, "rate", 0, "Maximum requests per second (0 for no limit)")
+	cmd.Flags().IntVar(&retries, "retries", 3, "Retries per user after a failed request")
+	cmd.Flags().BoolVar(&resume, "resume", false, "Continue an interrupted run, skipping users already in the output")
+	addRawIDsFlag(cmd, &rawIDs)
+	_ = cmd.MarkFlagRequired("id")
+	_ = cmd.MarkFlagRequired("users")
+
+	return cmd
+}
+
+func readBatchUsers(path string, stdin io.Reader) ([]string, error) {
+	r := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	users, err := batch.ReadUsers(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("no user IDs in %s", path)
+	}
+	return users, nil
+}
+
+// permanentError is a failure that retrying cannot fix.
+type permanentError struct{ error }
+
+func (e permanentError) Unwrap() error { return e.error }
+
+// recommendClient is the part of api.Client recommend-batch uses.
+type recommendClient interface {
+	Recommend(id int, userID string, nItems int) (*openapi.RawRecommendation, error)
+}
+
+func recommendForBatch(client recommendClient, mapping *pseudonym.Mapping, modelID int, user string, nItems int) ([]batch.Item, error) {
+	ids, err := pseudonymizeIDs(mapping, pseudonym.KindUser, []string{user})
+	if err != nil {
+		return nil, permanentError{err}
+	}
+	result, err := client.Recommend(modelID, ids[0], nItems)
+	if err != nil {
+		return nil, err
+	}
+	revealRecommendation(mapping, result)
+	items := make([]batch.Item, len(result.Recommendations))
+	for i, r := range result.Recommendations {
+		items[i] = batch.Item{ID: r.ItemId, Score: r.Score}
+	}
+	return items, nil
+}
+
+// retryableRecommendError retries network errors and server errors. API
+// errors start with the HTTP status, and client errors other than timeouts
+// and rate limiting will not succeed on a retry.
+func retryableRecommendError(err error) bool {
+	var p permanentError
+	if errors.As(err, &p) {
+		return false
+	}
+	msg := err.Error()
+	if len(msg) >= 4 && msg[0] == '4' && msg[3] == ' ' {
+		code, convErr := strconv.Atoi(msg[:3])
+		return convErr != nil || code == 408 || code == 429
+	}
+	return true
+}
+
+func reportRecommendBatch(stderr io.Writer, output string, stats batch.Stats, failures []batch.Failure, runErr error) error {
+	const shown = 10
+	for i, f := range failures {
+		if i == shown {
+			fmt.Fprintf(stderr, "... and %d more\n", len(failures)-shown)
+			break
+		}
+		fmt.Fprintf(stderr, "user %s: %v\n", f.User, f.Err)
+	}
+	target := output
+	if target == "" || target == "-" {
+		target = "stdout"
+	}
+	summary := fmt.Sprintf("Wrote recommendations for %d users to %s", stats.Succeeded, target)
+	var notes []string
+	if stats.Skipped > 0 {
+		notes = append(notes, fmt.Sprintf("%d already done", stats.Skipped))
+	}
+	if stats.Failed > 0 {
+		notes = append(notes, fmt.Sprintf("%d failed", stats.Failed))
+	}
+	if len(notes) > 0 {
+		summary += " (" + strings.Join(notes, ", ") + ")"
+	}
+	fmt.Fprintln(stderr, summary)
+
+	canResume := output != "" && output != "-"
+	switch {
+	case runErr != nil && canResume:
+		return fmt.Errorf("stopped after %d of %d users: %w; rerun with --resume to continue",
+			stats.Skipped+stats.Succeeded+stats.Failed, stats.Total, runErr)
+	case runErr != nil:
+		return runErr
+	case stats.Failed > 0 && canResume:
+		return fmt.Errorf("%d users failed; rerun with --resume to retry them", stats.Failed)
+	case stats.Failed > 0:
+		return fmt.Errorf("%d users failed", stats.Failed)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"recotem.org/cli/recotem/pkg/batch"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/pseudonym"
+)
+
+type fakeRecommendClient struct{ users []string }
+
+func (c *fakeRecommendClient) Recommend(id int, userID string, nItems int) (*openapi.RawRecommendation, error) {
+	c.users = append(c.users, userID)
+	return &openapi.RawRecommendation{UserId: userID, Recommendations: []openapi.IDAndScore{
+		{ItemId: "i1", Score: 0.9}, {ItemId: "i2", Score: 0.5},
+	}}, nil
+}
+
+func TestRecommendForBatch(t *testing.T) {
+	client := &fakeRecommendClient{}
+	items, err := recommendForBatch(client, nil, 3, "u1", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 2 || items[0].ID != "i1" || items[1].Score != 0.5 {
+		t.Errorf("unexpected items %+v", items)
+	}
+
+	// A user missing from the pseudonym mapping cannot succeed on a retry.
+	_, err = recommendForBatch(client, &pseudonym.Mapping{}, 3, "unknown", 2)
+	if err == nil || retryableRecommendError(err) {
+		t.Errorf("expected a permanent error, got %v", err)
+	}
+	if len(client.users) != 1 {
+		t.Errorf("expected no request for the unknown user, got %v", client.users)
+	}
+}
+
+func TestRetryableRecommendError(t *testing.T) {
+	cases := map[string]bool{
+		"404 Not Found: {}":            false,
+		"400 Bad Request: {}":          false,
+		"429 Too Many Requests: {}":    true,
+		"408 Request Timeout: {}":      true,
+		"502 Bad Gateway: {}":          true,
+		"dial tcp: connection refused": true,
+	}
+	for msg, want := range cases {
+		if got := retryableRecommendError(errors.New(msg)); got != want {
+			t.Errorf("%q: expected %v, got %v", msg, want, got)
+		}
+	}
+}
+
+func TestReportRecommendBatch(t *testing.T) {
+	var buf bytes.Buffer
+	stats := batch.Stats{Total: 5, Skipped: 2, Succeeded: 2, Failed: 1}
+	failures := []batch.Failure{{User: "u9", Err: errors.New("404 Not Found")}}
+	err := reportRecommendBatch(&buf, "recs.csv", stats, failures, nil)
+	if err == nil || !strings.Contains(err.Error(), "--resume") {
+		t.Errorf("expected a failure error suggesting --resume, got %v", err)
+	}
+	want := "user u9: 404 Not Found\nWrote recommendations for 2 users to recs.csv (2 already done, 1 failed)\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := reportRecommendBatch(&buf, "", batch.Stats{Total: 1, Succeeded: 1}, nil, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "to stdout") {
+		t.Errorf("unexpected summary %q", buf.String())
+	}
+}
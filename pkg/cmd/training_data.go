@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/ledger"
 	"recotem.org/cli/recotem/pkg/openapi"
 	"recotem.org/cli/recotem/pkg/utils"
 )
@@ -22,6 +23,11 @@ func newTrainingDataCmd() *cobra.Command {
 		newTrainingDataDeleteCmd(),
 		newTrainingDataDownloadCmd(),
 		newTrainingDataPreviewCmd(),
+		newTrainingDataStatsCmd(),
+		newTrainingDataPrepareCmd(),
+		newTrainingDataAppendCmd(),
+		newTrainingDataDiffCmd(),
+		newTrainingDataVersionsCmd(),
 	)
 
 	return cmd
@@ -62,7 +68,8 @@ func newTrainingDataListCmd() *cobra.Command {
 }
 
 func newTrainingDataUploadCmd() *cobra.Command {
-	var project, file string
+	var project string
+	var source uploadSource
 
 	cmd := &cobra.Command{
 		Use:   "upload",
@@ -76,7 +83,28 @@ func newTrainingDataUploadCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			trainingData, err := client.UploadTrainingData(id, file)
+			book, err := loadLedger(client)
+			if err != nil {
+				return err
+			}
+			sha, err := ledger.HashFile(source.file)
+			if err != nil {
+				return err
+			}
+			dup, existing, err := findDuplicate(book, ledger.KindTrainingData, id, sha, source.options(),
+				func(x int) (*openapi.TrainingData, error) { return findTrainingData(client, x) },
+				func(x *openapi.TrainingData) (*string, *int) { return x.Basename, x.Filesize })
+			if err != nil {
+				return err
+			}
+			if skip, err := checkDuplicate(source.onDuplicate, dup, source.file); err != nil {
+				return err
+			} else if skip {
+				printTrainingData(getOutputFormat(), *existing)
+				return nil
+			}
+
+			trainingData, err := source.uploadTrainingData(client, book, id, sha)
 			if err != nil {
 				return err
 			}
@@ -86,7 +114,7 @@ func newTrainingDataUploadCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&project, "project", "p", "", "Project ID")
-	cmd.Flags().StringVarP(&file, "file", "f", "", "File path")
+	source.addFlags(cmd)
 	_ = cmd.MarkFlagRequired("project")
 	_ = cmd.MarkFlagRequired("file")
 
@@ -155,36 +183,6 @@ func newTrainingDataDownloadCmd() *cobra.Command {
 	return cmd
 }
 
-func newTrainingDataPreviewCmd() *cobra.Command {
-	var id string
-
-	cmd := &cobra.Command{
-		Use:   "preview",
-		Short: "Preview training data",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := newClientFromCmd(cmd)
-			if err != nil {
-				return err
-			}
-			idInt, err := strconv.Atoi(id)
-			if err != nil {
-				return err
-			}
-			preview, err := client.PreviewTrainingData(idInt)
-			if err != nil {
-				return err
-			}
-			fmt.Println(string(preview))
-			return nil
-		},
-	}
-
-	cmd.Flags().StringVarP(&id, "id", "i", "", "Training data ID")
-	_ = cmd.MarkFlagRequired("id")
-
-	return cmd
-}
-
 func printTrainingData(format string, x openapi.TrainingData) {
 	if format == "json" || format == "yaml" {
 		m := map[string]any{
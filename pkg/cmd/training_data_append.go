@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/dataset"
+	"recotem.org/cli/recotem/pkg/ledger"
+	"recotem.org/cli/recotem/pkg/pseudonym"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+func newTrainingDataAppendCmd() *cobra.Command {
+	var schemaOpts schemaFlags
+	var source uploadSource
+	var base, retention string
+
+	cmd := &cobra.Command{
+		Use:   "append",
+		Short: "Append new interactions to training data as a new version",
+		Long: "Download the base training data, append the rows of a local file, drop\n" +
+			"repeated user/item(/time) interactions and, with --retention, rows older\n" +
+			"than the window before the newest interaction, then upload the result as\n" +
+			"new training data. Both files are streamed, not loaded into memory.\n\n" +
+			"If the base was uploaded with --pseudonymize, pass the same mode so the new\n" +
+			"IDs go through the project's pseudonym mapping; appending raw IDs to\n" +
+			"pseudonymized data, or the reverse, is refused.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			projectID, err := strconv.Atoi(schemaOpts.project)
+			if err != nil {
+				return err
+			}
+			baseID, err := strconv.Atoi(base)
+			if err != nil {
+				return err
+			}
+			window, err := dataset.ParseWindow(retention)
+			if err != nil {
+				return err
+			}
+			schema, err := schemaOpts.resolve(func() (api.Client, error) { return client, nil }, nil)
+			if err != nil {
+				return err
+			}
+
+			basePath, td, remove, err := downloadTrainingData(client, baseID)
+			if err != nil {
+				return err
+			}
+			defer remove()
+			if td.Project != projectID {
+				return fmt.Errorf("training data %d belongs to project %d, not %d", baseID, td.Project, projectID)
+			}
+			book, err := loadLedger(client)
+			if err != nil {
+				return err
+			}
+			if err := checkAppendPseudonyms(book, baseID, source.pseudonymize); err != nil {
+				return err
+			}
+			store, targets, err := source.pseudonyms(client, projectID, pseudonym.KindUser, pseudonym.KindItem)
+			if err != nil {
+				return err
+			}
+
+			inputs := []func() (dataset.Reader, error){
+				func() (dataset.Reader, error) { return dataset.Open(basePath, "") },
+				func() (dataset.Reader, error) {
+					r, err := source.reader()
+					if err != nil || store == nil {
+						return r, err
+					}
+					wrapped, err := pseudonym.Wrap(r, store.Mapping, targets)
+					if err != nil {
+						r.Close()
+						return nil, err
+					}
+					return wrapped, nil
+				},
+			}
+			var report *dataset.MergeReport
+			pr, pw := io.Pipe()
+			go func() {
+				var err error
+				report, err = dataset.Merge(inputs, dataset.MergeOptions{Schema: schema, Retention: window}, pw)
+				pw.CloseWithError(err)
+			}()
+			defer pr.Close()
+
+			hashed := ledger.NewHashingReader(pr)
+			name := dataset.CSVName(basePath)
+			trainingData, err := client.UploadTrainingDataFrom(projectID, name, hashed)
+			if err != nil {
+				return err
+			}
+			if store != nil {
+				if err := store.Save(); err != nil {
+					return fmt.Errorf("uploaded, but saving the pseudonym mapping failed: %w", err)
+				}
+			}
+			entry := newLedgerEntry(ledger.KindTrainingData, projectID, trainingData.Id, trainingData.Basename, trainingData.Filesize)
+			entry.SHA256 = hashed.Sum()
+			entry.Parent = &baseID
+			entry.Source = source.source()
+			entry.Options = strings.TrimSpace("append " + source.options())
+			if retention != "" {
+				entry.Options += " retention=" + retention
+			}
+			recordUpload(book, entry)
+			printMergeReport(os.Stderr, report)
+			printTrainingData(getOutputFormat(), *trainingData)
+			return nil
+		},
+	}
+
+	schemaOpts.addFlags(cmd)
+	cmd.Flags().StringVar(&base, "base", "", "Training data ID to append to")
+	cmd.Flags().StringVarP(&source.file, "file", "f", "", "File with the new interactions")
+	source.addConversionFlags(cmd)
+	cmd.Flags().StringVar(&retention, "retention", "", "Drop interactions older than this window before the newest one (e.g. 180d)")
+	_ = cmd.MarkFlagRequired("project")
+	_ = cmd.MarkFlagRequired("base")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+// checkAppendPseudonyms refuses to mix raw IDs and pseudonyms in one version:
+// the new rows must be pseudonymized in the same mode as the base, as far as
+// the upload ledger knows how the base was made.
+func checkAppendPseudonyms(book *ledger.Ledger, baseID int, mode string) error {
+	var base *ledger.Entry
+	for i, e := range book.Entries {
+		if e.Kind == ledger.KindTrainingData && e.ID == baseID {
+			base = &book.Entries[i]
+		}
+	}
+	if base == nil {
+		return nil
+	}
+	baseMode := ledgerOption(base.Options, "pseudonymize")
+	switch {
+	case baseMode == mode:
+		return nil
+	case mode == "":
+		return fmt.Errorf("training data %d was uploaded with --pseudonymize %s; pass --pseudonymize %s so the new IDs use the same mapping",
+			baseID, baseMode, baseMode)
+	case baseMode == "":
+		return fmt.Errorf("training data %d holds raw IDs; appending with --pseudonymize would mix them with pseudonyms", baseID)
+	}
+	return fmt.Errorf("training data %d was uploaded with --pseudonymize %s, not %s", baseID, baseMode, mode)
+}
+
+func printMergeReport(w io.Writer, r *dataset.MergeReport) {
+	if r == nil {
+		return
+	}
+	fmt.Fprintf(w, "rows: base %d + new %d -> %d\n", r.InputRows[0], r.InputRows[1], r.OutputRows)
+	if r.Since != nil {
+		fmt.Fprintf(w, "retention: since %s\n", utils.FormatTime(r.Since))
+	}
+	for _, reason := range []string{"missing_id", "invalid_time", "retention", "duplicate"} {
+		if n := r.Dropped[reason]; n > 0 {
+			fmt.Fprintf(w, "dropped (%s): %d\n", reason, n)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/dataset"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+func newTrainingDataDiffCmd() *cobra.Command {
+	var schemaOpts schemaFlags
+	var top int
+
+	cmd := &cobra.Command{
+		Use:   "diff <idA> <idB>",
+		Short: "Compare two training data versions",
+		Long: "Download two training data files and report added and removed users and\n" +
+			"items, the change in interactions and time range, and the users and items\n" +
+			"whose interaction counts changed most. Use -o json for a machine-readable report.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if top < 0 {
+				return fmt.Errorf("--top must not be negative")
+			}
+			ids := make([]int, len(args))
+			for i, arg := range args {
+				id, err := strconv.Atoi(arg)
+				if err != nil {
+					return fmt.Errorf("invalid training data ID %q", arg)
+				}
+				ids[i] = id
+			}
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+
+			readers := make([]dataset.Reader, len(ids))
+			var projectID *int
+			for i, id := range ids {
+				path, td, remove, err := downloadTrainingData(client, id)
+				if err != nil {
+					return err
+				}
+				defer remove()
+				r, err := dataset.Open(path, "")
+				if err != nil {
+					return err
+				}
+				defer r.Close()
+				readers[i] = r
+				if projectID == nil {
+					projectID = &td.Project
+				}
+			}
+
+			schema, err := schemaOpts.resolve(lazyClient(cmd), projectID)
+			if err != nil {
+				return err
+			}
+			report, err := dataset.Diff(readers[0], readers[1], schema, top)
+			if err != nil {
+				return err
+			}
+			printDatasetDiff(getOutputFormat(), ids, report)
+			return nil
+		},
+	}
+
+	schemaOpts.addFlags(cmd)
+	cmd.Flags().IntVar(&top, "top", 10, "Number of changed users/items and sample IDs to show")
+
+	return cmd
+}
+
+func printDatasetDiff(format string, ids []int, d *dataset.DiffReport) {
+	if format == "json" || format == "yaml" {
+		utils.PrintOutput(format, d)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\t%d\t%d\tdelta\n", ids[0], ids[1])
+	fmt.Fprintf(w, "Interactions:\t%d\t%d\t%+d\n", d.A.Interactions, d.B.Interactions, d.InteractionDelta)
+	fmt.Fprintf(w, "Users:\t%d\t%d\t%+d\n", d.A.Users, d.B.Users, d.B.Users-d.A.Users)
+	fmt.Fprintf(w, "Items:\t%d\t%d\t%+d\n", d.A.Items, d.B.Items, d.B.Items-d.A.Items)
+	if d.A.Start != nil || d.B.Start != nil {
+		fmt.Fprintf(w, "Start:\t%s\t%s\t\n", utils.FormatTime(d.A.Start), utils.FormatTime(d.B.Start))
+		fmt.Fprintf(w, "End:\t%s\t%s\t\n", utils.FormatTime(d.A.End), utils.FormatTime(d.B.End))
+	}
+	w.Flush()
+
+	printSetDiff("Users", d.Users)
+	printSetDiff("Items", d.Items)
+	printCountDeltas("Top changed users", d.TopUsers)
+	printCountDeltas("Top changed items", d.TopItems)
+}
+
+func printSetDiff(title string, s dataset.SetDiff) {
+	fmt.Println()
+	fmt.Printf("%s: %d added, %d removed, %d common\n", title, s.Added, s.Removed, s.Common)
+	if len(s.AddedSample) > 0 {
+		fmt.Printf("  added: %s\n", sampleList(s.AddedSample, s.Added))
+	}
+	if len(s.RemovedSample) > 0 {
+		fmt.Printf("  removed: %s\n", sampleList(s.RemovedSample, s.Removed))
+	}
+}
+
+func sampleList(ids []string, total int) string {
+	list := strings.Join(ids, ", ")
+	if total > len(ids) {
+		list += fmt.Sprintf(", ... (%d more)", total-len(ids))
+	}
+	return list
+}
+
+func printCountDeltas(title string, deltas []dataset.CountDelta) {
+	if len(deltas) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println(title)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, c := range deltas {
+		fmt.Fprintf(w, "  %s\t%d -> %d\t%+d\n", c.ID, c.Before, c.After, c.Delta)
+	}
+	w.Flush()
+}
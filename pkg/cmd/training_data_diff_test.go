@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrainingDataDiffRejectsNegativeTop(t *testing.T) {
+	cmd := newTrainingDataDiffCmd()
+	cmd.SetArgs([]string{"1", "2", "--top", "-1"})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--top") {
+		t.Fatalf("expected a --top error, got %v", err)
+	}
+}
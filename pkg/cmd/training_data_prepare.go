@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/dataset"
+	"recotem.org/cli/recotem/pkg/ledger"
+)
+
+func newTrainingDataPrepareCmd() *cobra.Command {
+	var source dataSource
+	var schemaOpts schemaFlags
+	var since, until, last, output string
+	var dedup, upload bool
+	var sampleUsers float64
+	var seed int64
+	var minUser, minItem int
+
+	cmd := &cobra.Command{
+		Use:   "prepare",
+		Short: "Filter training data locally before upload",
+		Long: "Apply time-window filtering, deduplication, user sampling and iterative\n" +
+			"k-core filtering to a local file or downloaded training data, then write\n" +
+			"the result to a file (- for stdout) or upload it as new training data.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := lazyClient(cmd)
+			open, td, cleanup, err := source.opener(client)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			var projectID *int
+			if td != nil {
+				projectID = &td.Project
+			}
+			schema, err := schemaOpts.resolve(client, projectID)
+			if err != nil {
+				return err
+			}
+			opts := dataset.PrepareOptions{
+				Schema:              schema,
+				Dedup:               dedup,
+				SampleUsers:         sampleUsers,
+				Seed:                seed,
+				MinUserInteractions: minUser,
+				MinItemInteractions: minItem,
+			}
+			if opts.Since, err = parseOptionalTime(since); err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+			if opts.Until, err = parseOptionalTime(until); err != nil {
+				return fmt.Errorf("invalid --until: %w", err)
+			}
+			if opts.Last, err = dataset.ParseWindow(last); err != nil {
+				return err
+			}
+
+			prepared, err := dataset.Prepare(open, opts)
+			if err != nil {
+				return err
+			}
+
+			if upload {
+				if schemaOpts.project != "" {
+					id, err := strconv.Atoi(schemaOpts.project)
+					if err != nil {
+						return err
+					}
+					projectID = &id
+				}
+				if projectID == nil {
+					return fmt.Errorf("--upload requires --project")
+				}
+				c, err := client()
+				if err != nil {
+					return err
+				}
+				name := dataset.CSVName(source.file)
+				if source.file == "" {
+					name = fmt.Sprintf("training-data-%s-prepared.csv", source.id)
+				}
+				body := streamPrepared(prepared)
+				defer body.Close()
+				hashed := ledger.NewHashingReader(body)
+				trainingData, err := c.UploadTrainingDataFrom(*projectID, name, hashed)
+				if err != nil {
+					return err
+				}
+				if book, err := loadLedger(c); err == nil {
+					entry := newLedgerEntry(ledger.KindTrainingData, *projectID, trainingData.Id, trainingData.Basename, trainingData.Filesize)
+					entry.SHA256 = hashed.Sum()
+					entry.Options = "prepare"
+					if td != nil {
+						entry.Parent = td.Id
+					} else {
+						entry.Source, _ = filepath.Abs(source.file)
+					}
+					recordUpload(book, entry)
+				}
+				printPrepareReport(os.Stderr, &prepared.Report)
+				printTrainingData(getOutputFormat(), *trainingData)
+				return nil
+			}
+
+			if output == "-" {
+				if err := prepared.Write(os.Stdout); err != nil {
+					return err
+				}
+				printPrepareReport(os.Stderr, &prepared.Report)
+				return nil
+			}
+			f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			if err != nil {
+				return err
+			}
+			if err := prepared.Write(f); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			printPrepareReport(os.Stderr, &prepared.Report)
+			fmt.Println(output)
+			return nil
+		},
+	}
+
+	source.addFlags(cmd)
+	schemaOpts.addFlags(cmd)
+	cmd.Flags().StringVar(&since, "since", "", "Keep interactions at or after this time")
+	cmd.Flags().StringVar(&until, "until", "", "Keep interactions before this time")
+	cmd.Flags().StringVar(&last, "last", "", "Keep interactions within this window of the newest one (e.g. 90d, 12w)")
+	cmd.Flags().BoolVar(&dedup, "dedup", false, "Drop repeated user/item pairs")
+	cmd.Flags().Float64Var(&sampleUsers, "sample-users", 0, "Fraction of users to keep (0-1)")
+	cmd.Flags().Int64Var(&seed, "seed", 0, "Random seed for user sampling")
+	cmd.Flags().IntVar(&minUser, "min-user-interactions", 0, "k-core threshold for users")
+	cmd.Flags().IntVar(&minItem, "min-item-interactions", 0, "k-core threshold for items")
+	cmd.Flags().StringVarP(&output, "output", "O", "", "Output filename (- for stdout)")
+	cmd.Flags().BoolVar(&upload, "upload", false, "Upload the result as new training data")
+	cmd.MarkFlagsMutuallyExclusive("output", "upload")
+	cmd.MarkFlagsOneRequired("output", "upload")
+
+	return cmd
+}
+
+// streamPrepared writes the prepared rows into a pipe for upload.
+func streamPrepared(p *dataset.Prepared) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(p.Write(pw))
+	}()
+	return pr
+}
+
+func parseOptionalTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return dataset.ParseTime(s)
+}
+
+func printPrepareReport(w io.Writer, r *dataset.PrepareReport) {
+	fmt.Fprintf(w, "rows: %d -> %d, users: %d, items: %d, k-core iterations: %d\n",
+		r.InputRows, r.OutputRows, r.Users, r.Items, r.Iterations)
+	for _, reason := range []string{"missing_id", "invalid_time", "time_window", "duplicate", "sampling", "kcore"} {
+		if n := r.Dropped[reason]; n > 0 {
+			fmt.Fprintf(w, "dropped (%s): %d\n", reason, n)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+// previewSampleValues is the number of distinct values shown per column in
+// the preview summary.
+const previewSampleValues = 3
+
+func newTrainingDataPreviewCmd() *cobra.Command {
+	var id string
+	var rows int
+	var columns []string
+	var summary bool
+
+	cmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Preview training data",
+		Long: "Show the first rows of training data as a table (-o text), CSV (-o csv),\n" +
+			"JSON or YAML. --summary prints distinct and null counts with sample\n" +
+			"values for each column instead of the rows.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			idInt, err := strconv.Atoi(id)
+			if err != nil {
+				return err
+			}
+			var nRows *int
+			if rows > 0 {
+				nRows = &rows
+			}
+			preview, err := client.PreviewTrainingData(idInt, nRows)
+			if err != nil {
+				return err
+			}
+			if len(columns) > 0 {
+				for _, c := range columns {
+					if !slices.Contains(preview.Columns, c) {
+						return fmt.Errorf("column %q not found (available: %s)", c, strings.Join(preview.Columns, ", "))
+					}
+				}
+				preview.Columns = columns
+			}
+
+			format := getOutputFormat()
+			if summary {
+				printPreviewSummary(format, summarizePreview(preview))
+				return nil
+			}
+			return printPreview(format, preview)
+		},
+	}
+
+	cmd.Flags().StringVarP(&id, "id", "i", "", "Training data ID")
+	cmd.Flags().IntVarP(&rows, "rows", "n", 0, "Number of rows to fetch (server default if 0)")
+	cmd.Flags().StringSliceVar(&columns, "columns", nil, "Columns to show, in order (comma-separated)")
+	cmd.Flags().BoolVar(&summary, "summary", false, "Show a per-column summary instead of the rows")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+// previewCell renders a preview value; null becomes an empty cell.
+func previewCell(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(b)
+	}
+}
+
+func printPreview(format string, p *api.TrainingDataPreview) error {
+	switch format {
+	case "json", "yaml":
+		rows := make([]map[string]any, len(p.Rows))
+		for i, row := range p.Rows {
+			rows[i] = make(map[string]any, len(p.Columns))
+			for _, c := range p.Columns {
+				rows[i][c] = row[c]
+			}
+		}
+		out := map[string]any{"columns": p.Columns, "rows": rows}
+		if p.TotalRows != nil {
+			out["total_rows"] = *p.TotalRows
+		}
+		utils.PrintOutput(format, out)
+		return nil
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		if err := w.Write(p.Columns); err != nil {
+			return err
+		}
+		for _, row := range p.Rows {
+			if err := w.Write(previewRecord(p.Columns, row)); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(p.Columns, "\t"))
+		for _, row := range p.Rows {
+			fmt.Fprintln(w, strings.Join(previewRecord(p.Columns, row), "\t"))
+		}
+		w.Flush()
+		if p.TotalRows != nil {
+			fmt.Printf("(%d of %d rows)\n", len(p.Rows), *p.TotalRows)
+		}
+		return nil
+	}
+}
+
+func previewRecord(columns []string, row map[string]any) []string {
+	record := make([]string, len(columns))
+	for i, c := range columns {
+		record[i] = previewCell(row[c])
+	}
+	return record
+}
+
+// columnSummary describes one column of a preview.
+type columnSummary struct {
+	Column   string   `json:"column" yaml:"column"`
+	Distinct int      `json:"distinct" yaml:"distinct"`
+	Nulls    int      `json:"nulls" yaml:"nulls"`
+	Samples  []string `json:"samples" yaml:"samples"`
+}
+
+// summarizePreview counts distinct and null (missing, null or empty) values
+// per column over the previewed rows only.
+func summarizePreview(p *api.TrainingDataPreview) []columnSummary {
+	summaries := make([]columnSummary, len(p.Columns))
+	for i, c := range p.Columns {
+		s := columnSummary{Column: c, Samples: []string{}}
+		seen := map[string]bool{}
+		for _, row := range p.Rows {
+			v := previewCell(row[c])
+			if v == "" {
+				s.Nulls++
+				continue
+			}
+			if seen[v] {
+				continue
+			}
+			seen[v] = true
+			if len(s.Samples) < previewSampleValues {
+				s.Samples = append(s.Samples, v)
+			}
+		}
+		s.Distinct = len(seen)
+		summaries[i] = s
+	}
+	return summaries
+}
+
+func printPreviewSummary(format string, summaries []columnSummary) {
+	switch format {
+	case "json", "yaml":
+		utils.PrintOutput(format, summaries)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		_ = w.Write([]string{"column", "distinct", "nulls", "samples"})
+		for _, s := range summaries {
+			_ = w.Write([]string{s.Column, strconv.Itoa(s.Distinct), strconv.Itoa(s.Nulls), strings.Join(s.Samples, "|")})
+		}
+		w.Flush()
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "COLUMN\tDISTINCT\tNULLS\tSAMPLES")
+		for _, s := range summaries {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", s.Column, s.Distinct, s.Nulls, strings.Join(s.Samples, ", "))
+		}
+		w.Flush()
+	}
+}
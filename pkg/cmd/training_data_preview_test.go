@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"recotem.org/cli/recotem/pkg/api"
+)
+
+func TestPreviewCell(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{nil, ""},
+		{"u1", "u1"},
+		{json.Number("12345678901234567890"), "12345678901234567890"},
+		{true, "true"},
+		{[]any{"a", "b"}, `["a","b"]`},
+	}
+	for _, tt := range tests {
+		if got := previewCell(tt.in); got != tt.want {
+			t.Errorf("previewCell(%#v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSummarizePreview(t *testing.T) {
+	p := &api.TrainingDataPreview{
+		Columns: []string{"user_id", "item_id"},
+		Rows: []map[string]any{
+			{"user_id": "u1", "item_id": "a"},
+			{"user_id": "u1", "item_id": nil},
+			{"user_id": "u2", "item_id": "b"},
+			{"user_id": "u3", "item_id": ""},
+			{"user_id": "u4"},
+		},
+	}
+	want := []columnSummary{
+		{Column: "user_id", Distinct: 4, Nulls: 0, Samples: []string{"u1", "u2", "u3"}},
+		{Column: "item_id", Distinct: 2, Nulls: 3, Samples: []string{"a", "b"}},
+	}
+	if got := summarizePreview(p); !reflect.DeepEqual(got, want) {
+		t.Errorf("summarizePreview() = %+v, want %+v", got, want)
+	}
+}
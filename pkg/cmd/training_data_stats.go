@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/dataset"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+func newTrainingDataStatsCmd() *cobra.Command {
+	var source dataSource
+	var schemaOpts schemaFlags
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show statistics of a local file or training data",
+		Long: "Compute interaction statistics (users, items, sparsity, per-user and per-item\n" +
+			"distributions, long-tail share and daily volume) for a local file or a\n" +
+			"training data file downloaded by ID.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := lazyClient(cmd)
+			r, td, cleanup, err := source.open(client)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			var projectID *int
+			if td != nil {
+				projectID = &td.Project
+			}
+			schema, err := schemaOpts.resolve(client, projectID)
+			if err != nil {
+				return err
+			}
+			stats, err := dataset.ComputeStats(r, schema)
+			if err != nil {
+				return err
+			}
+			printDatasetStats(getOutputFormat(), stats)
+			return nil
+		},
+	}
+
+	source.addFlags(cmd)
+	schemaOpts.addFlags(cmd)
+
+	return cmd
+}
+
+func printDatasetStats(format string, s *dataset.Stats) {
+	if format == "json" || format == "yaml" {
+		utils.PrintOutput(format, s)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Interactions:\t%d\n", s.Interactions)
+	fmt.Fprintf(w, "Users:\t%d\n", s.Users)
+	fmt.Fprintf(w, "Items:\t%d\n", s.Items)
+	fmt.Fprintf(w, "Sparsity:\t%.6f\n", s.Sparsity)
+	for _, reason := range slices.Sorted(maps.Keys(s.Skipped)) {
+		fmt.Fprintf(w, "Skipped (%s):\t%d\n", reason, s.Skipped[reason])
+	}
+	w.Flush()
+
+	printDistribution("Interactions per user", s.PerUser)
+	printDistribution("Interactions per item", s.PerItem)
+
+	fmt.Println()
+	fmt.Println("Long tail")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  Head items (top 20%%):\t%d\n", s.LongTail.HeadItems)
+	fmt.Fprintf(w, "  Head share:\t%.1f%%\n", s.LongTail.HeadShare*100)
+	fmt.Fprintf(w, "  Tail share:\t%.1f%%\n", s.LongTail.TailShare*100)
+	fmt.Fprintf(w, "  Items for 80%% of interactions:\t%d\n", s.LongTail.ItemsFor80Pct)
+	w.Flush()
+
+	if s.Time != nil {
+		fmt.Println()
+		fmt.Println("Time range")
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "  Start:\t%s\n", s.Time.Start.Format(time.RFC3339))
+		fmt.Fprintf(w, "  End:\t%s\n", s.Time.End.Format(time.RFC3339))
+		fmt.Fprintf(w, "  Active days:\t%d\n", s.Time.Days)
+		w.Flush()
+
+		fmt.Println()
+		fmt.Println("Daily volume")
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, d := range s.Time.Daily {
+			fmt.Fprintf(w, "  %s\t%d\n", d.Date, d.Count)
+		}
+		w.Flush()
+	}
+}
+
+func printDistribution(title string, d dataset.Distribution) {
+	fmt.Println()
+	fmt.Println(title)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  min / mean / max:\t%d / %.2f / %d\n", d.Min, d.Mean, d.Max)
+	parts := make([]string, 0, len(d.Quantiles))
+	for _, q := range d.Quantiles {
+		parts = append(parts, fmt.Sprintf("p%g=%d", q.Q*100, q.Value))
+	}
+	fmt.Fprintf(w, "  quantiles:\t%s\n", strings.Join(parts, " "))
+	w.Flush()
+
+	total := 0
+	for _, b := range d.Histogram {
+		total += b.Count
+	}
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	for _, b := range d.Histogram {
+		bar := ""
+		if total > 0 {
+			bar = strings.Repeat("#", (b.Count*40+total-1)/total)
+		}
+		label := fmt.Sprintf("%d-%d", b.Lower, b.Upper)
+		if b.Lower == b.Upper {
+			label = fmt.Sprint(b.Lower)
+		}
+		fmt.Fprintf(w, "  %s\t%d\t %s\n", label, b.Count, bar)
+	}
+	w.Flush()
+}
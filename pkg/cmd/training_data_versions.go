@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/ledger"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/utils"
+)
+
+// datasetVersion is one training data upload as seen by the ledger and the
+// server.
+type datasetVersion struct {
+	ID         int        `json:"id" yaml:"id"`
+	UploadedAt *time.Time `json:"uploaded_at,omitempty" yaml:"uploaded_at,omitempty"`
+	SHA256     string     `json:"sha256,omitempty" yaml:"sha256,omitempty"`
+	Basename   string     `json:"basename" yaml:"basename"`
+	Filesize   int        `json:"filesize" yaml:"filesize"`
+	Parent     *int       `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Source     string     `json:"source,omitempty" yaml:"source,omitempty"`
+	Options    string     `json:"options,omitempty" yaml:"options,omitempty"`
+	// Status is "ok" when the upload is in the ledger and on the server,
+	// "deleted" when only the ledger knows it and "untracked" when it was
+	// uploaded without this ledger.
+	Status string `json:"status" yaml:"status"`
+}
+
+func newTrainingDataVersionsCmd() *cobra.Command {
+	var project string
+
+	cmd := &cobra.Command{
+		Use:   "versions",
+		Short: "Show the upload history of a project's training data",
+		Long: "List the training data uploads recorded in the local ledger for a project,\n" +
+			"with content hashes, parents of derived uploads and whether each version\n" +
+			"still exists on the server. Server data not in the ledger is shown as untracked.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClientFromCmd(cmd)
+			if err != nil {
+				return err
+			}
+			projectID, err := strconv.Atoi(project)
+			if err != nil {
+				return err
+			}
+			book, err := loadLedger(client)
+			if err != nil {
+				return err
+			}
+			server, err := listTrainingData(client, projectID)
+			if err != nil {
+				return err
+			}
+			printDatasetVersions(getOutputFormat(), mergeVersions(book.Versions(ledger.KindTrainingData, projectID), server))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&project, "project", "p", "", "Project ID")
+	_ = cmd.MarkFlagRequired("project")
+
+	return cmd
+}
+
+func mergeVersions(entries []ledger.Entry, server []openapi.TrainingData) []datasetVersion {
+	onServer := map[int]openapi.TrainingData{}
+	for _, td := range server {
+		if td.Id != nil {
+			onServer[*td.Id] = td
+		}
+	}
+
+	versions := []datasetVersion{}
+	tracked := map[int]bool{}
+	for _, e := range entries {
+		uploadedAt := e.UploadedAt
+		v := datasetVersion{
+			ID:         e.ID,
+			UploadedAt: &uploadedAt,
+			SHA256:     e.SHA256,
+			Basename:   e.Basename,
+			Filesize:   e.Filesize,
+			Parent:     e.Parent,
+			Source:     e.Source,
+			Options:    e.Options,
+			Status:     "deleted",
+		}
+		if _, ok := onServer[e.ID]; ok {
+			v.Status = "ok"
+		}
+		tracked[e.ID] = true
+		versions = append(versions, v)
+	}
+	for id, td := range onServer {
+		if tracked[id] {
+			continue
+		}
+		e := newLedgerEntry(ledger.KindTrainingData, td.Project, td.Id, td.Basename, td.Filesize)
+		versions = append(versions, datasetVersion{
+			ID:         id,
+			UploadedAt: td.InsDatetime,
+			Basename:   e.Basename,
+			Filesize:   e.Filesize,
+			Status:     "untracked",
+		})
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, b := versions[i].UploadedAt, versions[j].UploadedAt
+		if a != nil && b != nil && !a.Equal(*b) {
+			return a.Before(*b)
+		}
+		return versions[i].ID < versions[j].ID
+	})
+	return versions
+}
+
+func printDatasetVersions(format string, versions []datasetVersion) {
+	if format == "json" || format == "yaml" {
+		utils.PrintOutput(format, versions)
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUPLOADED\tSHA256\tBASENAME\tSIZE\tPARENT\tSTATUS")
+	for _, v := range versions {
+		sha := v.SHA256
+		if len(sha) > 12 {
+			sha = sha[:12]
+		}
+		if sha == "" {
+			sha = utils.NoValue
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			v.ID, utils.FormatTime(v.UploadedAt), sha, v.Basename, v.Filesize, utils.Itoa(v.Parent), v.Status)
+	}
+	w.Flush()
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"recotem.org/cli/recotem/pkg/api"
+	"recotem.org/cli/recotem/pkg/dataset"
+	"recotem.org/cli/recotem/pkg/ledger"
+	"recotem.org/cli/recotem/pkg/openapi"
+	"recotem.org/cli/recotem/pkg/pseudonym"
+)
+
+// uploadSource holds the conversion and deduplication flags shared by
+// upload commands.
+type uploadSource struct {
+	file         string
+	inputFormat  string
+	mappings     []string
+	pseudonymize string
+	onDuplicate  string
+}
+
+func (s *uploadSource) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&s.file, "file", "f", "", "File path")
+	s.addConversionFlags(cmd)
+	cmd.Flags().StringVar(&s.onDuplicate, "on-duplicate", onDuplicateSkip, "What to do if the same file was already uploaded to the project (skip, warn)")
+}
+
+// addConversionFlags adds the flags that change the uploaded content, for
+// commands that take --file with their own meaning.
+func (s *uploadSource) addConversionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&s.inputFormat, "input-format", "", "Input format (parquet, jsonl, csv, tsv); detected from the file name if omitted")
+	cmd.Flags().StringArrayVar(&s.mappings, "map", nil, "Rename a column before upload (src=dst, repeatable)")
+	cmd.Flags().StringVar(&s.pseudonymize, "pseudonymize", "", "Replace IDs with pseudonyms before upload (hmac, sequential)")
+}
+
+// reader opens the source file with --map renames applied, for checks
+// made before the upload.
+func (s *uploadSource) reader() (dataset.Reader, error) {
+	format, err := dataset.ParseFormat(s.inputFormat)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := dataset.ParseColumnMap(s.mappings)
+	if err != nil {
+		return nil, err
+	}
+	r, err := dataset.Open(s.file, format)
+	if err != nil {
+		return nil, err
+	}
+	renamed, err := dataset.Rename(r, columns)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	return renamed, nil
+}
+
+// options describes the conversion flags for the upload ledger.
+func (s *uploadSource) options() string {
+	var parts []string
+	if s.inputFormat != "" {
+		parts = append(parts, "input-format="+s.inputFormat)
+	}
+	for _, m := range s.mappings {
+		parts = append(parts, "map="+m)
+	}
+	if s.pseudonymize != "" {
+		parts = append(parts, "pseudonymize="+s.pseudonymize)
+	}
+	return strings.Join(parts, " ")
+}
+
+// source returns the absolute path of the file for the upload ledger.
+func (s *uploadSource) source() string {
+	if abs, err := filepath.Abs(s.file); err == nil {
+		return abs
+	}
+	return s.file
+}
+
+// open returns the upload filename and a CSV stream of the source file.
+// With --pseudonymize, the project's columns of the given kinds are
+// rewritten and the returned store must be saved once the upload succeeds;
+// otherwise the store is nil.
+func (s *uploadSource) open(client api.Client, projectID int, kinds ...pseudonym.Kind) (string, io.ReadCloser, *pseudonym.Store, error) {
+	format, err := dataset.ParseFormat(s.inputFormat)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	columns, err := dataset.ParseColumnMap(s.mappings)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	opts := dataset.ConvertOptions{
+		Format:  format,
+		Columns: columns,
+	}
+
+	store, targets, err := s.pseudonyms(client, projectID, kinds...)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if store != nil {
+		opts.Transform = func(r dataset.Reader) (dataset.Reader, error) {
+			return pseudonym.Wrap(r, store.Mapping, targets)
+		}
+	}
+
+	name, r, err := dataset.OpenAsCSV(s.file, opts)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return name, r, store, nil
+}
+
+// pseudonyms opens the project's pseudonym store for --pseudonymize and
+// returns it with the columns of the given kinds to rewrite. Without the
+// flag the store is nil.
+func (s *uploadSource) pseudonyms(client api.Client, projectID int, kinds ...pseudonym.Kind) (*pseudonym.Store, map[string]pseudonym.Kind, error) {
+	if s.pseudonymize == "" {
+		return nil, nil, nil
+	}
+	mode, err := pseudonym.ParseMode(s.pseudonymize)
+	if err != nil {
+		return nil, nil, err
+	}
+	project, err := getProject(client, projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	store, err := openPseudonymStore(client, projectID, mode)
+	if err != nil {
+		return nil, nil, err
+	}
+	targets := map[string]pseudonym.Kind{}
+	for _, kind := range kinds {
+		if kind == pseudonym.KindUser {
+			targets[project.UserColumn] = kind
+		} else {
+			targets[project.ItemColumn] = kind
+		}
+	}
+	return store, targets, nil
+}
+
+// uploadTrainingData converts and uploads the source as training data of the
+// project, with the user and item columns pseudonymized, and records it in
+// the ledger under sha.
+func (s *uploadSource) uploadTrainingData(client api.Client, book *ledger.Ledger, projectID int, sha string) (*openapi.TrainingData, error) {
+	name, r, store, err := s.open(client, projectID, pseudonym.KindUser, pseudonym.KindItem)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	x, err := client.UploadTrainingDataFrom(projectID, name, r)
+	if err != nil {
+		return nil, err
+	}
+	entry := newLedgerEntry(ledger.KindTrainingData, projectID, x.Id, x.Basename, x.Filesize)
+	return x, s.finish(book, store, entry, sha)
+}
+
+// uploadItemMetaData is uploadTrainingData for item meta data, where only
+// the item column is pseudonymized.
+func (s *uploadSource) uploadItemMetaData(client api.Client, book *ledger.Ledger, projectID int, sha string) (*openapi.ItemMetaData, error) {
+	name, r, store, err := s.open(client, projectID, pseudonym.KindItem)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	x, err := client.UploadItemMetaDataFrom(projectID, name, r)
+	if err != nil {
+		return nil, err
+	}
+	entry := newLedgerEntry(ledger.KindItemMetaData, projectID, x.Id, x.Basename, x.Filesize)
+	return x, s.finish(book, store, entry, sha)
+}
+
+// finish saves the pseudonym mapping used by a successful upload and records
+// the upload in the ledger.
+func (s *uploadSource) finish(book *ledger.Ledger, store *pseudonym.Store, entry ledger.Entry, sha string) error {
+	if store != nil {
+		if err := store.Save(); err != nil {
+			return fmt.Errorf("uploaded, but saving the pseudonym mapping failed: %w", err)
+		}
+	}
+	entry.SHA256 = sha
+	entry.Source = s.source()
+	entry.Options = s.options()
+	recordUpload(book, entry)
+	return nil
+}
+
+// checkItemMetaData validates the source as item meta data of the project
+// before it is uploaded, writing warnings to warn.
+func (s *uploadSource) checkItemMetaData(client api.Client, projectID int, warn io.Writer) error {
+	project, err := getProject(client, projectID)
+	if err != nil {
+		return err
+	}
+	r, err := s.reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if err := validateItemMetaData(r, project.ItemColumn, warn); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package dataset
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ConvertOptions controls how a local file is turned into the CSV the
+// server expects.
+type ConvertOptions struct {
+	// Format overrides filename-based format detection.
+	Format Format
+	// Columns renames source columns (src -> dst) before upload.
+	Columns map[string]string
+	// Transform, if set, wraps the renamed reader, e.g. to rewrite values.
+	Transform func(Reader) (Reader, error)
+}
+
+// OpenAsCSV returns the upload name and a CSV stream for path. Plain CSV
+// files that need no renaming are passed through untouched; anything else
+// is decoded and re-encoded row by row in a background goroutine, so the
+// whole file is never held in memory.
+func OpenAsCSV(path string, opts ConvertOptions) (string, io.ReadCloser, error) {
+	format := opts.Format
+	if format == "" {
+		format = DetectFormat(path)
+	}
+	if format == FormatCSV && len(opts.Columns) == 0 && opts.Transform == nil && DetectCompression(path) == CompressionNone {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", nil, err
+		}
+		return filepath.Base(path), f, nil
+	}
+
+	r, err := Open(path, format)
+	if err != nil {
+		return "", nil, err
+	}
+	renamed, err := Rename(r, opts.Columns)
+	if err != nil {
+		r.Close()
+		return "", nil, err
+	}
+	if opts.Transform != nil {
+		if renamed, err = opts.Transform(renamed); err != nil {
+			r.Close()
+			return "", nil, err
+		}
+	}
+	return CSVName(path), StreamCSV(renamed), nil
+}
+
+// StreamCSV encodes r as CSV into a pipe and returns its read end.
+// The reader is closed once it has been drained or the pipe is closed.
+func StreamCSV(r Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		err := WriteCSV(pw, r)
+		r.Close()
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
@@ -0,0 +1,187 @@
+package dataset
+
+import (
+	"io"
+	"sort"
+	"time"
+)
+
+// DiffReport compares two interaction files, A (before) and B (after).
+type DiffReport struct {
+	A                Side         `json:"a" yaml:"a"`
+	B                Side         `json:"b" yaml:"b"`
+	InteractionDelta int64        `json:"interaction_delta" yaml:"interaction_delta"`
+	Users            SetDiff      `json:"users" yaml:"users"`
+	Items            SetDiff      `json:"items" yaml:"items"`
+	TopUsers         []CountDelta `json:"top_changed_users" yaml:"top_changed_users"`
+	TopItems         []CountDelta `json:"top_changed_items" yaml:"top_changed_items"`
+}
+
+// Side summarizes one of the compared files.
+type Side struct {
+	Interactions int64          `json:"interactions" yaml:"interactions"`
+	Users        int            `json:"users" yaml:"users"`
+	Items        int            `json:"items" yaml:"items"`
+	Start        *time.Time     `json:"start,omitempty" yaml:"start,omitempty"`
+	End          *time.Time     `json:"end,omitempty" yaml:"end,omitempty"`
+	Skipped      map[string]int `json:"skipped_rows,omitempty" yaml:"skipped_rows,omitempty"`
+}
+
+// SetDiff counts IDs only in B (added), only in A (removed) and in both,
+// with a sorted sample of the added and removed IDs.
+type SetDiff struct {
+	Added         int      `json:"added" yaml:"added"`
+	Removed       int      `json:"removed" yaml:"removed"`
+	Common        int      `json:"common" yaml:"common"`
+	AddedSample   []string `json:"added_sample" yaml:"added_sample"`
+	RemovedSample []string `json:"removed_sample" yaml:"removed_sample"`
+}
+
+// CountDelta is the change in interactions of one user or item.
+type CountDelta struct {
+	ID     string `json:"id" yaml:"id"`
+	Before int    `json:"before" yaml:"before"`
+	After  int    `json:"after" yaml:"after"`
+	Delta  int    `json:"delta" yaml:"delta"`
+}
+
+type counts struct {
+	side  Side
+	users map[string]int
+	items map[string]int
+}
+
+// Diff reads both files once and compares them. top limits the number of
+// changed users/items and sampled IDs reported.
+func Diff(a, b Reader, schema Schema, top int) (*DiffReport, error) {
+	ca, err := countInteractions(a, schema)
+	if err != nil {
+		return nil, err
+	}
+	cb, err := countInteractions(b, schema)
+	if err != nil {
+		return nil, err
+	}
+	return &DiffReport{
+		A:                ca.side,
+		B:                cb.side,
+		InteractionDelta: cb.side.Interactions - ca.side.Interactions,
+		Users:            setDiff(ca.users, cb.users, top),
+		Items:            setDiff(ca.items, cb.items, top),
+		TopUsers:         topDeltas(ca.users, cb.users, top),
+		TopItems:         topDeltas(ca.items, cb.items, top),
+	}, nil
+}
+
+func countInteractions(r Reader, schema Schema) (*counts, error) {
+	userIdx, err := ColumnIndex(r.Columns(), schema.UserColumn)
+	if err != nil {
+		return nil, err
+	}
+	itemIdx, err := ColumnIndex(r.Columns(), schema.ItemColumn)
+	if err != nil {
+		return nil, err
+	}
+	timeIdx := -1
+	if schema.TimeColumn != "" {
+		if timeIdx, err = ColumnIndex(r.Columns(), schema.TimeColumn); err != nil {
+			return nil, err
+		}
+	}
+
+	c := &counts{side: Side{Skipped: map[string]int{}}, users: map[string]int{}, items: map[string]int{}}
+	var start, end time.Time
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		user, item := row[userIdx], row[itemIdx]
+		if user == "" || item == "" {
+			c.side.Skipped["missing_id"]++
+			continue
+		}
+		if timeIdx >= 0 {
+			t, err := ParseTime(row[timeIdx])
+			if err != nil {
+				c.side.Skipped["invalid_time"]++
+				continue
+			}
+			if start.IsZero() || t.Before(start) {
+				start = t
+			}
+			if end.IsZero() || t.After(end) {
+				end = t
+			}
+		}
+		c.users[user]++
+		c.items[item]++
+		c.side.Interactions++
+	}
+	c.side.Users = len(c.users)
+	c.side.Items = len(c.items)
+	if !start.IsZero() {
+		c.side.Start, c.side.End = &start, &end
+	}
+	if len(c.side.Skipped) == 0 {
+		c.side.Skipped = nil
+	}
+	return c, nil
+}
+
+func setDiff(a, b map[string]int, sample int) SetDiff {
+	d := SetDiff{AddedSample: []string{}, RemovedSample: []string{}}
+	for id := range b {
+		if _, ok := a[id]; ok {
+			d.Common++
+		} else {
+			d.Added++
+			d.AddedSample = append(d.AddedSample, id)
+		}
+	}
+	for id := range a {
+		if _, ok := b[id]; !ok {
+			d.Removed++
+			d.RemovedSample = append(d.RemovedSample, id)
+		}
+	}
+	sort.Strings(d.AddedSample)
+	sort.Strings(d.RemovedSample)
+	d.AddedSample = d.AddedSample[:min(sample, len(d.AddedSample))]
+	d.RemovedSample = d.RemovedSample[:min(sample, len(d.RemovedSample))]
+	return d
+}
+
+// topDeltas returns the IDs with the largest absolute change in count,
+// ties broken by ID.
+func topDeltas(a, b map[string]int, top int) []CountDelta {
+	deltas := []CountDelta{}
+	for id, before := range a {
+		if after := b[id]; after != before {
+			deltas = append(deltas, CountDelta{ID: id, Before: before, After: after, Delta: after - before})
+		}
+	}
+	for id, after := range b {
+		if _, ok := a[id]; !ok {
+			deltas = append(deltas, CountDelta{ID: id, After: after, Delta: after})
+		}
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		di, dj := abs(deltas[i].Delta), abs(deltas[j].Delta)
+		if di != dj {
+			return di > dj
+		}
+		return deltas[i].ID < deltas[j].ID
+	})
+	return deltas[:min(top, len(deltas))]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
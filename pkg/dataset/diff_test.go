@@ -0,0 +1,55 @@
+package dataset
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	a, err := Open(writeFile(t, "a.csv", []byte("user_id,item_id,ts\n"+
+		"u1,i1,2024-01-01\n"+
+		"u1,i2,2024-01-02\n"+
+		"u2,i1,2024-01-03\n"+
+		"u3,i3,2024-01-04\n")), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := Open(writeFile(t, "b.csv", []byte("user_id,item_id,ts\n"+
+		"u1,i1,2024-01-01\n"+
+		"u2,i1,2024-01-03\n"+
+		"u2,i2,2024-02-01\n"+
+		"u2,i4,2024-02-02\n"+
+		"u4,i4,2024-02-03\n")), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	d, err := Diff(a, b, prepareSchema, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.A.Interactions != 4 || d.B.Interactions != 5 || d.InteractionDelta != 1 {
+		t.Errorf("unexpected interaction counts: %+v %+v %d", d.A, d.B, d.InteractionDelta)
+	}
+	wantUsers := SetDiff{Added: 1, Removed: 1, Common: 2, AddedSample: []string{"u4"}, RemovedSample: []string{"u3"}}
+	if !reflect.DeepEqual(d.Users, wantUsers) {
+		t.Errorf("users = %+v, want %+v", d.Users, wantUsers)
+	}
+	wantItems := SetDiff{Added: 1, Removed: 1, Common: 2, AddedSample: []string{"i4"}, RemovedSample: []string{"i3"}}
+	if !reflect.DeepEqual(d.Items, wantItems) {
+		t.Errorf("items = %+v, want %+v", d.Items, wantItems)
+	}
+	wantTopUsers := []CountDelta{{ID: "u2", Before: 1, After: 3, Delta: 2}, {ID: "u1", Before: 2, After: 1, Delta: -1}}
+	if !reflect.DeepEqual(d.TopUsers, wantTopUsers) {
+		t.Errorf("top users = %+v, want %+v", d.TopUsers, wantTopUsers)
+	}
+	if len(d.TopItems) != 2 || d.TopItems[0].ID != "i4" || d.TopItems[0].Delta != 2 {
+		t.Errorf("unexpected top items %+v", d.TopItems)
+	}
+	if d.B.End == nil || !d.B.End.Equal(time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected time range of B: %v - %v", d.B.Start, d.B.End)
+	}
+}
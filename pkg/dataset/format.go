@@ -0,0 +1,85 @@
+package dataset
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Format identifies the row layout of a local data file.
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatTSV     Format = "tsv"
+	FormatJSONL   Format = "jsonl"
+	FormatParquet Format = "parquet"
+)
+
+// Compression identifies a stream compression wrapped around a data file.
+type Compression string
+
+const (
+	CompressionNone Compression = ""
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// ParseFormat converts a --input-format value to a Format.
+// An empty string yields an empty Format, meaning "detect from the filename".
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "":
+		return "", nil
+	case "csv":
+		return FormatCSV, nil
+	case "tsv":
+		return FormatTSV, nil
+	case "jsonl", "ndjson":
+		return FormatJSONL, nil
+	case "parquet":
+		return FormatParquet, nil
+	}
+	return "", fmt.Errorf("unsupported input format %q (expected parquet, jsonl, csv or tsv)", s)
+}
+
+// DetectCompression guesses the compression of a file from its extension.
+func DetectCompression(path string) Compression {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz", ".gzip":
+		return CompressionGzip
+	case ".zst", ".zstd":
+		return CompressionZstd
+	}
+	return CompressionNone
+}
+
+// DetectFormat guesses the format of a file from its extension, ignoring
+// any compression suffix. Unknown extensions are treated as CSV.
+func DetectFormat(path string) Format {
+	name := trimCompressionExt(path)
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".tsv", ".tab":
+		return FormatTSV
+	case ".jsonl", ".ndjson", ".json":
+		return FormatJSONL
+	case ".parquet", ".pq":
+		return FormatParquet
+	}
+	return FormatCSV
+}
+
+// CSVName returns the name under which a converted file is uploaded:
+// the base name with compression and format extensions replaced by ".csv".
+func CSVName(path string) string {
+	name := filepath.Base(trimCompressionExt(path))
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + ".csv"
+}
+
+func trimCompressionExt(path string) string {
+	if DetectCompression(path) == CompressionNone {
+		return path
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path))
+}
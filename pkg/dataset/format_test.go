@@ -0,0 +1,100 @@
+package dataset
+
+import "testing"
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  Format
+		expectErr bool
+	}{
+		{"", "", false},
+		{"csv", FormatCSV, false},
+		{"TSV", FormatTSV, false},
+		{"jsonl", FormatJSONL, false},
+		{"ndjson", FormatJSONL, false},
+		{"parquet", FormatParquet, false},
+		{"xlsx", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseFormat(tt.input)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected Format
+	}{
+		{"data.csv", FormatCSV},
+		{"data.csv.gz", FormatCSV},
+		{"data.tsv", FormatTSV},
+		{"logs/events.jsonl.zst", FormatJSONL},
+		{"events.ndjson", FormatJSONL},
+		{"part-0001.parquet", FormatParquet},
+		{"part-0001.parquet.gz", FormatParquet},
+		{"noext", FormatCSV},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if result := DetectFormat(tt.path); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDetectCompression(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected Compression
+	}{
+		{"data.csv", CompressionNone},
+		{"data.csv.gz", CompressionGzip},
+		{"data.jsonl.zst", CompressionZstd},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if result := DetectCompression(tt.path); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestCSVName(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/tmp/data.csv", "data.csv"},
+		{"/tmp/events.jsonl.gz", "events.csv"},
+		{"part-0001.parquet", "part-0001.csv"},
+		{"data.tsv.zst", "data.csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if result := CSVName(tt.path); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package dataset
+
+import (
+	"io"
+	"sort"
+)
+
+// ItemMetadataReport describes the quality of an item metadata file and,
+// if training data was given, how well it covers the interacted items.
+type ItemMetadataReport struct {
+	Rows  int64 `json:"rows" yaml:"rows"`
+	Items int   `json:"items" yaml:"items"`
+	// MissingItemID counts rows with an empty item ID.
+	MissingItemID   int64        `json:"missing_item_id" yaml:"missing_item_id"`
+	DuplicateItems  int          `json:"duplicate_items" yaml:"duplicate_items"`
+	DuplicateSample []string     `json:"duplicate_sample" yaml:"duplicate_sample"`
+	Columns         []ColumnFill `json:"columns" yaml:"columns"`
+	// EmptyColumns lists columns without a single non-empty value.
+	EmptyColumns []string      `json:"empty_columns" yaml:"empty_columns"`
+	Coverage     *ItemCoverage `json:"coverage,omitempty" yaml:"coverage,omitempty"`
+}
+
+// ColumnFill is the number of non-empty values in a column.
+type ColumnFill struct {
+	Name   string `json:"name" yaml:"name"`
+	Filled int64  `json:"filled" yaml:"filled"`
+}
+
+// ItemCoverage is the share of interacted items that have metadata.
+type ItemCoverage struct {
+	InteractedItems int      `json:"interacted_items" yaml:"interacted_items"`
+	Covered         int      `json:"covered" yaml:"covered"`
+	Ratio           float64  `json:"ratio" yaml:"ratio"`
+	MissingSample   []string `json:"missing_sample" yaml:"missing_sample"`
+}
+
+// AnalyzeItemMetadata reads an item metadata file keyed by itemColumn. When
+// training is not nil, its trainingItemColumn is read as well to compute the
+// coverage. sample limits the number of IDs listed in the report.
+func AnalyzeItemMetadata(meta Reader, itemColumn string, training Reader, trainingItemColumn string, sample int) (*ItemMetadataReport, error) {
+	itemIdx, err := ColumnIndex(meta.Columns(), itemColumn)
+	if err != nil {
+		return nil, err
+	}
+	report := &ItemMetadataReport{DuplicateSample: []string{}, EmptyColumns: []string{}}
+	filled := make([]int64, len(meta.Columns()))
+	items := map[string]int{}
+	for {
+		row, err := meta.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Rows++
+		for i, v := range row {
+			if v != "" {
+				filled[i]++
+			}
+		}
+		if row[itemIdx] == "" {
+			report.MissingItemID++
+			continue
+		}
+		items[row[itemIdx]]++
+	}
+
+	report.Items = len(items)
+	var duplicates []string
+	for id, n := range items {
+		if n > 1 {
+			duplicates = append(duplicates, id)
+		}
+	}
+	sort.Strings(duplicates)
+	report.DuplicateItems = len(duplicates)
+	report.DuplicateSample = append(report.DuplicateSample, duplicates[:min(sample, len(duplicates))]...)
+	for i, name := range meta.Columns() {
+		report.Columns = append(report.Columns, ColumnFill{Name: name, Filled: filled[i]})
+		if filled[i] == 0 {
+			report.EmptyColumns = append(report.EmptyColumns, name)
+		}
+	}
+
+	if training != nil {
+		if report.Coverage, err = itemCoverage(items, training, trainingItemColumn, sample); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+func itemCoverage(items map[string]int, training Reader, itemColumn string, sample int) (*ItemCoverage, error) {
+	idx, err := ColumnIndex(training.Columns(), itemColumn)
+	if err != nil {
+		return nil, err
+	}
+	interacted := map[string]bool{}
+	for {
+		row, err := training.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if row[idx] != "" {
+			interacted[row[idx]] = true
+		}
+	}
+
+	c := &ItemCoverage{InteractedItems: len(interacted), MissingSample: []string{}}
+	var missing []string
+	for id := range interacted {
+		if items[id] > 0 {
+			c.Covered++
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	if c.InteractedItems > 0 {
+		c.Ratio = float64(c.Covered) / float64(c.InteractedItems)
+	}
+	sort.Strings(missing)
+	c.MissingSample = append(c.MissingSample, missing[:min(sample, len(missing))]...)
+	return c, nil
+}
@@ -0,0 +1,57 @@
+package dataset
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeItemMetadata(t *testing.T) {
+	meta, err := Open(writeFile(t, "items.csv", []byte("item_id,title,genre,price\n"+
+		"i1,First,,\n"+
+		"i2,Second,,\n"+
+		"i2,Second again,,\n"+
+		",Orphan,,\n"+
+		"i5,Fifth,,\n")), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer meta.Close()
+	training, err := Open(writeFile(t, "data.csv", []byte("user_id,item_id\n"+
+		"u1,i1\nu1,i2\nu2,i3\nu3,i4\nu3,i1\n")), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer training.Close()
+
+	r, err := AnalyzeItemMetadata(meta, "item_id", training, "item_id", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Rows != 5 || r.Items != 3 || r.MissingItemID != 1 {
+		t.Errorf("unexpected counts: %+v", r)
+	}
+	if r.DuplicateItems != 1 || !reflect.DeepEqual(r.DuplicateSample, []string{"i2"}) {
+		t.Errorf("unexpected duplicates: %d %v", r.DuplicateItems, r.DuplicateSample)
+	}
+	if !reflect.DeepEqual(r.EmptyColumns, []string{"genre", "price"}) {
+		t.Errorf("unexpected empty columns: %v", r.EmptyColumns)
+	}
+	if r.Columns[1] != (ColumnFill{Name: "title", Filled: 5}) {
+		t.Errorf("unexpected fill: %+v", r.Columns[1])
+	}
+	want := &ItemCoverage{InteractedItems: 4, Covered: 2, Ratio: 0.5, MissingSample: []string{"i3", "i4"}}
+	if !reflect.DeepEqual(r.Coverage, want) {
+		t.Errorf("coverage = %+v, want %+v", r.Coverage, want)
+	}
+}
+
+func TestAnalyzeItemMetadataMissingColumn(t *testing.T) {
+	meta, err := Open(writeFile(t, "items.csv", []byte("id,title\ni1,First\n")), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer meta.Close()
+	if _, err := AnalyzeItemMetadata(meta, "item_id", nil, "", 10); err == nil {
+		t.Error("expected error for a missing item column")
+	}
+}
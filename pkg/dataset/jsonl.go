@@ -0,0 +1,125 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonlReader reads newline-delimited JSON objects. The columns are the keys
+// of the first object in document order; later objects may omit keys (read
+// as empty values) but may not introduce new ones, since the header has
+// already been emitted by the time they are seen.
+type jsonlReader struct {
+	src     io.ReadCloser
+	dec     *json.Decoder
+	columns []string
+	index   map[string]int
+	first   []string
+	line    int
+}
+
+func newJSONLReader(src io.ReadCloser) (*jsonlReader, error) {
+	dec := json.NewDecoder(src)
+	dec.UseNumber()
+	r := &jsonlReader{src: src, dec: dec, index: map[string]int{}}
+
+	keys, values, err := r.next()
+	if err == io.EOF {
+		return nil, fmt.Errorf("no records found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, k := range keys {
+		r.index[k] = i
+	}
+	r.columns = keys
+	r.first = values
+	return r, nil
+}
+
+func (r *jsonlReader) Columns() []string { return r.columns }
+
+func (r *jsonlReader) Read() ([]string, error) {
+	if r.first != nil {
+		row := r.first
+		r.first = nil
+		return row, nil
+	}
+	keys, values, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	row := make([]string, len(r.columns))
+	for i, k := range keys {
+		pos, ok := r.index[k]
+		if !ok {
+			return nil, fmt.Errorf("record %d: unexpected key %q not present in the first record", r.line, k)
+		}
+		row[pos] = values[i]
+	}
+	return row, nil
+}
+
+func (r *jsonlReader) Close() error { return r.src.Close() }
+
+// next decodes one JSON object and returns its keys and flattened values.
+func (r *jsonlReader) next() ([]string, []string, error) {
+	var raw json.RawMessage
+	if err := r.dec.Decode(&raw); err != nil {
+		if err == io.EOF {
+			return nil, nil, io.EOF
+		}
+		return nil, nil, fmt.Errorf("record %d: %w", r.line+1, err)
+	}
+	r.line++
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, nil, fmt.Errorf("record %d: %w", r.line, err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, nil, fmt.Errorf("record %d: expected a JSON object", r.line)
+	}
+
+	var keys, values []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, fmt.Errorf("record %d: %w", r.line, err)
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, nil, fmt.Errorf("record %d: %w", r.line, err)
+		}
+		keys = append(keys, key)
+		values = append(values, jsonScalar(value))
+	}
+	return keys, values, nil
+}
+
+// jsonScalar renders a JSON value as a CSV cell: strings unquoted, null as
+// empty, and nested objects or arrays as compact JSON.
+func jsonScalar(raw json.RawMessage) string {
+	raw = bytes.TrimSpace(raw)
+	switch {
+	case len(raw) == 0, bytes.Equal(raw, []byte("null")):
+		return ""
+	case raw[0] == '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return s
+		}
+	case raw[0] == '{' || raw[0] == '[':
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, raw); err == nil {
+			return buf.String()
+		}
+	}
+	return string(raw)
+}
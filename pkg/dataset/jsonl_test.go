@@ -0,0 +1,59 @@
+package dataset
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONLReader(t *testing.T) {
+	content := strings.Join([]string{
+		`{"user":"u1","item":42,"score":1.5,"tags":["a","b"]}`,
+		`{"item":7,"user":"u2"}`,
+		`{"user":null,"item":true,"score":0}`,
+	}, "\n")
+	path := writeFile(t, "events.jsonl", []byte(content))
+
+	r, err := Open(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer r.Close()
+
+	if !reflect.DeepEqual(r.Columns(), []string{"user", "item", "score", "tags"}) {
+		t.Errorf("unexpected columns: %v", r.Columns())
+	}
+	expected := [][]string{
+		{"u1", "42", "1.5", `["a","b"]`},
+		{"u2", "7", "", ""},
+		{"", "true", "0", ""},
+	}
+	if rows := readAll(t, r); !reflect.DeepEqual(rows, expected) {
+		t.Errorf("unexpected rows: %v", rows)
+	}
+}
+
+func TestJSONLReaderRejectsNewKeys(t *testing.T) {
+	content := `{"user":"u1"}` + "\n" + `{"user":"u2","item":"i2"}` + "\n"
+	path := writeFile(t, "events.jsonl", []byte(content))
+
+	r, err := Open(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer r.Close()
+
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("unexpected error on first row: %v", err)
+	}
+	if _, err := r.Read(); err == nil || !strings.Contains(err.Error(), "item") {
+		t.Errorf("expected unexpected-key error, got %v", err)
+	}
+}
+
+func TestJSONLReaderRejectsNonObjects(t *testing.T) {
+	path := writeFile(t, "events.jsonl", []byte("[1,2,3]\n"))
+	if _, err := Open(path, ""); err == nil {
+		t.Error("expected error for non-object record, got nil")
+	}
+}
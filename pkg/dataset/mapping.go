@@ -0,0 +1,62 @@
+package dataset
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ParseColumnMap parses --map values of the form "src=dst".
+func ParseColumnMap(pairs []string) (map[string]string, error) {
+	m := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		src, dst, ok := strings.Cut(p, "=")
+		src, dst = strings.TrimSpace(src), strings.TrimSpace(dst)
+		if !ok || src == "" || dst == "" {
+			return nil, fmt.Errorf("invalid column mapping %q (expected src=dst)", p)
+		}
+		if _, dup := m[src]; dup {
+			return nil, fmt.Errorf("column %q is mapped more than once", src)
+		}
+		m[src] = dst
+	}
+	return m, nil
+}
+
+// Rename returns a reader whose column names are translated through m.
+// Every source column in m must exist in r, and the result must not
+// contain duplicate names.
+func Rename(r Reader, m map[string]string) (Reader, error) {
+	if len(m) == 0 {
+		return r, nil
+	}
+	columns := make([]string, len(r.Columns()))
+	seen := make(map[string]bool, len(columns))
+	found := 0
+	for i, c := range r.Columns() {
+		if dst, ok := m[c]; ok {
+			c = dst
+			found++
+		}
+		if seen[c] {
+			return nil, fmt.Errorf("duplicate column %q after renaming", c)
+		}
+		seen[c] = true
+		columns[i] = c
+	}
+	if found != len(m) {
+		for src := range m {
+			if !slices.Contains(r.Columns(), src) {
+				return nil, fmt.Errorf("column %q not found (available: %s)", src, strings.Join(r.Columns(), ", "))
+			}
+		}
+	}
+	return &renamedReader{Reader: r, columns: columns}, nil
+}
+
+type renamedReader struct {
+	Reader
+	columns []string
+}
+
+func (r *renamedReader) Columns() []string { return r.columns }
@@ -0,0 +1,80 @@
+package dataset
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseColumnMap(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     []string
+		expected  map[string]string
+		expectErr bool
+	}{
+		{"empty", nil, map[string]string{}, false},
+		{"single", []string{"uid=user_id"}, map[string]string{"uid": "user_id"}, false},
+		{"spaces", []string{" uid = user_id "}, map[string]string{"uid": "user_id"}, false},
+		{"missing separator", []string{"uid"}, nil, true},
+		{"empty target", []string{"uid="}, nil, true},
+		{"duplicate source", []string{"a=b", "a=c"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseColumnMap(tt.input)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRename(t *testing.T) {
+	path := writeFile(t, "data.csv", []byte(sampleCSV))
+
+	tests := []struct {
+		name      string
+		mapping   map[string]string
+		expected  []string
+		expectErr bool
+	}{
+		{"no mapping", nil, []string{"user_id", "item_id", "ts"}, false},
+		{"rename one", map[string]string{"ts": "timestamp"}, []string{"user_id", "item_id", "timestamp"}, false},
+		{"unknown column", map[string]string{"missing": "x"}, nil, true},
+		{"collision", map[string]string{"ts": "item_id"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Open(path, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer r.Close()
+
+			renamed, err := Rename(r, tt.mapping)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(renamed.Columns(), tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, renamed.Columns())
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package dataset
+
+import (
+	"encoding/csv"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MergeOptions controls how Merge combines interaction files.
+type MergeOptions struct {
+	// Schema names the columns that identify an interaction. When it has a
+	// time column, rows are duplicates only if the time matches as well.
+	Schema Schema
+	// Retention drops rows older than this duration before the newest
+	// timestamp across all inputs. Zero keeps everything.
+	Retention time.Duration
+}
+
+// MergeReport counts the rows read from each input and what was dropped.
+type MergeReport struct {
+	InputRows  []int64          `json:"input_rows" yaml:"input_rows"`
+	OutputRows int64            `json:"output_rows" yaml:"output_rows"`
+	Dropped    map[string]int64 `json:"dropped_rows" yaml:"dropped_rows"`
+	Since      *time.Time       `json:"since,omitempty" yaml:"since,omitempty"`
+}
+
+// Merge concatenates inputs into w as CSV with the columns of the first
+// input, dropping repeated interactions (the first occurrence wins) and rows
+// outside the retention window. Later inputs may order their columns
+// differently but must have the same set of columns. Rows are streamed;
+// only a 64-bit hash per kept interaction is held in memory.
+func Merge(inputs []func() (Reader, error), opts MergeOptions, w io.Writer) (*MergeReport, error) {
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("nothing to merge")
+	}
+	if opts.Retention > 0 && opts.Schema.TimeColumn == "" {
+		return nil, fmt.Errorf("a retention window requires a time column")
+	}
+
+	report := &MergeReport{Dropped: map[string]int64{}}
+	var since time.Time
+	if opts.Retention > 0 {
+		var latest time.Time
+		for _, open := range inputs {
+			t, err := latestTime(open, opts.Schema.TimeColumn)
+			if err != nil {
+				return nil, err
+			}
+			if t.After(latest) {
+				latest = t
+			}
+		}
+		if !latest.IsZero() {
+			since = latest.Add(-opts.Retention)
+			report.Since = &since
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	var columns []string
+	seen := map[uint64]struct{}{}
+	for i, open := range inputs {
+		r, err := open()
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			columns = r.Columns()
+			if err := cw.Write(columns); err != nil {
+				r.Close()
+				return nil, err
+			}
+		}
+		n, err := mergeInput(r, columns, opts.Schema, since, seen, cw, report)
+		r.Close()
+		report.InputRows = append(report.InputRows, n)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// mergeInput copies one input, reordered to columns, returning the number
+// of rows read.
+func mergeInput(r Reader, columns []string, schema Schema, since time.Time,
+	seen map[uint64]struct{}, cw *csv.Writer, report *MergeReport) (int64, error) {
+	if len(r.Columns()) != len(columns) {
+		return 0, fmt.Errorf("columns differ: expected %s, got %s",
+			strings.Join(columns, ", "), strings.Join(r.Columns(), ", "))
+	}
+	order := make([]int, len(columns))
+	for i, c := range columns {
+		idx, err := ColumnIndex(r.Columns(), c)
+		if err != nil {
+			return 0, err
+		}
+		order[i] = idx
+	}
+	userIdx, err := ColumnIndex(columns, schema.UserColumn)
+	if err != nil {
+		return 0, err
+	}
+	itemIdx, err := ColumnIndex(columns, schema.ItemColumn)
+	if err != nil {
+		return 0, err
+	}
+	timeIdx := -1
+	if schema.TimeColumn != "" {
+		if timeIdx, err = ColumnIndex(columns, schema.TimeColumn); err != nil {
+			return 0, err
+		}
+	}
+
+	var n int64
+	out := make([]string, len(columns))
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+		for i, idx := range order {
+			out[i] = row[idx]
+		}
+		user, item := out[userIdx], out[itemIdx]
+		if user == "" || item == "" {
+			report.Dropped["missing_id"]++
+			continue
+		}
+		when := ""
+		if timeIdx >= 0 {
+			t, err := ParseTime(out[timeIdx])
+			if err != nil {
+				report.Dropped["invalid_time"]++
+				continue
+			}
+			if !since.IsZero() && t.Before(since) {
+				report.Dropped["retention"]++
+				continue
+			}
+			// Compare instants, not strings, so the same time written in
+			// different layouts by different exports still matches.
+			when = strconv.FormatInt(t.UnixNano(), 10)
+		}
+		key := interactionHash(user, item, when)
+		if _, dup := seen[key]; dup {
+			report.Dropped["duplicate"]++
+			continue
+		}
+		seen[key] = struct{}{}
+		if err := cw.Write(out); err != nil {
+			return n, err
+		}
+		report.OutputRows++
+	}
+}
+
+// latestTime returns the newest valid timestamp in column.
+func latestTime(open func() (Reader, error), column string) (time.Time, error) {
+	r, err := open()
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer r.Close()
+	idx, err := ColumnIndex(r.Columns(), column)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var latest time.Time
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return latest, nil
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+		if t, err := ParseTime(row[idx]); err == nil && t.After(latest) {
+			latest = t
+		}
+	}
+}
+
+func interactionHash(user, item, when string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(user))
+	h.Write([]byte{0})
+	h.Write([]byte(item))
+	h.Write([]byte{0})
+	h.Write([]byte(when))
+	return h.Sum64()
+}
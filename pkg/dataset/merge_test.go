@@ -0,0 +1,90 @@
+package dataset
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func mergeOpener(t *testing.T, name, content string) func() (Reader, error) {
+	t.Helper()
+	path := writeFile(t, name, []byte(content))
+	return func() (Reader, error) { return Open(path, "") }
+}
+
+func TestMerge(t *testing.T) {
+	base := mergeOpener(t, "base.csv", "user_id,item_id,ts\n"+
+		"u1,i1,2024-01-01T00:00:00Z\n"+
+		"u1,i2,2024-02-01T00:00:00Z\n")
+	// Columns in a different order; one repeat written as epoch seconds.
+	added := mergeOpener(t, "new.csv", "ts,item_id,user_id\n"+
+		"1706745600,i2,u1\n"+
+		"2024-03-01T00:00:00Z,i3,u2\n"+
+		"2024-03-02T00:00:00Z,,u2\n"+
+		"2024-03-01T00:00:00Z,i3,u2\n")
+
+	var out bytes.Buffer
+	report, err := Merge([]func() (Reader, error){base, added}, MergeOptions{Schema: prepareSchema}, &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "user_id,item_id,ts\n" +
+		"u1,i1,2024-01-01T00:00:00Z\n" +
+		"u1,i2,2024-02-01T00:00:00Z\n" +
+		"u2,i3,2024-03-01T00:00:00Z\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+	if len(report.InputRows) != 2 || report.InputRows[0] != 2 || report.InputRows[1] != 4 {
+		t.Errorf("unexpected input rows %v", report.InputRows)
+	}
+	if report.OutputRows != 3 || report.Dropped["duplicate"] != 2 || report.Dropped["missing_id"] != 1 {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestMergeRetention(t *testing.T) {
+	base := mergeOpener(t, "base.csv", "user_id,item_id,ts\nu1,i1,2024-01-01\nu1,i2,2024-02-20\n")
+	added := mergeOpener(t, "new.csv", "user_id,item_id,ts\nu2,i1,2024-03-01\n")
+
+	var out bytes.Buffer
+	report, err := Merge([]func() (Reader, error){base, added},
+		MergeOptions{Schema: prepareSchema, Retention: 30 * 24 * time.Hour}, &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "user_id,item_id,ts\nu1,i2,2024-02-20\nu2,i1,2024-03-01\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+	if report.Dropped["retention"] != 1 || report.Since == nil || !report.Since.Equal(time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestMergeWithoutTimeColumn(t *testing.T) {
+	base := mergeOpener(t, "base.csv", "user_id,item_id\nu1,i1\n")
+	added := mergeOpener(t, "new.csv", "user_id,item_id\nu1,i1\nu1,i2\n")
+	schema := Schema{UserColumn: "user_id", ItemColumn: "item_id"}
+
+	var out bytes.Buffer
+	if _, err := Merge([]func() (Reader, error){base, added}, MergeOptions{Schema: schema}, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "user_id,item_id\nu1,i1\nu1,i2\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+	if _, err := Merge([]func() (Reader, error){base}, MergeOptions{Schema: schema, Retention: time.Hour}, &out); err == nil {
+		t.Error("expected error for retention without a time column")
+	}
+}
+
+func TestMergeColumnMismatch(t *testing.T) {
+	base := mergeOpener(t, "base.csv", "user_id,item_id\nu1,i1\n")
+	added := mergeOpener(t, "new.csv", "user_id,item_id,rating\nu1,i2,5\n")
+	schema := Schema{UserColumn: "user_id", ItemColumn: "item_id"}
+
+	var out bytes.Buffer
+	if _, err := Merge([]func() (Reader, error){base, added}, MergeOptions{Schema: schema}, &out); err == nil {
+		t.Error("expected error for differing columns")
+	}
+}
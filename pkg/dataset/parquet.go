@@ -0,0 +1,174 @@
+package dataset
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+const parquetBatchSize = 256
+
+// parquetReader reads the leaf columns of a flat Parquet file row by row,
+// holding at most one batch of rows in memory.
+type parquetReader struct {
+	file    *os.File
+	cleanup func()
+	reader  *parquet.Reader
+	columns []string
+	convert []func(parquet.Value) string
+	batch   []parquet.Row
+	pos     int
+	n       int
+	done    bool
+}
+
+func openParquet(path string) (Reader, error) {
+	f, cleanup, err := openSeekable(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+	pf, err := parquet.OpenFile(f, info.Size())
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	schema := pf.Schema()
+	paths := schema.Columns()
+	columns := make([]string, len(paths))
+	convert := make([]func(parquet.Value) string, len(paths))
+	for i, p := range paths {
+		columns[i] = strings.Join(p, ".")
+		leaf, _ := schema.Lookup(p...)
+		convert[i] = parquetConverter(leaf.Node)
+	}
+
+	return &parquetReader{
+		file:    f,
+		cleanup: cleanup,
+		reader:  parquet.NewReader(pf),
+		columns: columns,
+		convert: convert,
+		batch:   make([]parquet.Row, parquetBatchSize),
+	}, nil
+}
+
+// openSeekable returns a random-access handle on path. Parquet footers sit
+// at the end of the file, so a compressed Parquet file is first spooled to
+// a temporary file that is removed again by the returned cleanup function.
+func openSeekable(path string) (*os.File, func(), error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	stream, err := decompress(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if sc, ok := stream.(*stackedCloser); ok && len(sc.closers) == 1 {
+		return f, func() { f.Close() }, nil
+	}
+	defer stream.Close()
+
+	tmp, err := os.CreateTemp("", "recotem-*.parquet")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+	if _, err := io.Copy(tmp, stream); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return tmp, cleanup, nil
+}
+
+func (r *parquetReader) Columns() []string { return r.columns }
+
+func (r *parquetReader) Read() ([]string, error) {
+	if r.pos >= r.n {
+		if r.done {
+			return nil, io.EOF
+		}
+		n, err := r.reader.ReadRows(r.batch)
+		if err == io.EOF {
+			r.done = true
+		} else if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, io.EOF
+		}
+		r.pos, r.n = 0, n
+	}
+
+	row := make([]string, len(r.columns))
+	for _, v := range r.batch[r.pos] {
+		col := v.Column()
+		if col < 0 || col >= len(row) || v.IsNull() || row[col] != "" {
+			continue
+		}
+		row[col] = r.convert[col](v)
+	}
+	r.pos++
+	return row, nil
+}
+
+func (r *parquetReader) Close() error {
+	err := r.reader.Close()
+	r.cleanup()
+	return err
+}
+
+// parquetConverter picks a string rendering for a leaf column, turning
+// timestamp and date logical types into RFC 3339 so they can be used as a
+// project's time column.
+func parquetConverter(node parquet.Node) func(parquet.Value) string {
+	if node == nil {
+		return parquet.Value.String
+	}
+	lt := node.Type().LogicalType()
+	if lt == nil {
+		return parquet.Value.String
+	}
+	switch t := lt.Value.(type) {
+	case *format.TimestampType:
+		var unit time.Duration
+		switch t.Unit.Value.(type) {
+		case *format.MilliSeconds:
+			unit = time.Millisecond
+		case *format.MicroSeconds:
+			unit = time.Microsecond
+		default:
+			unit = time.Nanosecond
+		}
+		return func(v parquet.Value) string {
+			return time.Unix(0, v.Int64()*int64(unit)).UTC().Format(time.RFC3339)
+		}
+	case *format.DateType:
+		return func(v parquet.Value) string {
+			return time.Unix(int64(v.Int32())*86400, 0).UTC().Format(time.DateOnly)
+		}
+	case *format.IntType:
+		if !t.IsSigned && t.BitWidth <= 32 {
+			return func(v parquet.Value) string {
+				return strconv.FormatUint(uint64(uint32(v.Int32())), 10)
+			}
+		}
+	}
+	return parquet.Value.String
+}
@@ -0,0 +1,293 @@
+package dataset
+
+import (
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultMaxIterations = 50
+
+// PrepareOptions describes the filters applied by Prepare, in the order
+// they are evaluated for each row.
+type PrepareOptions struct {
+	Schema Schema
+	// Since and Until bound the time column (inclusive, exclusive).
+	Since time.Time
+	Until time.Time
+	// Last keeps only rows within this duration of the newest timestamp.
+	Last time.Duration
+	// Dedup drops repeated (user, item) pairs, keeping the first occurrence.
+	Dedup bool
+	// SampleUsers keeps this fraction of users, chosen deterministically
+	// from Seed. Zero or one keeps every user.
+	SampleUsers float64
+	Seed        int64
+	// MinUserInteractions and MinItemInteractions are the k-core thresholds.
+	MinUserInteractions int
+	MinItemInteractions int
+	MaxIterations       int
+}
+
+// PrepareReport summarizes what a prepared dataset kept and dropped.
+type PrepareReport struct {
+	InputRows  int64            `json:"input_rows" yaml:"input_rows"`
+	OutputRows int64            `json:"output_rows" yaml:"output_rows"`
+	Users      int              `json:"users" yaml:"users"`
+	Items      int              `json:"items" yaml:"items"`
+	Iterations int              `json:"kcore_iterations" yaml:"kcore_iterations"`
+	Dropped    map[string]int64 `json:"dropped_rows" yaml:"dropped_rows"`
+	Since      *time.Time       `json:"since,omitempty" yaml:"since,omitempty"`
+	Until      *time.Time       `json:"until,omitempty" yaml:"until,omitempty"`
+}
+
+// Prepared is the result of the counting passes of Prepare. Its Write
+// method performs the final pass that emits the surviving rows.
+type Prepared struct {
+	Report PrepareReport
+
+	open         func() (Reader, error)
+	opts         PrepareOptions
+	since, until time.Time
+	removedUsers map[string]bool
+	removedItems map[string]bool
+}
+
+// Prepare filters an interaction file without holding its rows in memory.
+// open is called once per pass: iterative k-core filtering re-reads the
+// input until no more users or items fall below the thresholds.
+func Prepare(open func() (Reader, error), opts PrepareOptions) (*Prepared, error) {
+	if opts.SampleUsers < 0 || opts.SampleUsers > 1 {
+		return nil, fmt.Errorf("sample ratio must be between 0 and 1, got %g", opts.SampleUsers)
+	}
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = defaultMaxIterations
+	}
+	needsTime := !opts.Since.IsZero() || !opts.Until.IsZero() || opts.Last > 0
+	if needsTime && opts.Schema.TimeColumn == "" {
+		return nil, fmt.Errorf("time filters require a time column")
+	}
+
+	p := &Prepared{
+		open:         open,
+		opts:         opts,
+		since:        opts.Since,
+		until:        opts.Until,
+		removedUsers: map[string]bool{},
+		removedItems: map[string]bool{},
+	}
+
+	if opts.Last > 0 {
+		latest, err := latestTime(open, opts.Schema.TimeColumn)
+		if err != nil {
+			return nil, err
+		}
+		if from := latest.Add(-opts.Last); from.After(p.since) {
+			p.since = from
+		}
+	}
+	if !p.since.IsZero() {
+		p.Report.Since = &p.since
+	}
+	if !p.until.IsZero() {
+		p.Report.Until = &p.until
+	}
+
+	iterations := 0
+	for {
+		users := map[string]int{}
+		items := map[string]int{}
+		report, err := p.pass(func(user, item string, _ []string) error {
+			users[user]++
+			items[item]++
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		p.Report = report
+		p.Report.Users = len(users)
+		p.Report.Items = len(items)
+		p.Report.Iterations = iterations
+
+		if opts.MinUserInteractions <= 1 && opts.MinItemInteractions <= 1 {
+			break
+		}
+		iterations++
+		p.Report.Iterations = iterations
+		removed := false
+		for u, c := range users {
+			if c < opts.MinUserInteractions {
+				p.removedUsers[u] = true
+				removed = true
+			}
+		}
+		for i, c := range items {
+			if c < opts.MinItemInteractions {
+				p.removedItems[i] = true
+				removed = true
+			}
+		}
+		if !removed {
+			break
+		}
+		if iterations >= opts.MaxIterations {
+			return nil, fmt.Errorf("k-core filtering did not converge after %d iterations", opts.MaxIterations)
+		}
+	}
+	return p, nil
+}
+
+// Write emits the surviving rows as CSV with the original header.
+func (p *Prepared) Write(w io.Writer) error {
+	r, err := p.open()
+	if err != nil {
+		return err
+	}
+	columns := r.Columns()
+	r.Close()
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	if _, err := p.pass(func(_, _ string, row []string) error {
+		return cw.Write(row)
+	}); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// pass reads the input once and calls keep for every row that survives the
+// filters, returning counts of the rows read and dropped.
+func (p *Prepared) pass(keep func(user, item string, row []string) error) (PrepareReport, error) {
+	report := PrepareReport{Dropped: map[string]int64{}, Since: p.Report.Since, Until: p.Report.Until}
+	r, err := p.open()
+	if err != nil {
+		return report, err
+	}
+	defer r.Close()
+
+	schema := p.opts.Schema
+	userIdx, err := ColumnIndex(r.Columns(), schema.UserColumn)
+	if err != nil {
+		return report, err
+	}
+	itemIdx, err := ColumnIndex(r.Columns(), schema.ItemColumn)
+	if err != nil {
+		return report, err
+	}
+	timeIdx := -1
+	if !p.since.IsZero() || !p.until.IsZero() {
+		if timeIdx, err = ColumnIndex(r.Columns(), schema.TimeColumn); err != nil {
+			return report, err
+		}
+	}
+	var seen map[uint64]struct{}
+	if p.opts.Dedup {
+		seen = map[uint64]struct{}{}
+	}
+
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+		report.InputRows++
+		user, item := row[userIdx], row[itemIdx]
+
+		if reason := p.reject(user, item, row, timeIdx, seen); reason != "" {
+			report.Dropped[reason]++
+			continue
+		}
+		if err := keep(user, item, row); err != nil {
+			return report, err
+		}
+		report.OutputRows++
+	}
+	return report, nil
+}
+
+// reject returns the name of the first filter that drops the row, or "".
+func (p *Prepared) reject(user, item string, row []string, timeIdx int, seen map[uint64]struct{}) string {
+	if user == "" || item == "" {
+		return "missing_id"
+	}
+	if timeIdx >= 0 {
+		t, err := ParseTime(row[timeIdx])
+		if err != nil {
+			return "invalid_time"
+		}
+		if (!p.since.IsZero() && t.Before(p.since)) || (!p.until.IsZero() && !t.Before(p.until)) {
+			return "time_window"
+		}
+	}
+	if seen != nil {
+		key := pairHash(user, item)
+		if _, dup := seen[key]; dup {
+			return "duplicate"
+		}
+		seen[key] = struct{}{}
+	}
+	if !keepUser(user, p.opts.SampleUsers, p.opts.Seed) {
+		return "sampling"
+	}
+	if p.removedUsers[user] || p.removedItems[item] {
+		return "kcore"
+	}
+	return ""
+}
+
+// keepUser decides deterministically whether a user is part of the sample,
+// so every pass (and every rerun with the same seed) picks the same users.
+func keepUser(user string, ratio float64, seed int64) bool {
+	if ratio <= 0 || ratio >= 1 {
+		return true
+	}
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(seed))
+	h.Write(buf[:])
+	h.Write([]byte(user))
+	return float64(h.Sum64())/math.MaxUint64 < ratio
+}
+
+func pairHash(user, item string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(user))
+	h.Write([]byte{0})
+	h.Write([]byte(item))
+	return h.Sum64()
+}
+
+// ParseWindow parses a relative time window such as "90d", "12w" or "36h".
+func ParseWindow(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	unit := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if d, ok := unit[s[len(s)-1]]; ok {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid window %q", s)
+		}
+		return time.Duration(n) * d, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid window %q (expected e.g. 90d, 12w or 36h)", s)
+	}
+	return d, nil
+}
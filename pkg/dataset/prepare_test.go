@@ -0,0 +1,148 @@
+package dataset
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+const prepareCSV = "user_id,item_id,ts\n" +
+	"u1,i1,2024-01-01\n" +
+	"u1,i2,2024-01-02\n" +
+	"u1,i2,2024-01-03\n" +
+	"u2,i1,2024-03-01\n" +
+	"u2,i2,2024-03-02\n" +
+	"u3,i3,2024-03-03\n" +
+	"u4,i1,2024-03-04\n"
+
+func prepareOpener(t *testing.T) func() (Reader, error) {
+	t.Helper()
+	path := writeFile(t, "data.csv", []byte(prepareCSV))
+	return func() (Reader, error) { return Open(path, "") }
+}
+
+var prepareSchema = Schema{UserColumn: "user_id", ItemColumn: "item_id", TimeColumn: "ts"}
+
+func TestPrepareKCore(t *testing.T) {
+	p, err := Prepare(prepareOpener(t), PrepareOptions{
+		Schema:              prepareSchema,
+		Dedup:               true,
+		MinUserInteractions: 2,
+		MinItemInteractions: 2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := p.Write(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "user_id,item_id,ts\nu1,i1,2024-01-01\nu1,i2,2024-01-02\nu2,i1,2024-03-01\nu2,i2,2024-03-02\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+	if p.Report.OutputRows != 4 || p.Report.Users != 2 || p.Report.Items != 2 {
+		t.Errorf("unexpected report: %+v", p.Report)
+	}
+	if p.Report.Dropped["duplicate"] != 1 || p.Report.Dropped["kcore"] != 2 {
+		t.Errorf("unexpected dropped counts: %v", p.Report.Dropped)
+	}
+}
+
+func TestPrepareTimeWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     PrepareOptions
+		expected int64
+	}{
+		{"since", PrepareOptions{Since: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)}, 3},
+		{"until", PrepareOptions{Until: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)}, 4},
+		{"last", PrepareOptions{Last: 48 * time.Hour}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Schema = prepareSchema
+			p, err := Prepare(prepareOpener(t), tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.Report.OutputRows != tt.expected {
+				t.Errorf("expected %d rows, got %d", tt.expected, p.Report.OutputRows)
+			}
+		})
+	}
+}
+
+func TestPrepareTimeWindowRequiresTimeColumn(t *testing.T) {
+	_, err := Prepare(prepareOpener(t), PrepareOptions{
+		Schema: Schema{UserColumn: "user_id", ItemColumn: "item_id"},
+		Last:   time.Hour,
+	})
+	if err == nil {
+		t.Error("expected error without time column, got nil")
+	}
+}
+
+func TestPrepareSamplingIsDeterministic(t *testing.T) {
+	run := func(seed int64) string {
+		p, err := Prepare(prepareOpener(t), PrepareOptions{Schema: prepareSchema, SampleUsers: 0.5, Seed: seed})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var out bytes.Buffer
+		if err := p.Write(&out); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return out.String()
+	}
+
+	first, second := run(42), run(42)
+	if first != second {
+		t.Errorf("expected identical samples for the same seed, got %q and %q", first, second)
+	}
+	if !strings.HasPrefix(first, "user_id,item_id,ts\n") {
+		t.Errorf("expected header to be preserved, got %q", first)
+	}
+}
+
+func TestPrepareInvalidSampleRatio(t *testing.T) {
+	if _, err := Prepare(prepareOpener(t), PrepareOptions{Schema: prepareSchema, SampleUsers: 1.5}); err == nil {
+		t.Error("expected error for sample ratio > 1, got nil")
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  time.Duration
+		expectErr bool
+	}{
+		{"", 0, false},
+		{"90d", 90 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"0d", 0, true},
+		{"ten days", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseWindow(tt.input)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Reader yields the rows of a tabular file one at a time.
+// Values are returned in the order given by Columns; Read returns io.EOF
+// once every row has been consumed.
+type Reader interface {
+	Columns() []string
+	Read() ([]string, error)
+	Close() error
+}
+
+// Open opens path as a row reader. When format is empty it is detected from
+// the filename. Gzip and zstd compressed inputs are decompressed on the fly.
+func Open(path string, format Format) (Reader, error) {
+	if format == "" {
+		format = DetectFormat(path)
+	}
+	if format == FormatParquet {
+		return openParquet(path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := decompress(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	var r Reader
+	switch format {
+	case FormatCSV:
+		r, err = newDelimitedReader(stream, ',')
+	case FormatTSV:
+		r, err = newDelimitedReader(stream, '\t')
+	case FormatJSONL:
+		r, err = newJSONLReader(stream)
+	default:
+		err = fmt.Errorf("unsupported input format %q", format)
+	}
+	if err != nil {
+		stream.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// decompress wraps f in a decompressor when its leading bytes carry a gzip
+// or zstd magic number. Closing the result also closes f.
+func decompress(f *os.File) (io.ReadCloser, error) {
+	br := bufio.NewReader(f)
+	head, _ := br.Peek(len(zstdMagic))
+
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return &stackedCloser{Reader: gz, closers: []io.Closer{gz, f}}, nil
+	case bytes.HasPrefix(head, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return &stackedCloser{Reader: zr, closers: []io.Closer{zr.IOReadCloser(), f}}, nil
+	}
+	return &stackedCloser{Reader: br, closers: []io.Closer{f}}, nil
+}
+
+type stackedCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (s *stackedCloser) Close() error {
+	var first error
+	for _, c := range s.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+type delimitedReader struct {
+	src     io.ReadCloser
+	csv     *csv.Reader
+	columns []string
+}
+
+func newDelimitedReader(src io.ReadCloser, comma rune) (*delimitedReader, error) {
+	r := csv.NewReader(src)
+	r.Comma = comma
+	if comma == '\t' {
+		r.LazyQuotes = true
+	}
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("missing header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	return &delimitedReader{src: src, csv: r, columns: header}, nil
+}
+
+func (r *delimitedReader) Columns() []string { return r.columns }
+
+func (r *delimitedReader) Read() ([]string, error) {
+	return r.csv.Read()
+}
+
+func (r *delimitedReader) Close() error { return r.src.Close() }
+
+// WriteCSV streams every row of r to w as CSV, header first.
+func WriteCSV(w io.Writer, r Reader) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(r.Columns()); err != nil {
+		return err
+	}
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,167 @@
+package dataset
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+const sampleCSV = "user_id,item_id,ts\nu1,i1,2024-01-01\nu2,i2,2024-01-02\n"
+
+var sampleRows = [][]string{
+	{"u1", "i1", "2024-01-01"},
+	{"u2", "i2", "2024-01-02"},
+}
+
+func TestOpenCSVVariants(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content func(t *testing.T) []byte
+	}{
+		{"plain csv", "data.csv", func(t *testing.T) []byte { return []byte(sampleCSV) }},
+		{"gzip csv", "data.csv.gz", func(t *testing.T) []byte { return gzipBytes(t, []byte(sampleCSV)) }},
+		{"zstd csv", "data.csv.zst", func(t *testing.T) []byte { return zstdBytes(t, []byte(sampleCSV)) }},
+		{"tsv", "data.tsv", func(t *testing.T) []byte {
+			return []byte(strings.ReplaceAll(sampleCSV, ",", "\t"))
+		}},
+		{"csv with BOM", "bom.csv", func(t *testing.T) []byte { return []byte("\ufeff" + sampleCSV) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, tt.file, tt.content(t))
+			r, err := Open(path, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer r.Close()
+
+			if !reflect.DeepEqual(r.Columns(), []string{"user_id", "item_id", "ts"}) {
+				t.Errorf("unexpected columns: %v", r.Columns())
+			}
+			if rows := readAll(t, r); !reflect.DeepEqual(rows, sampleRows) {
+				t.Errorf("unexpected rows: %v", rows)
+			}
+		})
+	}
+}
+
+func TestOpenSniffsCompressionWithoutExtension(t *testing.T) {
+	path := writeFile(t, "data.csv", gzipBytes(t, []byte(sampleCSV)))
+	r, err := Open(path, FormatCSV)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer r.Close()
+
+	if rows := readAll(t, r); len(rows) != 2 {
+		t.Errorf("expected 2 rows, got %d", len(rows))
+	}
+}
+
+func TestOpenEmptyFile(t *testing.T) {
+	path := writeFile(t, "empty.csv", nil)
+	if _, err := Open(path, ""); err == nil {
+		t.Error("expected error for empty file, got nil")
+	}
+}
+
+func TestOpenParquet(t *testing.T) {
+	type event struct {
+		UserID string `parquet:"user_id"`
+		ItemID string `parquet:"item_id"`
+		Rating int64  `parquet:"rating"`
+	}
+	var buf bytes.Buffer
+	w := parquet.NewGenericWriter[event](&buf)
+	if _, err := w.Write([]event{{"u1", "i1", 5}, {"u2", "i2", 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"events.parquet", "events.parquet.gz"} {
+		t.Run(name, func(t *testing.T) {
+			content := buf.Bytes()
+			if strings.HasSuffix(name, ".gz") {
+				content = gzipBytes(t, content)
+			}
+			path := writeFile(t, name, content)
+			r, err := Open(path, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer r.Close()
+
+			if !reflect.DeepEqual(r.Columns(), []string{"user_id", "item_id", "rating"}) {
+				t.Errorf("unexpected columns: %v", r.Columns())
+			}
+			expected := [][]string{{"u1", "i1", "5"}, {"u2", "i2", "3"}}
+			if rows := readAll(t, r); !reflect.DeepEqual(rows, expected) {
+				t.Errorf("unexpected rows: %v", rows)
+			}
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	path := writeFile(t, "data.tsv", []byte("a\tb\nx,1\t\"q\"\n"))
+	r, err := Open(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer r.Close()
+
+	var out bytes.Buffer
+	if err := WriteCSV(&out, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "a,b\n\"x,1\",q\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestOpenAsCSVPassthrough(t *testing.T) {
+	path := writeFile(t, "data.csv", []byte(sampleCSV))
+	name, rc, err := OpenAsCSV(path, ConvertOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer rc.Close()
+
+	body, _ := io.ReadAll(rc)
+	if name != "data.csv" || string(body) != sampleCSV {
+		t.Errorf("expected passthrough of data.csv, got %q %q", name, string(body))
+	}
+}
+
+func TestOpenAsCSVConverts(t *testing.T) {
+	jsonl := `{"uid":"u1","iid":"i1"}` + "\n" + `{"uid":"u2","iid":"i2"}` + "\n"
+	path := writeFile(t, "events.jsonl.gz", gzipBytes(t, []byte(jsonl)))
+	name, rc, err := OpenAsCSV(path, ConvertOptions{
+		Columns: map[string]string{"uid": "user_id", "iid": "item_id"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer rc.Close()
+
+	body, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "events.csv" {
+		t.Errorf("expected name events.csv, got %q", name)
+	}
+	expected := "user_id,item_id\nu1,i1\nu2,i2\n"
+	if string(body) != expected {
+		t.Errorf("expected %q, got %q", expected, string(body))
+	}
+}
@@ -0,0 +1,245 @@
+package dataset
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// headItemRatio is the fraction of most popular items treated as the "head"
+// when computing the long-tail share.
+const headItemRatio = 0.2
+
+// Schema names the interaction columns of a data file, usually taken from
+// the project the file belongs to.
+type Schema struct {
+	UserColumn string
+	ItemColumn string
+	TimeColumn string
+}
+
+// Stats summarizes an interaction file.
+type Stats struct {
+	Interactions int64          `json:"interactions" yaml:"interactions"`
+	Users        int            `json:"users" yaml:"users"`
+	Items        int            `json:"items" yaml:"items"`
+	Sparsity     float64        `json:"sparsity" yaml:"sparsity"`
+	PerUser      Distribution   `json:"interactions_per_user" yaml:"interactions_per_user"`
+	PerItem      Distribution   `json:"interactions_per_item" yaml:"interactions_per_item"`
+	LongTail     LongTail       `json:"long_tail" yaml:"long_tail"`
+	Time         *TimeSummary   `json:"time,omitempty" yaml:"time,omitempty"`
+	Skipped      map[string]int `json:"skipped_rows,omitempty" yaml:"skipped_rows,omitempty"`
+}
+
+// Distribution describes how interactions are spread over users or items.
+type Distribution struct {
+	Min       int        `json:"min" yaml:"min"`
+	Max       int        `json:"max" yaml:"max"`
+	Mean      float64    `json:"mean" yaml:"mean"`
+	Quantiles []Quantile `json:"quantiles" yaml:"quantiles"`
+	Histogram []Bucket   `json:"histogram" yaml:"histogram"`
+}
+
+// Quantile is the interaction count at a given quantile.
+type Quantile struct {
+	Q     float64 `json:"q" yaml:"q"`
+	Value int     `json:"value" yaml:"value"`
+}
+
+// Bucket counts the users or items whose interaction count lies in [Lower, Upper].
+type Bucket struct {
+	Lower int `json:"lower" yaml:"lower"`
+	Upper int `json:"upper" yaml:"upper"`
+	Count int `json:"count" yaml:"count"`
+}
+
+// LongTail reports how concentrated interactions are on popular items.
+type LongTail struct {
+	HeadItems     int     `json:"head_items" yaml:"head_items"`
+	HeadShare     float64 `json:"head_share" yaml:"head_share"`
+	TailShare     float64 `json:"tail_share" yaml:"tail_share"`
+	ItemsFor80Pct int     `json:"items_for_80pct" yaml:"items_for_80pct"`
+}
+
+// TimeSummary is the time range and daily volume of the time column.
+type TimeSummary struct {
+	Start time.Time    `json:"start" yaml:"start"`
+	End   time.Time    `json:"end" yaml:"end"`
+	Days  int          `json:"days" yaml:"days"`
+	Daily []DailyCount `json:"daily" yaml:"daily"`
+}
+
+// DailyCount is the number of interactions on one UTC day.
+type DailyCount struct {
+	Date  string `json:"date" yaml:"date"`
+	Count int    `json:"count" yaml:"count"`
+}
+
+var statsQuantiles = []float64{0.25, 0.5, 0.75, 0.9, 0.99}
+
+// ColumnIndex returns the position of name in columns.
+func ColumnIndex(columns []string, name string) (int, error) {
+	for i, c := range columns {
+		if c == name {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("column %q not found (available: %s)", name, strings.Join(columns, ", "))
+}
+
+// ComputeStats reads every row of r and summarizes it. Only per-user and
+// per-item counters are kept in memory, never the rows themselves.
+func ComputeStats(r Reader, schema Schema) (*Stats, error) {
+	userIdx, err := ColumnIndex(r.Columns(), schema.UserColumn)
+	if err != nil {
+		return nil, err
+	}
+	itemIdx, err := ColumnIndex(r.Columns(), schema.ItemColumn)
+	if err != nil {
+		return nil, err
+	}
+	timeIdx := -1
+	if schema.TimeColumn != "" {
+		if timeIdx, err = ColumnIndex(r.Columns(), schema.TimeColumn); err != nil {
+			return nil, err
+		}
+	}
+
+	stats := &Stats{Skipped: map[string]int{}}
+	users := map[string]int{}
+	items := map[string]int{}
+	daily := map[string]int{}
+	var start, end time.Time
+
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		user, item := row[userIdx], row[itemIdx]
+		if user == "" || item == "" {
+			stats.Skipped["missing_id"]++
+			continue
+		}
+		if timeIdx >= 0 {
+			t, err := ParseTime(row[timeIdx])
+			if err != nil {
+				stats.Skipped["invalid_time"]++
+				continue
+			}
+			if start.IsZero() || t.Before(start) {
+				start = t
+			}
+			if end.IsZero() || t.After(end) {
+				end = t
+			}
+			daily[t.Format(time.DateOnly)]++
+		}
+		users[user]++
+		items[item]++
+		stats.Interactions++
+	}
+
+	stats.Users = len(users)
+	stats.Items = len(items)
+	if stats.Users > 0 && stats.Items > 0 {
+		stats.Sparsity = 1 - float64(stats.Interactions)/(float64(stats.Users)*float64(stats.Items))
+	}
+	userCounts := sortedCounts(users)
+	itemCounts := sortedCounts(items)
+	stats.PerUser = distribution(userCounts)
+	stats.PerItem = distribution(itemCounts)
+	stats.LongTail = longTail(itemCounts, stats.Interactions)
+
+	if timeIdx >= 0 && stats.Interactions > 0 {
+		stats.Time = &TimeSummary{Start: start, End: end, Daily: dailyCounts(daily)}
+		stats.Time.Days = len(stats.Time.Daily)
+	}
+	if len(stats.Skipped) == 0 {
+		stats.Skipped = nil
+	}
+	return stats, nil
+}
+
+// sortedCounts returns the values of m in ascending order.
+func sortedCounts(m map[string]int) []int {
+	counts := make([]int, 0, len(m))
+	for _, c := range m {
+		counts = append(counts, c)
+	}
+	sort.Ints(counts)
+	return counts
+}
+
+// distribution summarizes ascending counts with nearest-rank quantiles and
+// a power-of-two histogram (1, 2-3, 4-7, ...), which suits the heavy-tailed
+// shape of interaction data.
+func distribution(counts []int) Distribution {
+	d := Distribution{Quantiles: []Quantile{}, Histogram: []Bucket{}}
+	if len(counts) == 0 {
+		return d
+	}
+	d.Min = counts[0]
+	d.Max = counts[len(counts)-1]
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	d.Mean = float64(total) / float64(len(counts))
+	for _, q := range statsQuantiles {
+		rank := int(math.Ceil(q*float64(len(counts)))) - 1
+		rank = max(0, min(rank, len(counts)-1))
+		d.Quantiles = append(d.Quantiles, Quantile{Q: q, Value: counts[rank]})
+	}
+
+	i := 0
+	for lower := 1; i < len(counts); lower *= 2 {
+		upper := lower*2 - 1
+		b := Bucket{Lower: lower, Upper: upper}
+		for i < len(counts) && counts[i] <= upper {
+			b.Count++
+			i++
+		}
+		d.Histogram = append(d.Histogram, b)
+	}
+	return d
+}
+
+func longTail(ascending []int, total int64) LongTail {
+	lt := LongTail{}
+	if len(ascending) == 0 || total == 0 {
+		return lt
+	}
+	lt.HeadItems = max(1, int(math.Ceil(headItemRatio*float64(len(ascending)))))
+	var head, covered int64
+	for rank := 0; rank < len(ascending); rank++ {
+		c := int64(ascending[len(ascending)-1-rank])
+		if rank < lt.HeadItems {
+			head += c
+		}
+		if lt.ItemsFor80Pct == 0 {
+			covered += c
+			if float64(covered) >= 0.8*float64(total) {
+				lt.ItemsFor80Pct = rank + 1
+			}
+		}
+	}
+	lt.HeadShare = float64(head) / float64(total)
+	lt.TailShare = 1 - lt.HeadShare
+	return lt
+}
+
+func dailyCounts(m map[string]int) []DailyCount {
+	days := make([]DailyCount, 0, len(m))
+	for d, c := range m {
+		days = append(days, DailyCount{Date: d, Count: c})
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days
+}
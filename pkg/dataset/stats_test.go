@@ -0,0 +1,98 @@
+package dataset
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComputeStats(t *testing.T) {
+	content := "user,item,ts\n" +
+		"u1,i1,2024-01-01\n" +
+		"u1,i2,2024-01-01\n" +
+		"u1,i3,2024-01-02\n" +
+		"u2,i1,2024-01-02\n" +
+		"u3,i1,2024-01-03\n" +
+		",i9,2024-01-03\n" +
+		"u4,i2,not-a-date\n"
+	path := writeFile(t, "data.csv", []byte(content))
+	r, err := Open(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer r.Close()
+
+	stats, err := ComputeStats(r, Schema{UserColumn: "user", ItemColumn: "item", TimeColumn: "ts"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stats.Interactions != 5 || stats.Users != 3 || stats.Items != 3 {
+		t.Errorf("unexpected counts: interactions=%d users=%d items=%d", stats.Interactions, stats.Users, stats.Items)
+	}
+	if want := 1 - 5.0/9.0; stats.Sparsity != want {
+		t.Errorf("expected sparsity %v, got %v", want, stats.Sparsity)
+	}
+	if stats.Skipped["missing_id"] != 1 || stats.Skipped["invalid_time"] != 1 {
+		t.Errorf("unexpected skipped rows: %v", stats.Skipped)
+	}
+	if stats.PerUser.Min != 1 || stats.PerUser.Max != 3 {
+		t.Errorf("unexpected per-user range: %+v", stats.PerUser)
+	}
+	expectedHist := []Bucket{{1, 1, 2}, {2, 3, 1}}
+	if !reflect.DeepEqual(stats.PerUser.Histogram, expectedHist) {
+		t.Errorf("expected histogram %v, got %v", expectedHist, stats.PerUser.Histogram)
+	}
+	if stats.LongTail.HeadItems != 1 || stats.LongTail.HeadShare != 0.6 {
+		t.Errorf("unexpected long tail: %+v", stats.LongTail)
+	}
+	if stats.Time == nil || stats.Time.Days != 3 {
+		t.Fatalf("expected 3 active days, got %+v", stats.Time)
+	}
+	if stats.Time.Daily[0] != (DailyCount{"2024-01-01", 2}) {
+		t.Errorf("unexpected first day: %+v", stats.Time.Daily[0])
+	}
+}
+
+func TestComputeStatsWithoutTimeColumn(t *testing.T) {
+	path := writeFile(t, "data.csv", []byte(sampleCSV))
+	r, err := Open(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer r.Close()
+
+	stats, err := ComputeStats(r, Schema{UserColumn: "user_id", ItemColumn: "item_id"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Time != nil {
+		t.Errorf("expected no time summary, got %+v", stats.Time)
+	}
+	if stats.Skipped != nil {
+		t.Errorf("expected no skipped rows, got %v", stats.Skipped)
+	}
+}
+
+func TestComputeStatsMissingColumn(t *testing.T) {
+	path := writeFile(t, "data.csv", []byte(sampleCSV))
+	r, err := Open(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer r.Close()
+
+	if _, err := ComputeStats(r, Schema{UserColumn: "user", ItemColumn: "item_id"}); err == nil {
+		t.Error("expected error for missing column, got nil")
+	}
+}
+
+func TestDistributionQuantiles(t *testing.T) {
+	d := distribution([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	expected := []Quantile{{0.25, 3}, {0.5, 5}, {0.75, 8}, {0.9, 9}, {0.99, 10}}
+	if !reflect.DeepEqual(d.Quantiles, expected) {
+		t.Errorf("expected %v, got %v", expected, d.Quantiles)
+	}
+	if d.Mean != 5.5 {
+		t.Errorf("expected mean 5.5, got %v", d.Mean)
+	}
+}
@@ -0,0 +1,42 @@
+package dataset
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  time.Time
+		expectErr bool
+	}{
+		{"2024-01-02T03:04:05Z", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"2024-01-02T03:04:05+09:00", time.Date(2024, 1, 1, 18, 4, 5, 0, time.UTC), false},
+		{"2024-01-02 03:04:05", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), false},
+		{"2024/01/02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), false},
+		{"1704164645", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"1704164645000", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseTime(tt.input)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
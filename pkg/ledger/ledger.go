@@ -0,0 +1,173 @@
+// Package ledger records which local files were uploaded to a server, keyed
+// by their SHA-256, so identical files are not uploaded twice and the
+// lineage of dataset versions can be shown.
+package ledger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Kind is the type of resource an upload created.
+type Kind string
+
+const (
+	KindTrainingData Kind = "training_data"
+	KindItemMetaData Kind = "item_meta_data"
+)
+
+// Entry is one upload.
+type Entry struct {
+	Kind    Kind   `json:"kind" yaml:"kind"`
+	ID      int    `json:"id" yaml:"id"`
+	Project int    `json:"project" yaml:"project"`
+	SHA256  string `json:"sha256" yaml:"sha256"`
+	// Source is the local file the upload was made from, if any.
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+	// Options describes conversions applied to Source before upload, since
+	// the same file uploaded with different options is a different dataset.
+	Options string `json:"options,omitempty" yaml:"options,omitempty"`
+	// Basename and Filesize are what the server reported after the upload;
+	// they are compared with the server later to detect reused IDs.
+	Basename string `json:"basename" yaml:"basename"`
+	Filesize int    `json:"filesize" yaml:"filesize"`
+	// Parent is the training data a derived upload was built from.
+	Parent     *int      `json:"parent,omitempty" yaml:"parent,omitempty"`
+	UploadedAt time.Time `json:"uploaded_at" yaml:"uploaded_at"`
+}
+
+// Ledger is the set of uploads made to one server.
+type Ledger struct {
+	Entries []Entry `json:"entries"`
+
+	path string
+}
+
+// Load reads the ledger at path. A missing file yields an empty ledger.
+func Load(path string) (*Ledger, error) {
+	l := &Ledger{path: path}
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Save writes the ledger, replacing the previous file atomically.
+func (l *Ledger) Save() error {
+	out, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(l.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".ledger-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), l.path)
+}
+
+// Add records an upload.
+func (l *Ledger) Add(e Entry) {
+	l.Entries = append(l.Entries, e)
+}
+
+// Remove drops the entry for a resource, e.g. after it was found to be
+// deleted on the server.
+func (l *Ledger) Remove(kind Kind, id int) {
+	kept := l.Entries[:0]
+	for _, e := range l.Entries {
+		if e.Kind != kind || e.ID != id {
+			kept = append(kept, e)
+		}
+	}
+	l.Entries = kept
+}
+
+// Find returns uploads of the same content with the same options to a
+// project, newest first.
+func (l *Ledger) Find(kind Kind, project int, sha, options string) []Entry {
+	var found []Entry
+	for _, e := range l.Versions(kind, project) {
+		if e.SHA256 == sha && e.Options == options {
+			found = append([]Entry{e}, found...)
+		}
+	}
+	return found
+}
+
+// Versions returns the uploads to a project, oldest first.
+func (l *Ledger) Versions(kind Kind, project int) []Entry {
+	var versions []Entry
+	for _, e := range l.Entries {
+		if e.Kind == kind && e.Project == project {
+			versions = append(versions, e)
+		}
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].UploadedAt.Before(versions[j].UploadedAt)
+	})
+	return versions
+}
+
+// HashFile returns the hex SHA-256 of a file.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashingReader computes the SHA-256 of everything read through it, for
+// uploads generated on the fly.
+type HashingReader struct {
+	r io.Reader
+	h hash.Hash
+}
+
+// NewHashingReader wraps r.
+func NewHashingReader(r io.Reader) *HashingReader {
+	return &HashingReader{r: r, h: sha256.New()}
+}
+
+func (h *HashingReader) Read(p []byte) (int, error) {
+	n, err := h.r.Read(p)
+	h.h.Write(p[:n])
+	return n, err
+}
+
+// Sum returns the hex SHA-256 of the bytes read so far.
+func (h *HashingReader) Sum() string {
+	return hex.EncodeToString(h.h.Sum(nil))
+}
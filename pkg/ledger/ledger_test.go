@@ -0,0 +1,91 @@
+package ledger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingIsEmpty(t *testing.T) {
+	l, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(l.Entries) != 0 {
+		t.Errorf("expected empty ledger, got %d entries", len(l.Entries))
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger", "server.json")
+	l, _ := Load(path)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	parent := 1
+	l.Add(Entry{Kind: KindTrainingData, ID: 2, Project: 1, SHA256: "bbb", UploadedAt: base.Add(time.Hour), Parent: &parent})
+	l.Add(Entry{Kind: KindTrainingData, ID: 1, Project: 1, SHA256: "aaa", UploadedAt: base})
+	l.Add(Entry{Kind: KindTrainingData, ID: 3, Project: 1, SHA256: "aaa", UploadedAt: base.Add(2 * time.Hour)})
+	l.Add(Entry{Kind: KindItemMetaData, ID: 1, Project: 1, SHA256: "aaa", UploadedAt: base})
+	l.Add(Entry{Kind: KindTrainingData, ID: 4, Project: 2, SHA256: "aaa", UploadedAt: base})
+	if err := l.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	versions := loaded.Versions(KindTrainingData, 1)
+	if len(versions) != 3 || versions[0].ID != 1 || versions[1].ID != 2 || versions[2].ID != 3 {
+		t.Errorf("unexpected versions %+v", versions)
+	}
+	if versions[1].Parent == nil || *versions[1].Parent != 1 {
+		t.Errorf("parent not preserved: %+v", versions[1])
+	}
+
+	found := loaded.Find(KindTrainingData, 1, "aaa", "")
+	if len(found) != 2 || found[0].ID != 3 || found[1].ID != 1 {
+		t.Errorf("Find should return newest first, got %+v", found)
+	}
+	if found := loaded.Find(KindTrainingData, 1, "aaa", "pseudonymize=hmac"); len(found) != 0 {
+		t.Errorf("Find should match options, got %+v", found)
+	}
+
+	loaded.Remove(KindTrainingData, 3)
+	if found := loaded.Find(KindTrainingData, 1, "aaa", ""); len(found) != 1 || found[0].ID != 1 {
+		t.Errorf("unexpected entries after Remove: %+v", found)
+	}
+	if len(loaded.Versions(KindItemMetaData, 1)) != 1 {
+		t.Error("Remove should only drop the given kind")
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.csv")
+	content := "user_id,item_id\nu1,i1\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(content))
+	want := hex.EncodeToString(sum[:])
+
+	got, err := HashFile(path)
+	if err != nil {
+		t.Fatalf("HashFile failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("HashFile = %s, want %s", got, want)
+	}
+
+	r := NewHashingReader(strings.NewReader(content))
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Sum() != want {
+		t.Errorf("HashingReader.Sum = %s, want %s", r.Sum(), want)
+	}
+}
@@ -0,0 +1,71 @@
+package manifest
+
+// Difference is a declared resource or field that does not match the server.
+type Difference struct {
+	Kind Kind   `json:"kind" yaml:"kind"`
+	Path string `json:"path" yaml:"path"`
+	ID   *int   `json:"id,omitempty" yaml:"id,omitempty"`
+	// Field is the type-qualified field, such as
+	// "RetrainingSchedule.cron_expression"; empty when the whole resource
+	// is missing or unmanaged.
+	Field    string `json:"field,omitempty" yaml:"field,omitempty"`
+	Live     any    `json:"live" yaml:"live"`
+	Declared any    `json:"declared" yaml:"declared"`
+	// Status is "changed", "missing" (declared but not on the server) or
+	// "unmanaged" (on the server but not declared).
+	Status string `json:"status" yaml:"status"`
+}
+
+const (
+	DriftChanged   = "changed"
+	DriftMissing   = "missing"
+	DriftUnmanaged = "unmanaged"
+)
+
+// TypeName returns the API type name of the kind.
+func (k Kind) TypeName() string {
+	switch k {
+	case KindSplitConfig:
+		return "SplitConfig"
+	case KindEvaluationConfig:
+		return "EvaluationConfig"
+	case KindProject:
+		return "Project"
+	case KindModelConfiguration:
+		return "ModelConfiguration"
+	case KindDeploymentSlot:
+		return "DeploymentSlot"
+	case KindRetrainingSchedule:
+		return "RetrainingSchedule"
+	case KindAbTest:
+		return "AbTest"
+	}
+	return string(k)
+}
+
+// Drift lists the differences in a plan: one per changed field, and one per
+// resource that would be created or, for a pruning plan, deleted.
+func Drift(p *Plan) []Difference {
+	diffs := []Difference{}
+	for _, c := range p.Changes {
+		switch c.Action {
+		case ActionCreate:
+			diffs = append(diffs, Difference{Kind: c.Kind, Path: c.Path(), Status: DriftMissing})
+		case ActionDelete:
+			diffs = append(diffs, Difference{Kind: c.Kind, Path: c.Path(), ID: c.ID, Status: DriftUnmanaged})
+		case ActionUpdate:
+			for _, f := range c.Fields {
+				diffs = append(diffs, Difference{
+					Kind:     c.Kind,
+					Path:     c.Path(),
+					ID:       c.ID,
+					Field:    c.Kind.TypeName() + "." + f.Field,
+					Live:     f.Old,
+					Declared: f.New,
+					Status:   DriftChanged,
+				})
+			}
+		}
+	}
+	return diffs
+}
@@ -0,0 +1,214 @@
+// Package manifest describes a declarative Recotem setup and computes the
+// changes needed to bring a server in line with it.
+//
+// Resources are identified by name: split and evaluation configs globally,
+// everything else within its project. A deployment slot has at most one
+// retraining schedule, identified by the slot. Optional fields that are left
+// out are not managed: they are set to the server default on creation and
+// never compared afterwards.
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"recotem.org/cli/recotem/pkg/openapi"
+)
+
+type Manifest struct {
+	SplitConfigs      []SplitConfig      `yaml:"split_configs,omitempty" json:"split_configs,omitempty"`
+	EvaluationConfigs []EvaluationConfig `yaml:"evaluation_configs,omitempty" json:"evaluation_configs,omitempty"`
+	Projects          []Project          `yaml:"projects,omitempty" json:"projects,omitempty"`
+}
+
+type SplitConfig struct {
+	Name          string              `yaml:"name" json:"name"`
+	Scheme        *openapi.SchemeEnum `yaml:"scheme,omitempty" json:"scheme,omitempty"`
+	HeldoutRatio  *float32            `yaml:"heldout_ratio,omitempty" json:"heldout_ratio,omitempty"`
+	NHeldout      *int                `yaml:"n_heldout,omitempty" json:"n_heldout,omitempty"`
+	TestUserRatio *float32            `yaml:"test_user_ratio,omitempty" json:"test_user_ratio,omitempty"`
+	NTestUsers    *int                `yaml:"n_test_users,omitempty" json:"n_test_users,omitempty"`
+	RandomSeed    *int                `yaml:"random_seed,omitempty" json:"random_seed,omitempty"`
+}
+
+type EvaluationConfig struct {
+	Name         string                    `yaml:"name" json:"name"`
+	Cutoff       *int                      `yaml:"cutoff,omitempty" json:"cutoff,omitempty"`
+	TargetMetric *openapi.TargetMetricEnum `yaml:"target_metric,omitempty" json:"target_metric,omitempty"`
+}
+
+type Project struct {
+	Name                string               `yaml:"name" json:"name"`
+	UserColumn          string               `yaml:"user_column" json:"user_column"`
+	ItemColumn          string               `yaml:"item_column" json:"item_column"`
+	TimeColumn          *string              `yaml:"time_column,omitempty" json:"time_column,omitempty"`
+	ModelConfigurations []ModelConfiguration `yaml:"model_configurations,omitempty" json:"model_configurations,omitempty"`
+	DeploymentSlots     []DeploymentSlot     `yaml:"deployment_slots,omitempty" json:"deployment_slots,omitempty"`
+	AbTests             []AbTest             `yaml:"ab_tests,omitempty" json:"ab_tests,omitempty"`
+}
+
+type ModelConfiguration struct {
+	Name                 string         `yaml:"name" json:"name"`
+	RecommenderClassName string         `yaml:"recommender_class_name" json:"recommender_class_name"`
+	Parameters           map[string]any `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+}
+
+// ParametersJSON returns the parameters as the server stores them.
+func (m ModelConfiguration) ParametersJSON() (string, error) {
+	params := m.Parameters
+	if params == nil {
+		params = map[string]any{}
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("model configuration %q: %w", m.Name, err)
+	}
+	return string(data), nil
+}
+
+type DeploymentSlot struct {
+	Name               string              `yaml:"name" json:"name"`
+	TrainedModel       *int                `yaml:"trained_model,omitempty" json:"trained_model,omitempty"`
+	IsActive           *bool               `yaml:"is_active,omitempty" json:"is_active,omitempty"`
+	RetrainingSchedule *RetrainingSchedule `yaml:"retraining_schedule,omitempty" json:"retraining_schedule,omitempty"`
+}
+
+type RetrainingSchedule struct {
+	CronExpression string `yaml:"cron_expression" json:"cron_expression"`
+	IsActive       *bool  `yaml:"is_active,omitempty" json:"is_active,omitempty"`
+}
+
+type AbTest struct {
+	Name string `yaml:"name" json:"name"`
+	// Slots are deployment slot names of the same project.
+	Slots []string `yaml:"slots" json:"slots"`
+}
+
+// Load reads and validates a manifest file. Unknown keys are errors so that
+// typos do not silently leave a field unmanaged.
+func Load(path string) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read is Load for a reader.
+func Read(r io.Reader) (*Manifest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	m := &Manifest{}
+	if err := dec.Decode(m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Validate checks required fields, enum values, name uniqueness and the
+// slot references of A/B tests. All problems are reported together.
+func (m *Manifest) Validate() error {
+	var errs []string
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+	unique := func(scope string) func(name string) {
+		seen := map[string]bool{}
+		return func(name string) {
+			if name == "" {
+				fail("%s: name is required", scope)
+			} else if seen[name] {
+				fail("%s %q is declared more than once", scope, name)
+			}
+			seen[name] = true
+		}
+	}
+
+	splitName := unique("split config")
+	for _, x := range m.SplitConfigs {
+		splitName(x.Name)
+		if x.Scheme != nil && *x.Scheme != openapi.RG && *x.Scheme != openapi.TG && *x.Scheme != openapi.TU {
+			fail("split config %q: invalid scheme %q (expected RG, TG or TU)", x.Name, *x.Scheme)
+		}
+		if x.HeldoutRatio != nil && (*x.HeldoutRatio < 0 || *x.HeldoutRatio > 1) {
+			fail("split config %q: heldout_ratio must be between 0 and 1", x.Name)
+		}
+		if x.TestUserRatio != nil && (*x.TestUserRatio < 0 || *x.TestUserRatio > 1) {
+			fail("split config %q: test_user_ratio must be between 0 and 1", x.Name)
+		}
+	}
+
+	evaluationName := unique("evaluation config")
+	for _, x := range m.EvaluationConfigs {
+		evaluationName(x.Name)
+		if x.TargetMetric != nil {
+			switch *x.TargetMetric {
+			case openapi.Hit, openapi.Map, openapi.Ndcg, openapi.Recall:
+			default:
+				fail("evaluation config %q: invalid target_metric %q (expected hit, map, ndcg or recall)",
+					x.Name, *x.TargetMetric)
+			}
+		}
+		if x.Cutoff != nil && *x.Cutoff <= 0 {
+			fail("evaluation config %q: cutoff must be positive", x.Name)
+		}
+	}
+
+	projectName := unique("project")
+	for _, p := range m.Projects {
+		projectName(p.Name)
+		if p.UserColumn == "" || p.ItemColumn == "" {
+			fail("project %q: user_column and item_column are required", p.Name)
+		}
+		mcName := unique(fmt.Sprintf("project %q: model configuration", p.Name))
+		for _, x := range p.ModelConfigurations {
+			mcName(x.Name)
+			if x.RecommenderClassName == "" {
+				fail("project %q: model configuration %q: recommender_class_name is required", p.Name, x.Name)
+			}
+			if _, err := x.ParametersJSON(); err != nil {
+				fail("project %q: %v", p.Name, err)
+			}
+		}
+		slots := map[string]bool{}
+		slotName := unique(fmt.Sprintf("project %q: deployment slot", p.Name))
+		for _, x := range p.DeploymentSlots {
+			slotName(x.Name)
+			slots[x.Name] = true
+			if x.RetrainingSchedule != nil && strings.TrimSpace(x.RetrainingSchedule.CronExpression) == "" {
+				fail("project %q: deployment slot %q: retraining_schedule.cron_expression is required", p.Name, x.Name)
+			}
+		}
+		abName := unique(fmt.Sprintf("project %q: A/B test", p.Name))
+		for _, x := range p.AbTests {
+			abName(x.Name)
+			if len(x.Slots) < 2 {
+				fail("project %q: A/B test %q: at least two slots are required", p.Name, x.Name)
+			}
+			for _, s := range x.Slots {
+				if !slots[s] {
+					fail("project %q: A/B test %q: deployment slot %q is not declared", p.Name, x.Name, s)
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid manifest:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package manifest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"recotem.org/cli/recotem/pkg/openapi"
+)
+
+const sample = `
+split_configs:
+  - name: default
+    scheme: RG
+    heldout_ratio: 0.1
+evaluation_configs:
+  - name: ndcg10
+    cutoff: 10
+    target_metric: ndcg
+projects:
+  - name: shop
+    user_column: user_id
+    item_column: item_id
+    model_configurations:
+      - name: ials
+        recommender_class_name: IALSRecommender
+        parameters:
+          n_components: 64
+          alpha: 0.5
+    deployment_slots:
+      - name: main
+        trained_model: 12
+        retraining_schedule:
+          cron_expression: "0 3 * * *"
+      - name: canary
+    ab_tests:
+      - name: exp
+        slots: [main, canary]
+`
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestRead(t *testing.T) {
+	m, err := Read(strings.NewReader(sample))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(m.Projects) != 1 || len(m.Projects[0].DeploymentSlots) != 2 {
+		t.Fatalf("unexpected manifest: %+v", m)
+	}
+	if *m.SplitConfigs[0].Scheme != openapi.RG {
+		t.Errorf("expected scheme RG, got %v", *m.SplitConfigs[0].Scheme)
+	}
+	params, err := m.Projects[0].ModelConfigurations[0].ParametersJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params != `{"alpha":0.5,"n_components":64}` {
+		t.Errorf("unexpected parameters %s", params)
+	}
+}
+
+func TestReadRejectsUnknownFields(t *testing.T) {
+	_, err := Read(strings.NewReader("projects:\n  - name: shop\n    user_colum: u\n"))
+	if err == nil || !strings.Contains(err.Error(), "user_colum") {
+		t.Errorf("expected an unknown field error, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	input := `
+split_configs:
+  - name: a
+    scheme: XX
+  - name: a
+evaluation_configs:
+  - name: e
+    target_metric: auc
+projects:
+  - name: shop
+    deployment_slots:
+      - name: main
+        retraining_schedule:
+          cron_expression: ""
+    ab_tests:
+      - name: exp
+        slots: [main, missing]
+`
+	_, err := Read(strings.NewReader(input))
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{
+		`invalid scheme "XX"`,
+		`split config "a" is declared more than once`,
+		`invalid target_metric "auc"`,
+		"user_column and item_column are required",
+		"cron_expression is required",
+		`deployment slot "missing" is not declared`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error:\n%v", want, err)
+		}
+	}
+}
+
+func TestComputeCreatesEverythingOnEmptyServer(t *testing.T) {
+	m, err := Read(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := Compute(m, &State{}, true)
+
+	var got []string
+	for _, c := range plan.Changes {
+		got = append(got, string(c.Action)+" "+string(c.Kind)+" "+c.Path())
+	}
+	expected := []string{
+		"create split-config default",
+		"create evaluation-config ndcg10",
+		"create project shop",
+		"create model-configuration shop/ials",
+		"create deployment-slot shop/main",
+		"create deployment-slot shop/canary",
+		"create retraining-schedule shop/main",
+		"create ab-test shop/exp",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected plan:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func liveState() *State {
+	scheme := openapi.RG
+	metric := openapi.Ndcg
+	return &State{
+		SplitConfigs: []openapi.SplitConfig{{Id: intPtr(1), Name: stringPtr("default"), Scheme: &scheme, HeldoutRatio: float32Ptr(0.1)}},
+		EvaluationConfigs: []openapi.EvaluationConfig{
+			{Id: intPtr(2), Name: stringPtr("ndcg10"), Cutoff: intPtr(20), TargetMetric: &metric},
+		},
+		Projects: map[string]*ProjectState{
+			"shop": {
+				Project: openapi.Project{Id: intPtr(3), Name: "shop", UserColumn: "user_id", ItemColumn: "item_id"},
+				ModelConfigurations: []openapi.ModelConfiguration{
+					{Id: intPtr(4), Name: stringPtr("ials"), RecommenderClassName: "IALSRecommender",
+						ParametersJson: `{"n_components": 64, "alpha": 0.5}`},
+					{Id: intPtr(5), Name: stringPtr("old"), RecommenderClassName: "TopPopRecommender", ParametersJson: "{}"},
+					{Id: intPtr(6), Name: stringPtr("tuned"), RecommenderClassName: "TopPopRecommender",
+						ParametersJson: "{}", TuningJob: intPtr(1)},
+				},
+				DeploymentSlots: []openapi.DeploymentSlot{
+					{Id: intPtr(7), Name: "main", TrainedModel: intPtr(11), IsActive: true},
+					{Id: intPtr(8), Name: "canary", IsActive: true},
+					{Id: intPtr(9), Name: "legacy", IsActive: true},
+				},
+				RetrainingSchedules: []openapi.RetrainingSchedule{
+					{Id: intPtr(10), DeploymentSlot: 7, CronExpression: "0 4 * * *", IsActive: true},
+					{Id: intPtr(11), DeploymentSlot: 9, CronExpression: "0 5 * * *", IsActive: true},
+				},
+				AbTests: []openapi.AbTest{{Id: intPtr(12), Name: "exp", Slots: []int{8, 7}}},
+			},
+		},
+	}
+}
+
+func float32Ptr(f float32) *float32 {
+	return &f
+}
+
+func TestComputeUpdatesAndPrune(t *testing.T) {
+	m, err := Read(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan := Compute(m, liveState(), false)
+	var got []string
+	for _, c := range plan.Changes {
+		line := string(c.Action) + " " + string(c.Kind) + " " + c.Path()
+		for _, f := range c.Fields {
+			line += " " + f.Field
+		}
+		got = append(got, line)
+	}
+	expected := []string{
+		"update evaluation-config ndcg10 cutoff",
+		"update deployment-slot shop/main trained_model",
+		"update retraining-schedule shop/main cron_expression",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected plan:\n%s", strings.Join(got, "\n"))
+	}
+	if f := plan.Changes[1].Fields[0]; f.Old != 11 || f.New != 12 {
+		t.Errorf("unexpected trained_model change %+v", f)
+	}
+
+	plan = Compute(m, liveState(), true)
+	got = nil
+	for _, c := range plan.Changes {
+		if c.Action == ActionDelete {
+			got = append(got, fmt.Sprintf("%s %s %d", c.Kind, c.Path(), *c.ID))
+		}
+	}
+	expected = []string{
+		"retraining-schedule shop/legacy 11",
+		"deployment-slot shop/legacy 9",
+		"model-configuration shop/old 5",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected deletes:\n%s", strings.Join(got, "\n"))
+	}
+	create, update, remove := plan.Counts()
+	if create != 0 || update != 3 || remove != 3 {
+		t.Errorf("unexpected counts %d/%d/%d", create, update, remove)
+	}
+}
+
+func TestSameJSON(t *testing.T) {
+	if !sameJSON(`{"a": 1, "b": [1, 2]}`, `{"b":[1,2],"a":1.0}`) {
+		t.Error("expected equal documents")
+	}
+	if sameJSON(`{"a": 1}`, `{"a": 2}`) {
+		t.Error("expected different documents")
+	}
+}
+
+func TestDrift(t *testing.T) {
+	m, err := Read(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := liveState()
+	state.Projects["shop"].ModelConfigurations = state.Projects["shop"].ModelConfigurations[1:]
+
+	diffs := Drift(Compute(m, state, true))
+	var got []string
+	for _, d := range diffs {
+		got = append(got, fmt.Sprintf("%s %s %s %v %v", d.Status, d.Path, d.Field, d.Live, d.Declared))
+	}
+	expected := []string{
+		"changed ndcg10 EvaluationConfig.cutoff 20 10",
+		"missing shop/ials  <nil> <nil>",
+		"changed shop/main DeploymentSlot.trained_model 11 12",
+		"changed shop/main RetrainingSchedule.cron_expression 0 4 * * * 0 3 * * *",
+		"unmanaged shop/legacy  <nil> <nil>",
+		"unmanaged shop/legacy  <nil> <nil>",
+		"unmanaged shop/old  <nil> <nil>",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected drift:\n%s", strings.Join(got, "\n"))
+	}
+
+	if diffs := Drift(&Plan{}); len(diffs) != 0 {
+		t.Errorf("expected no drift, got %v", diffs)
+	}
+}
@@ -0,0 +1,418 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"recotem.org/cli/recotem/pkg/openapi"
+)
+
+type Kind string
+
+const (
+	KindSplitConfig        Kind = "split-config"
+	KindEvaluationConfig   Kind = "evaluation-config"
+	KindProject            Kind = "project"
+	KindModelConfiguration Kind = "model-configuration"
+	KindDeploymentSlot     Kind = "deployment-slot"
+	KindRetrainingSchedule Kind = "retraining-schedule"
+	KindAbTest             Kind = "ab-test"
+)
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// FieldChange is a field whose live value differs from the declared one.
+// Old is nil when the field is not set on the server.
+type FieldChange struct {
+	Field string `json:"field" yaml:"field"`
+	Old   any    `json:"old" yaml:"old"`
+	New   any    `json:"new" yaml:"new"`
+}
+
+// Change is one step of a plan.
+type Change struct {
+	Action Action `json:"action" yaml:"action"`
+	Kind   Kind   `json:"kind" yaml:"kind"`
+	// Project is the name of the owning project, empty for split and
+	// evaluation configs.
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+	// Name identifies the resource; for a retraining schedule it is the name
+	// of its deployment slot.
+	Name string `json:"name" yaml:"name"`
+	// ID is the live ID of an updated or deleted resource.
+	ID     *int          `json:"id,omitempty" yaml:"id,omitempty"`
+	Fields []FieldChange `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// Spec is the declared resource of a create or update: a *SplitConfig,
+	// *EvaluationConfig, *Project, *ModelConfiguration, *DeploymentSlot,
+	// *RetrainingSchedule or *AbTest.
+	Spec any `json:"-" yaml:"-"`
+}
+
+// Path is the name of the resource qualified by its project.
+func (c Change) Path() string {
+	if c.Project == "" || c.Kind == KindProject {
+		return c.Name
+	}
+	return c.Project + "/" + c.Name
+}
+
+// State is the live state of the resources a manifest refers to.
+type State struct {
+	SplitConfigs      []openapi.SplitConfig
+	EvaluationConfigs []openapi.EvaluationConfig
+	// Projects holds the declared projects that exist on the server, by name.
+	Projects map[string]*ProjectState
+}
+
+type ProjectState struct {
+	Project             openapi.Project
+	ModelConfigurations []openapi.ModelConfiguration
+	DeploymentSlots     []openapi.DeploymentSlot
+	RetrainingSchedules []openapi.RetrainingSchedule
+	AbTests             []openapi.AbTest
+}
+
+type Plan struct {
+	Changes []Change `json:"changes" yaml:"changes"`
+}
+
+// Counts returns the number of creates, updates and deletes.
+func (p *Plan) Counts() (create, update, remove int) {
+	for _, c := range p.Changes {
+		switch c.Action {
+		case ActionCreate:
+			create++
+		case ActionUpdate:
+			update++
+		case ActionDelete:
+			remove++
+		}
+	}
+	return create, update, remove
+}
+
+// Compute returns the changes that make the server match the manifest.
+// Creates and updates come first in dependency order, followed by deletes in
+// reverse dependency order. With prune, resources of declared projects that
+// the manifest does not declare are deleted; projects, split configs and
+// evaluation configs are never deleted since they may hold data or be shared.
+func Compute(m *Manifest, s *State, prune bool) *Plan {
+	p := &Plan{Changes: []Change{}}
+	var deletes []Change
+
+	for i := range m.SplitConfigs {
+		x := &m.SplitConfigs[i]
+		live := findSplitConfig(s.SplitConfigs, x.Name)
+		if live == nil {
+			p.add(Change{Action: ActionCreate, Kind: KindSplitConfig, Name: x.Name, Spec: x})
+			continue
+		}
+		var d diff
+		compare(&d, "scheme", x.Scheme, live.Scheme)
+		compare(&d, "heldout_ratio", x.HeldoutRatio, live.HeldoutRatio)
+		compare(&d, "n_heldout", x.NHeldout, live.NHeldout)
+		compare(&d, "test_user_ratio", x.TestUserRatio, live.TestUserRatio)
+		compare(&d, "n_test_users", x.NTestUsers, live.NTestUsers)
+		compare(&d, "random_seed", x.RandomSeed, live.RandomSeed)
+		p.update(KindSplitConfig, "", x.Name, live.Id, d, x)
+	}
+
+	for i := range m.EvaluationConfigs {
+		x := &m.EvaluationConfigs[i]
+		live := findEvaluationConfig(s.EvaluationConfigs, x.Name)
+		if live == nil {
+			p.add(Change{Action: ActionCreate, Kind: KindEvaluationConfig, Name: x.Name, Spec: x})
+			continue
+		}
+		var d diff
+		compare(&d, "cutoff", x.Cutoff, live.Cutoff)
+		compare(&d, "target_metric", x.TargetMetric, live.TargetMetric)
+		p.update(KindEvaluationConfig, "", x.Name, live.Id, d, x)
+	}
+
+	for i := range m.Projects {
+		x := &m.Projects[i]
+		live := s.Projects[x.Name]
+		if live == nil {
+			live = &ProjectState{}
+			p.add(Change{Action: ActionCreate, Kind: KindProject, Project: x.Name, Name: x.Name, Spec: x})
+		} else {
+			var d diff
+			compare(&d, "user_column", &x.UserColumn, &live.Project.UserColumn)
+			compare(&d, "item_column", &x.ItemColumn, &live.Project.ItemColumn)
+			// An empty time_column declares that there is none.
+			if x.TimeColumn != nil {
+				var old any
+				liveTime := ""
+				if live.Project.TimeColumn != nil {
+					liveTime = *live.Project.TimeColumn
+					old = liveTime
+				}
+				if *x.TimeColumn != liveTime {
+					d.add("time_column", old, *x.TimeColumn)
+				}
+			}
+			p.update(KindProject, x.Name, x.Name, live.Project.Id, d, x)
+		}
+		deletes = append(deletes, p.project(x, live, prune)...)
+	}
+
+	p.Changes = append(p.Changes, deletes...)
+	return p
+}
+
+// project plans the resources of a project and returns its deletes.
+func (p *Plan) project(x *Project, live *ProjectState, prune bool) []Change {
+	var deletes []Change
+	remove := func(kind Kind, name string, id *int) {
+		if prune {
+			deletes = append(deletes, Change{Action: ActionDelete, Kind: kind, Project: x.Name, Name: name, ID: id})
+		}
+	}
+
+	declared := map[string]bool{}
+	for i := range x.ModelConfigurations {
+		mc := &x.ModelConfigurations[i]
+		declared[mc.Name] = true
+		l := findModelConfiguration(live.ModelConfigurations, mc.Name)
+		if l == nil {
+			p.add(Change{Action: ActionCreate, Kind: KindModelConfiguration, Project: x.Name, Name: mc.Name, Spec: mc})
+			continue
+		}
+		var d diff
+		compare(&d, "recommender_class_name", &mc.RecommenderClassName, &l.RecommenderClassName)
+		if mc.Parameters != nil {
+			want, _ := mc.ParametersJSON()
+			if !sameJSON(want, l.ParametersJson) {
+				d.add("parameters_json", l.ParametersJson, want)
+			}
+		}
+		p.update(KindModelConfiguration, x.Name, mc.Name, l.Id, d, mc)
+	}
+	// Unnamed configurations and those created by tuning jobs cannot be
+	// declared, so they are never pruned.
+	var mcDeletes []Change
+	for _, l := range live.ModelConfigurations {
+		if prune && l.Name != nil && *l.Name != "" && !declared[*l.Name] && l.TuningJob == nil {
+			mcDeletes = append(mcDeletes, Change{Action: ActionDelete, Kind: KindModelConfiguration,
+				Project: x.Name, Name: *l.Name, ID: l.Id})
+		}
+	}
+
+	slotNames := map[int]string{}
+	for _, l := range live.DeploymentSlots {
+		if l.Id != nil {
+			slotNames[*l.Id] = l.Name
+		}
+	}
+	schedules := map[int][]openapi.RetrainingSchedule{}
+	for _, l := range live.RetrainingSchedules {
+		schedules[l.DeploymentSlot] = append(schedules[l.DeploymentSlot], l)
+	}
+	for _, list := range schedules {
+		sort.Slice(list, func(i, j int) bool { return idOf(list[i].Id) < idOf(list[j].Id) })
+	}
+
+	declared = map[string]bool{}
+	var scheduleChanges []Change
+	for i := range x.DeploymentSlots {
+		slot := &x.DeploymentSlots[i]
+		declared[slot.Name] = true
+		l := findDeploymentSlot(live.DeploymentSlots, slot.Name)
+		var liveSchedules []openapi.RetrainingSchedule
+		if l == nil {
+			p.add(Change{Action: ActionCreate, Kind: KindDeploymentSlot, Project: x.Name, Name: slot.Name, Spec: slot})
+		} else {
+			var d diff
+			compare(&d, "trained_model", slot.TrainedModel, l.TrainedModel)
+			compare(&d, "is_active", slot.IsActive, &l.IsActive)
+			p.update(KindDeploymentSlot, x.Name, slot.Name, l.Id, d, slot)
+			liveSchedules = schedules[idOf(l.Id)]
+		}
+
+		if rs := slot.RetrainingSchedule; rs != nil {
+			if len(liveSchedules) == 0 {
+				scheduleChanges = append(scheduleChanges, Change{Action: ActionCreate, Kind: KindRetrainingSchedule,
+					Project: x.Name, Name: slot.Name, Spec: rs})
+			} else {
+				ls := liveSchedules[0]
+				liveSchedules = liveSchedules[1:]
+				var d diff
+				compare(&d, "cron_expression", &rs.CronExpression, &ls.CronExpression)
+				compare(&d, "is_active", rs.IsActive, &ls.IsActive)
+				if !d.empty() {
+					scheduleChanges = append(scheduleChanges, Change{Action: ActionUpdate, Kind: KindRetrainingSchedule,
+						Project: x.Name, Name: slot.Name, ID: ls.Id, Fields: d.fields, Spec: rs})
+				}
+			}
+		}
+		for _, ls := range liveSchedules {
+			remove(KindRetrainingSchedule, slot.Name, ls.Id)
+		}
+	}
+	p.Changes = append(p.Changes, scheduleChanges...)
+	var slotDeletes []Change
+	for _, l := range live.DeploymentSlots {
+		if declared[l.Name] {
+			continue
+		}
+		for _, ls := range schedules[idOf(l.Id)] {
+			remove(KindRetrainingSchedule, l.Name, ls.Id)
+		}
+		if prune {
+			slotDeletes = append(slotDeletes, Change{Action: ActionDelete, Kind: KindDeploymentSlot,
+				Project: x.Name, Name: l.Name, ID: l.Id})
+		}
+	}
+
+	declared = map[string]bool{}
+	var abDeletes []Change
+	for i := range x.AbTests {
+		ab := &x.AbTests[i]
+		declared[ab.Name] = true
+		l := findAbTest(live.AbTests, ab.Name)
+		if l == nil {
+			p.add(Change{Action: ActionCreate, Kind: KindAbTest, Project: x.Name, Name: ab.Name, Spec: ab})
+			continue
+		}
+		var d diff
+		want := sortedCopy(ab.Slots)
+		have := make([]string, 0, len(l.Slots))
+		for _, id := range l.Slots {
+			if name, ok := slotNames[id]; ok {
+				have = append(have, name)
+			} else {
+				have = append(have, fmt.Sprintf("#%d", id))
+			}
+		}
+		have = sortedCopy(have)
+		if !reflect.DeepEqual(want, have) {
+			d.add("slots", have, want)
+		}
+		p.update(KindAbTest, x.Name, ab.Name, l.Id, d, ab)
+	}
+	for _, l := range live.AbTests {
+		if !declared[l.Name] && prune {
+			abDeletes = append(abDeletes, Change{Action: ActionDelete, Kind: KindAbTest,
+				Project: x.Name, Name: l.Name, ID: l.Id})
+		}
+	}
+
+	// A/B tests go first since they refer to slots, and schedules are
+	// removed before their slots.
+	result := append(abDeletes, deletes...)
+	result = append(result, slotDeletes...)
+	return append(result, mcDeletes...)
+}
+
+func (p *Plan) add(c Change) {
+	p.Changes = append(p.Changes, c)
+}
+
+func (p *Plan) update(kind Kind, project, name string, id *int, d diff, spec any) {
+	if d.empty() {
+		return
+	}
+	p.add(Change{Action: ActionUpdate, Kind: kind, Project: project, Name: name, ID: id, Fields: d.fields, Spec: spec})
+}
+
+type diff struct {
+	fields []FieldChange
+}
+
+func (d *diff) add(field string, old, new any) {
+	d.fields = append(d.fields, FieldChange{Field: field, Old: old, New: new})
+}
+
+func (d *diff) empty() bool {
+	return len(d.fields) == 0
+}
+
+// compare records a difference when the field is declared and its live
+// value is missing or different.
+func compare[T comparable](d *diff, field string, declared, live *T) {
+	if declared == nil {
+		return
+	}
+	if live == nil {
+		d.add(field, nil, *declared)
+	} else if *live != *declared {
+		d.add(field, *live, *declared)
+	}
+}
+
+// sameJSON reports whether two JSON documents are equal, ignoring key order
+// and number formatting. Invalid documents are compared as text.
+func sameJSON(a, b string) bool {
+	var va, vb any
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return a == b
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func findSplitConfig(list []openapi.SplitConfig, name string) *openapi.SplitConfig {
+	for i := range list {
+		if list[i].Name != nil && *list[i].Name == name {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+func findEvaluationConfig(list []openapi.EvaluationConfig, name string) *openapi.EvaluationConfig {
+	for i := range list {
+		if list[i].Name != nil && *list[i].Name == name {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+func findModelConfiguration(list []openapi.ModelConfiguration, name string) *openapi.ModelConfiguration {
+	for i := range list {
+		if list[i].Name != nil && *list[i].Name == name {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+func findDeploymentSlot(list []openapi.DeploymentSlot, name string) *openapi.DeploymentSlot {
+	for i := range list {
+		if list[i].Name == name {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+func findAbTest(list []openapi.AbTest, name string) *openapi.AbTest {
+	for i := range list {
+		if list[i].Name == name {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+func idOf(id *int) int {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+func sortedCopy(s []string) []string {
+	c := append([]string{}, s...)
+	sort.Strings(c)
+	return c
+}
@@ -0,0 +1,222 @@
+// Package pipeline describes an end-to-end training pipeline, from local data
+// files to a served model, and the state that lets an interrupted run resume.
+package pipeline
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"recotem.org/cli/recotem/pkg/dataset"
+	"recotem.org/cli/recotem/pkg/manifest"
+	"recotem.org/cli/recotem/pkg/pseudonym"
+	"recotem.org/cli/recotem/pkg/tuning"
+)
+
+// Pipeline is a pipeline file. Relative data paths are resolved against the
+// directory of the file.
+type Pipeline struct {
+	// Project is an existing project, by ID or name.
+	Project      tuning.Ref `yaml:"project"`
+	TrainingData string     `yaml:"training_data"`
+	ItemMetaData string     `yaml:"item_meta_data,omitempty"`
+	// InputFormat overrides the format detected from the names of the data
+	// files (parquet, jsonl, csv, tsv).
+	InputFormat string `yaml:"input_format,omitempty"`
+	// Pseudonymize replaces user and item IDs with pseudonyms before upload
+	// (hmac, sequential), as upload --pseudonymize does.
+	Pseudonymize string `yaml:"pseudonymize,omitempty"`
+	// Split and Evaluation are reused when a config with the name exists
+	// and created otherwise.
+	Split      manifest.SplitConfig      `yaml:"split"`
+	Evaluation manifest.EvaluationConfig `yaml:"evaluation"`
+	// Tuning holds the job settings; data, split and evaluation are filled
+	// in by the pipeline.
+	Tuning tuning.Spec `yaml:"tuning,omitempty"`
+	// SmokeTest requests a sample recommendation from the tuned model;
+	// it defaults to true.
+	SmokeTest *bool `yaml:"smoke_test,omitempty"`
+	// DeploymentSlot, by ID or name within the project, is pointed at the
+	// tuned model when set.
+	DeploymentSlot tuning.Ref `yaml:"deployment_slot,omitempty"`
+
+	// Digest identifies the file content, so state from a different
+	// pipeline is not resumed.
+	Digest string `yaml:"-"`
+	dir    string
+}
+
+// Load reads and validates a pipeline file.
+func Load(path string) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := Read(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	p.dir = filepath.Dir(path)
+	return p, nil
+}
+
+// Read is Load for a reader; data paths stay relative to the working
+// directory.
+func Read(r io.Reader) (*Pipeline, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	p := &Pipeline{}
+	if err := dec.Decode(p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid pipeline: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	p.Digest = hex.EncodeToString(sum[:])
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Path resolves a data path from the file.
+func (p *Pipeline) Path(name string) string {
+	if name == "" || filepath.IsAbs(name) || p.dir == "" {
+		return name
+	}
+	return filepath.Join(p.dir, name)
+}
+
+// SmokeTestEnabled reports whether the smoke test runs.
+func (p *Pipeline) SmokeTestEnabled() bool {
+	return p.SmokeTest == nil || *p.SmokeTest
+}
+
+// Validate checks required fields and the tuning settings. All problems are
+// reported together.
+func (p *Pipeline) Validate() error {
+	var errs []string
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+	if p.Project.IsZero() {
+		fail("project: required (an ID or a name)")
+	}
+	if p.TrainingData == "" {
+		fail("training_data: required")
+	}
+	if _, err := dataset.ParseFormat(p.InputFormat); err != nil {
+		fail("input_format: %v", err)
+	}
+	if p.Pseudonymize != "" {
+		if _, err := pseudonym.ParseMode(p.Pseudonymize); err != nil {
+			fail("pseudonymize: %v", err)
+		}
+	}
+	if p.Split.Name == "" {
+		fail("split: name is required")
+	}
+	if p.Evaluation.Name == "" {
+		fail("evaluation: name is required")
+	}
+	if !p.Tuning.Data.IsZero() || !p.Tuning.Split.IsZero() || !p.Tuning.Evaluation.IsZero() {
+		fail("tuning: data, split and evaluation are set by the pipeline")
+	}
+	if p.Tuning.TrainAfterTuning != nil && !*p.Tuning.TrainAfterTuning {
+		fail("tuning: train_after_tuning cannot be false; the pipeline needs the tuned model")
+	}
+	if spec, err := p.JobSpec(1, 1, 1); err != nil {
+		fail("tuning: %v", err)
+	} else if err := spec.Validate(); err != nil {
+		for _, line := range strings.Split(err.Error(), "\n")[1:] {
+			fail("tuning: %s", strings.TrimSpace(line))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid pipeline:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
+
+// JobSpec returns the tuning job to create for the given resources.
+func (p *Pipeline) JobSpec(data, split, evaluation int) (tuning.Spec, error) {
+	train := true
+	spec := p.Tuning
+	spec.Data = tuning.Ref{ID: &data}
+	spec.Split = tuning.Ref{ID: &split}
+	spec.Evaluation = tuning.Ref{ID: &evaluation}
+	spec.TrainAfterTuning = &train
+	return spec.Resolve()
+}
+
+// State records the resources a run has created or found, one field per
+// step; a set field means the step is complete.
+type State struct {
+	Digest         string `json:"digest"`
+	Project        *int   `json:"project,omitempty"`
+	TrainingData   *int   `json:"training_data,omitempty"`
+	ItemMetaData   *int   `json:"item_meta_data,omitempty"`
+	Split          *int   `json:"split,omitempty"`
+	Evaluation     *int   `json:"evaluation,omitempty"`
+	TuningJob      *int   `json:"tuning_job,omitempty"`
+	TrainedModel   *int   `json:"trained_model,omitempty"`
+	SmokeTested    bool   `json:"smoke_tested,omitempty"`
+	DeploymentSlot *int   `json:"deployment_slot,omitempty"`
+
+	path string
+}
+
+// StatePath is where the state of a pipeline file is kept by default: next
+// to it, with ".state.json" appended.
+func StatePath(pipelinePath string) string {
+	return pipelinePath + ".state.json"
+}
+
+// LoadState reads the state at path for the pipeline. A missing file yields
+// an empty state; state written for different pipeline content is an error,
+// since its resources may not match.
+func LoadState(path string, p *Pipeline) (*State, error) {
+	s := &State{Digest: p.Digest, path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid pipeline state %s: %w", path, err)
+	}
+	if s.Digest != p.Digest {
+		return nil, fmt.Errorf("pipeline state %s belongs to a different version of the pipeline file; use --restart to start over", path)
+	}
+	return s, nil
+}
+
+// NewState is an empty state for the pipeline, saved to path.
+func NewState(path string, p *Pipeline) *State {
+	return &State{Digest: p.Digest, path: path}
+}
+
+// Save writes the state atomically.
+func (s *State) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
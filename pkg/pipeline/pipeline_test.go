@@ -0,0 +1,138 @@
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const example = `
+project: shop
+training_data: ./interactions.csv
+split:
+  name: default
+  scheme: RG
+evaluation:
+  name: ndcg-at-10
+  cutoff: 10
+tuning:
+  preset: quick
+  n_trials: 5
+deployment_slot: main
+`
+
+func TestRead(t *testing.T) {
+	p, err := Read(strings.NewReader(example))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Project.Name != "shop" || p.DeploymentSlot.Name != "main" {
+		t.Errorf("unexpected references: %v, %v", p.Project, p.DeploymentSlot)
+	}
+	if !p.SmokeTestEnabled() {
+		t.Error("expected the smoke test to default to enabled")
+	}
+	if p.Digest == "" {
+		t.Error("expected a digest")
+	}
+}
+
+func TestReadReportsEveryProblem(t *testing.T) {
+	_, err := Read(strings.NewReader(`
+input_format: xlsx
+pseudonymize: md5
+tuning:
+  data: 3
+  train_after_tuning: false
+  n_trials: 0
+`))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"project: required", "training_data: required", "split: name",
+		"evaluation: name", "input_format:", "pseudonymize:", "set by the pipeline", "train_after_tuning cannot be false", "tuning: n_trials"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in:\n%v", want, err)
+		}
+	}
+
+	if _, err := Read(strings.NewReader("project: shop\ntraining: a.csv\n")); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestLoadResolvesPathsAgainstTheFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pipeline.yaml")
+	if err := os.WriteFile(path, []byte(example), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := p.Path(p.TrainingData); got != filepath.Join(dir, "interactions.csv") {
+		t.Errorf("unexpected path %q", got)
+	}
+	if got := p.Path("/data/a.csv"); got != "/data/a.csv" {
+		t.Errorf("expected an absolute path to be kept, got %q", got)
+	}
+}
+
+func TestJobSpec(t *testing.T) {
+	p, err := Read(strings.NewReader(example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := p.JobSpec(1, 2, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *spec.Data.ID != 1 || *spec.Split.ID != 2 || *spec.Evaluation.ID != 3 {
+		t.Errorf("unexpected references: %+v", spec)
+	}
+	if spec.TrainAfterTuning == nil || !*spec.TrainAfterTuning {
+		t.Error("expected train_after_tuning to be forced on")
+	}
+	if *spec.NTrials != 5 || spec.TimeoutOverall == nil {
+		t.Errorf("expected the preset under the file's settings, got %+v", spec)
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	p, err := Read(strings.NewReader(example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "pipeline.yaml.state.json")
+
+	s, err := LoadState(path, p)
+	if err != nil {
+		t.Fatalf("unexpected error for a missing state file: %v", err)
+	}
+	id := 7
+	s.Project = &id
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = LoadState(path, p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Project == nil || *s.Project != 7 {
+		t.Errorf("expected the saved project, got %v", s.Project)
+	}
+
+	changed, err := Read(strings.NewReader(example + "smoke_test: false\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadState(path, changed); err == nil || !strings.Contains(err.Error(), "--restart") {
+		t.Errorf("expected a digest mismatch error, got %v", err)
+	}
+	if s := NewState(path, changed); s.Project != nil {
+		t.Error("expected a new state to be empty")
+	}
+}
@@ -0,0 +1,105 @@
+// Package pseudonym replaces user and item IDs with pseudonyms before they
+// leave the machine and keeps an encrypted local mapping to translate them
+// back.
+package pseudonym
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+)
+
+// Mode selects how pseudonyms are generated.
+type Mode string
+
+const (
+	// ModeHMAC derives a pseudonym from a keyed hash of the ID, so the same
+	// ID always maps to the same pseudonym under the same key.
+	ModeHMAC Mode = "hmac"
+	// ModeSequential numbers IDs in the order they are first seen.
+	ModeSequential Mode = "sequential"
+)
+
+// ParseMode validates a --pseudonymize value.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case ModeHMAC, ModeSequential:
+		return m, nil
+	}
+	return "", fmt.Errorf("unsupported pseudonymization mode %q (expected hmac or sequential)", s)
+}
+
+// Kind distinguishes the user and item ID spaces.
+type Kind string
+
+const (
+	KindUser Kind = "user"
+	KindItem Kind = "item"
+)
+
+// Mapping translates raw IDs to pseudonyms and back.
+type Mapping struct {
+	Mode  Mode              `json:"mode"`
+	Users map[string]string `json:"users"`
+	Items map[string]string `json:"items"`
+
+	hmacKey []byte
+	reverse map[Kind]map[string]string
+}
+
+func newMapping(mode Mode, hmacKey []byte) *Mapping {
+	m := &Mapping{Mode: mode, Users: map[string]string{}, Items: map[string]string{}, hmacKey: hmacKey}
+	m.index()
+	return m
+}
+
+func (m *Mapping) index() {
+	m.reverse = map[Kind]map[string]string{KindUser: {}, KindItem: {}}
+	for _, kind := range []Kind{KindUser, KindItem} {
+		for raw, p := range m.forward(kind) {
+			m.reverse[kind][p] = raw
+		}
+	}
+}
+
+func (m *Mapping) forward(kind Kind) map[string]string {
+	if kind == KindUser {
+		return m.Users
+	}
+	return m.Items
+}
+
+// Pseudonym returns the pseudonym of raw, assigning a new one if needed.
+func (m *Mapping) Pseudonym(kind Kind, raw string) string {
+	forward := m.forward(kind)
+	if p, ok := forward[raw]; ok {
+		return p
+	}
+	var p string
+	if m.Mode == ModeSequential {
+		p = string(kind) + "-" + strconv.Itoa(len(forward)+1)
+	} else {
+		h := hmac.New(sha256.New, m.hmacKey)
+		h.Write([]byte(kind))
+		h.Write([]byte{0})
+		h.Write([]byte(raw))
+		p = hex.EncodeToString(h.Sum(nil)[:16])
+	}
+	forward[raw] = p
+	m.reverse[kind][p] = raw
+	return p
+}
+
+// Lookup returns the pseudonym already assigned to raw.
+func (m *Mapping) Lookup(kind Kind, raw string) (string, bool) {
+	p, ok := m.forward(kind)[raw]
+	return p, ok
+}
+
+// Reveal returns the raw ID behind a pseudonym.
+func (m *Mapping) Reveal(kind Kind, pseudonym string) (string, bool) {
+	raw, ok := m.reverse[kind][pseudonym]
+	return raw, ok
+}
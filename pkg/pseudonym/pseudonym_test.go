@@ -0,0 +1,168 @@
+package pseudonym
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"recotem.org/cli/recotem/pkg/dataset"
+)
+
+var testSecret = []byte("test-secret")
+
+func TestParseMode(t *testing.T) {
+	for _, s := range []string{"hmac", "sequential"} {
+		if m, err := ParseMode(s); err != nil || string(m) != s {
+			t.Errorf("ParseMode(%q) = %q, %v", s, m, err)
+		}
+	}
+	if _, err := ParseMode("md5"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+func TestHMACPseudonymsAreStable(t *testing.T) {
+	a := newMapping(ModeHMAC, deriveKey(testSecret, "hmac"))
+	b := newMapping(ModeHMAC, deriveKey(testSecret, "hmac"))
+	other := newMapping(ModeHMAC, deriveKey([]byte("other"), "hmac"))
+
+	p := a.Pseudonym(KindUser, "alice")
+	if len(p) != 32 {
+		t.Errorf("expected 32 hex characters, got %q", p)
+	}
+	if b.Pseudonym(KindUser, "alice") != p {
+		t.Error("same key should give the same pseudonym")
+	}
+	if other.Pseudonym(KindUser, "alice") == p {
+		t.Error("different keys should give different pseudonyms")
+	}
+	if a.Pseudonym(KindItem, "alice") == p {
+		t.Error("user and item pseudonyms should differ")
+	}
+	if raw, ok := a.Reveal(KindUser, p); !ok || raw != "alice" {
+		t.Errorf("Reveal = %q, %v", raw, ok)
+	}
+}
+
+func TestSequentialPseudonyms(t *testing.T) {
+	m := newMapping(ModeSequential, nil)
+	if got := m.Pseudonym(KindUser, "alice"); got != "user-1" {
+		t.Errorf("got %q, want user-1", got)
+	}
+	if got := m.Pseudonym(KindUser, "bob"); got != "user-2" {
+		t.Errorf("got %q, want user-2", got)
+	}
+	if got := m.Pseudonym(KindUser, "alice"); got != "user-1" {
+		t.Errorf("repeated ID got %q, want user-1", got)
+	}
+	if got := m.Pseudonym(KindItem, "apple"); got != "item-1" {
+		t.Errorf("got %q, want item-1", got)
+	}
+	if _, ok := m.Lookup(KindUser, "carol"); ok {
+		t.Error("Lookup should not assign pseudonyms")
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "project-1.map")
+	s, err := Open(path, testSecret, ModeSequential)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	s.Pseudonym(KindUser, "alice")
+	s.Pseudonym(KindItem, "apple")
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf, []byte("alice")) {
+		t.Error("mapping file should be encrypted")
+	}
+
+	loaded, err := Load(path, testSecret)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if raw, ok := loaded.Reveal(KindItem, "item-1"); !ok || raw != "apple" {
+		t.Errorf("Reveal = %q, %v", raw, ok)
+	}
+	if got := loaded.Pseudonym(KindUser, "bob"); got != "user-2" {
+		t.Errorf("sequence should continue after reload, got %q", got)
+	}
+
+	if _, err := Load(path, []byte("wrong")); err == nil {
+		t.Error("expected error with the wrong key")
+	}
+	if _, err := Open(path, testSecret, ModeHMAC); err == nil {
+		t.Error("expected error when switching modes")
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.map"), testSecret)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected os.ErrNotExist, got %v", err)
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	t.Setenv(KeyEnv, "")
+	path := filepath.Join(t.TempDir(), "pseudonym.key")
+	key, err := LoadKey(path)
+	if err != nil {
+		t.Fatalf("LoadKey failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("key file not created: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("key file mode = %v, want 0600", info.Mode().Perm())
+	}
+	again, err := LoadKey(path)
+	if err != nil || !bytes.Equal(key, again) {
+		t.Errorf("second LoadKey = %q, %v; want %q", again, err, key)
+	}
+
+	t.Setenv(KeyEnv, "from-env")
+	if key, _ := LoadKey(path); string(key) != "from-env" {
+		t.Errorf("expected key from environment, got %q", key)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(path, []byte("user,item,rating\nalice,apple,5\n,banana,3\nbob,apple,4\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := dataset.Open(path, dataset.FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	m := newMapping(ModeSequential, nil)
+	wrapped, err := Wrap(r, m, map[string]Kind{"user": KindUser, "item": KindItem})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := dataset.WriteCSV(&out, wrapped); err != nil {
+		t.Fatal(err)
+	}
+	want := "user,item,rating\nuser-1,item-1,5\n,item-2,3\nuser-2,item-1,4\n"
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	if _, err := Wrap(r, m, map[string]Kind{"missing": KindUser}); err == nil {
+		t.Error("expected error for a missing column")
+	}
+}
@@ -0,0 +1,38 @@
+package pseudonym
+
+import (
+	"recotem.org/cli/recotem/pkg/dataset"
+)
+
+// Wrap returns a reader that replaces the values of the given columns with
+// pseudonyms from m. Empty values are left empty.
+func Wrap(r dataset.Reader, m *Mapping, columns map[string]Kind) (dataset.Reader, error) {
+	kinds := make(map[int]Kind, len(columns))
+	for name, kind := range columns {
+		i, err := dataset.ColumnIndex(r.Columns(), name)
+		if err != nil {
+			return nil, err
+		}
+		kinds[i] = kind
+	}
+	return &reader{Reader: r, mapping: m, kinds: kinds}, nil
+}
+
+type reader struct {
+	dataset.Reader
+	mapping *Mapping
+	kinds   map[int]Kind
+}
+
+func (r *reader) Read() ([]string, error) {
+	row, err := r.Reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	for i, kind := range r.kinds {
+		if i < len(row) && row[i] != "" {
+			row[i] = r.mapping.Pseudonym(kind, row[i])
+		}
+	}
+	return row, nil
+}
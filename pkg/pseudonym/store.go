@@ -0,0 +1,154 @@
+package pseudonym
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// KeyEnv overrides the key file with a secret taken from the environment.
+const KeyEnv = "RECOTEM_PSEUDONYM_KEY"
+
+var fileMagic = []byte("RCPM1")
+
+// LoadKey returns the pseudonymization secret from KeyEnv or from keyFile,
+// creating keyFile with a random secret the first time it is needed.
+func LoadKey(keyFile string) ([]byte, error) {
+	if s := os.Getenv(KeyEnv); s != "" {
+		return []byte(s), nil
+	}
+	buf, err := os.ReadFile(keyFile)
+	if err == nil {
+		return bytes.TrimSpace(buf), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	encoded := []byte(hex.EncodeToString(secret))
+	if err := os.MkdirAll(filepath.Dir(keyFile), 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(keyFile, append(encoded, '\n'), 0600); err != nil {
+		return nil, err
+	}
+	return encoded, nil
+}
+
+// Store is a Mapping persisted in an AES-GCM encrypted file.
+type Store struct {
+	*Mapping
+	path   string
+	secret []byte
+}
+
+// Open loads the mapping at path, or starts an empty one in the given mode
+// if the file does not exist yet. An existing mapping must use the same mode,
+// otherwise IDs uploaded earlier would no longer match.
+func Open(path string, secret []byte, mode Mode) (*Store, error) {
+	s, err := Load(path, secret)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Store{Mapping: newMapping(mode, deriveKey(secret, "hmac")), path: path, secret: secret}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.Mode != mode {
+		return nil, fmt.Errorf("%s already holds %s pseudonyms; cannot switch to %s", path, s.Mode, mode)
+	}
+	return s, nil
+}
+
+// Load reads an existing mapping. It returns an error wrapping
+// os.ErrNotExist when there is none.
+func Load(path string, secret []byte) (*Store, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(secret)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(buf, fileMagic) || len(buf) < len(fileMagic)+gcm.NonceSize() {
+		return nil, fmt.Errorf("%s is not a pseudonym mapping file", path)
+	}
+	buf = buf[len(fileMagic):]
+	plain, err := gcm.Open(nil, buf[:gcm.NonceSize()], buf[gcm.NonceSize():], fileMagic)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt %s (wrong key?)", path)
+	}
+	m := &Mapping{}
+	if err := json.Unmarshal(plain, m); err != nil {
+		return nil, fmt.Errorf("invalid mapping in %s: %w", path, err)
+	}
+	if m.Users == nil {
+		m.Users = map[string]string{}
+	}
+	if m.Items == nil {
+		m.Items = map[string]string{}
+	}
+	m.hmacKey = deriveKey(secret, "hmac")
+	m.index()
+	return &Store{Mapping: m, path: path, secret: secret}, nil
+}
+
+// Save encrypts the mapping and atomically replaces the file.
+func (s *Store) Save() error {
+	plain, err := json.Marshal(s.Mapping)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(s.secret)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	out := append(append(append([]byte{}, fileMagic...), nonce...), gcm.Seal(nil, nonce, plain, fileMagic)...)
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), "."+strings.TrimPrefix(filepath.Base(s.path), ".")+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// deriveKey separates the HMAC and encryption keys derived from one secret.
+func deriveKey(secret []byte, purpose string) []byte {
+	sum := sha256.Sum256(append([]byte("recotem-pseudonym-"+purpose+"\x00"), secret...))
+	return sum[:]
+}
+
+func newGCM(secret []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(deriveKey(secret, "encryption"))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,234 @@
+// Package recommender is a built-in catalog of the irspack recommender
+// classes the server trains, used to check model configurations before they
+// are sent.
+package recommender
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed catalog.yaml
+var catalogYAML []byte
+
+// Class is a recommender class and the parameters of its constructor.
+type Class struct {
+	Name        string      `yaml:"name" json:"name"`
+	Description string      `yaml:"description" json:"description"`
+	Parameters  []Parameter `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+}
+
+// Parameter describes one constructor parameter. Min and Max are inclusive.
+type Parameter struct {
+	Name        string   `yaml:"name" json:"name"`
+	Type        string   `yaml:"type" json:"type"`
+	Default     any      `yaml:"default,omitempty" json:"default,omitempty"`
+	Nullable    bool     `yaml:"nullable,omitempty" json:"nullable,omitempty"`
+	Min         *float64 `yaml:"min,omitempty" json:"min,omitempty"`
+	Max         *float64 `yaml:"max,omitempty" json:"max,omitempty"`
+	Choices     []string `yaml:"choices,omitempty" json:"choices,omitempty"`
+	Description string   `yaml:"description" json:"description"`
+}
+
+var catalog = mustLoad(catalogYAML)
+
+func mustLoad(data []byte) []Class {
+	var classes []Class
+	if err := yaml.Unmarshal(data, &classes); err != nil {
+		panic(fmt.Sprintf("recommender: invalid catalog: %v", err))
+	}
+	return classes
+}
+
+// Classes returns the catalog in its documented order.
+func Classes() []Class {
+	return catalog
+}
+
+// Lookup finds a class by its exact name.
+func Lookup(name string) (*Class, bool) {
+	for i := range catalog {
+		if catalog[i].Name == name {
+			return &catalog[i], true
+		}
+	}
+	return nil, false
+}
+
+// Find is Lookup with an error that suggests the closest class names.
+func Find(name string) (*Class, error) {
+	if c, ok := Lookup(name); ok {
+		return c, nil
+	}
+	names := make([]string, len(catalog))
+	for i, c := range catalog {
+		names[i] = c.Name
+	}
+	msg := fmt.Sprintf("unknown recommender class %q", name)
+	if s := suggest(name, names); s != "" {
+		msg += fmt.Sprintf("; did you mean %s?", s)
+	}
+	return nil, fmt.Errorf("%s (see `recotem recommender list`)", msg)
+}
+
+// Parameter finds a parameter of the class by name.
+func (c *Class) Parameter(name string) (*Parameter, bool) {
+	for i := range c.Parameters {
+		if c.Parameters[i].Name == name {
+			return &c.Parameters[i], true
+		}
+	}
+	return nil, false
+}
+
+// Validate checks parameters decoded from JSON or YAML against the class.
+// All problems are reported together. Parameters the catalog does not list
+// are returned as warnings rather than errors, since the server's version of
+// a class may accept more than the catalog knows.
+func (c *Class) Validate(params map[string]any) (warnings []string, err error) {
+	var errs []string
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	names := make([]string, len(c.Parameters))
+	for i, p := range c.Parameters {
+		names[i] = p.Name
+	}
+	for _, k := range keys {
+		p, ok := c.Parameter(k)
+		if !ok {
+			msg := fmt.Sprintf("%s: not a parameter of %s", k, c.Name)
+			if s := suggest(k, names); s != "" {
+				msg += fmt.Sprintf(" (did you mean %s?)", s)
+			}
+			warnings = append(warnings, msg)
+			continue
+		}
+		if err := p.check(params[k]); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", k, err))
+		}
+	}
+	if len(errs) > 0 {
+		return warnings, fmt.Errorf("invalid parameters for %s:\n  %s", c.Name, strings.Join(errs, "\n  "))
+	}
+	return warnings, nil
+}
+
+func (p *Parameter) check(v any) error {
+	if v == nil {
+		if p.Nullable {
+			return nil
+		}
+		return fmt.Errorf("must not be null")
+	}
+	switch p.Type {
+	case "bool":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("expected true or false, got %v", v)
+		}
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, got %v", v)
+		}
+		if len(p.Choices) > 0 && !contains(p.Choices, s) {
+			return fmt.Errorf("%q is not one of %s", s, strings.Join(p.Choices, ", "))
+		}
+	case "int", "float":
+		f, ok := number(v)
+		if !ok {
+			return fmt.Errorf("expected a number, got %v", v)
+		}
+		if p.Type == "int" && f != math.Trunc(f) {
+			return fmt.Errorf("expected an integer, got %v", v)
+		}
+		if p.Min != nil && f < *p.Min {
+			return fmt.Errorf("%v is below the minimum %v", v, *p.Min)
+		}
+		if p.Max != nil && f > *p.Max {
+			return fmt.Errorf("%v is above the maximum %v", v, *p.Max)
+		}
+	}
+	return nil
+}
+
+// Range describes the valid values of a parameter, such as ">= 1" or
+// "none, log".
+func (p *Parameter) Range() string {
+	switch {
+	case len(p.Choices) > 0:
+		return strings.Join(p.Choices, ", ")
+	case p.Min != nil && p.Max != nil:
+		return fmt.Sprintf("%v..%v", *p.Min, *p.Max)
+	case p.Min != nil:
+		return fmt.Sprintf(">= %v", *p.Min)
+	case p.Max != nil:
+		return fmt.Sprintf("<= %v", *p.Max)
+	}
+	return ""
+}
+
+func number(v any) (float64, bool) {
+	switch x := v.(type) {
+	case int:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case float64:
+		return x, true
+	}
+	return 0, false
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest returns the candidate closest to s by edit distance, ignoring case,
+// if it is close enough to be a likely typo.
+func suggest(s string, candidates []string) string {
+	best, bestDist := "", -1
+	for _, c := range candidates {
+		d := distance(strings.ToLower(s), strings.ToLower(c))
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	if bestDist < 0 || bestDist > max(2, len(s)/3) {
+		return ""
+	}
+	return best
+}
+
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
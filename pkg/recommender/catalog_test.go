@@ -0,0 +1,100 @@
+package recommender
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	for _, name := range []string{"IALSRecommender", "RP3betaRecommender", "TopPopRecommender", "DenseSLIMRecommender"} {
+		if _, ok := Lookup(name); !ok {
+			t.Errorf("expected %s in the catalog", name)
+		}
+	}
+	types := map[string]bool{"int": true, "float": true, "bool": true, "string": true}
+	seen := map[string]bool{}
+	for _, c := range Classes() {
+		if seen[c.Name] {
+			t.Errorf("duplicate class %s", c.Name)
+		}
+		seen[c.Name] = true
+		for _, p := range c.Parameters {
+			if !types[p.Type] {
+				t.Errorf("%s.%s: unknown type %q", c.Name, p.Name, p.Type)
+			}
+			if p.Default != nil {
+				if err := p.check(p.Default); err != nil {
+					t.Errorf("%s.%s: default %v is invalid: %v", c.Name, p.Name, p.Default, err)
+				}
+			}
+		}
+	}
+}
+
+func TestFindSuggests(t *testing.T) {
+	if _, err := Find("IALSRecommendr"); err == nil || !strings.Contains(err.Error(), "did you mean IALSRecommender?") {
+		t.Errorf("expected a suggestion, got %v", err)
+	}
+	if _, err := Find("SomethingElse"); err == nil || strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("expected no suggestion for a distant name, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	c, _ := Lookup("IALSRecommender")
+	warnings, err := c.Validate(map[string]any{"n_components": int64(64), "reg": 1, "confidence_scaling": "log",
+		"loss_type": "ORIGINAL", "nu_star": nil, "n_threads": nil})
+	if err != nil || len(warnings) > 0 {
+		t.Errorf("unexpected problems: %v, %v", warnings, err)
+	}
+
+	warnings, err = c.Validate(map[string]any{"n_component": 64, "prediction_time_block_size": 32})
+	if err != nil {
+		t.Errorf("expected unknown parameters not to be an error, got %v", err)
+	}
+	want := []string{
+		"n_component: not a parameter of IALSRecommender (did you mean n_components?)",
+		"prediction_time_block_size: not a parameter of IALSRecommender",
+	}
+	if strings.Join(warnings, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected warnings %q, got %q", want, warnings)
+	}
+
+	_, err = c.Validate(map[string]any{
+		"n_components":       1.5,
+		"reg":                -1.0,
+		"confidence_scaling": "sqrt",
+		"train_epochs":       "16",
+		"random_seed":        nil,
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{
+		"n_components: expected an integer",
+		"reg: -1 is below the minimum 0",
+		`confidence_scaling: "sqrt" is not one of none, log`,
+		"train_epochs: expected a number",
+		"random_seed: must not be null",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in:\n%v", want, err)
+		}
+	}
+
+	top, _ := Lookup("TopPopRecommender")
+	if warnings, _ := top.Validate(map[string]any{"top_k": 10}); len(warnings) != 1 {
+		t.Errorf("expected a warning for a class without parameters, got %v", warnings)
+	}
+}
+
+func TestRange(t *testing.T) {
+	c, _ := Lookup("SLIMRecommender")
+	cases := map[string]string{"l1_ratio": "0..1", "alpha": ">= 0", "positive_only": ""}
+	for name, want := range cases {
+		p, _ := c.Parameter(name)
+		if got := p.Range(); got != want {
+			t.Errorf("%s: expected %q, got %q", name, want, got)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Package tuning interprets the results recorded on parameter tuning jobs.
+package tuning
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Trial is one evaluated combination of recommender and parameters.
+type Trial struct {
+	Job       int            `json:"job" yaml:"job"`
+	Algorithm string         `json:"algorithm" yaml:"algorithm"`
+	Score     *float64       `json:"score" yaml:"score"`
+	Params    map[string]any `json:"params" yaml:"params"`
+}
+
+// Entry is the leaderboard row of one algorithm.
+type Entry struct {
+	Rank      int            `json:"rank" yaml:"rank"`
+	Algorithm string         `json:"algorithm" yaml:"algorithm"`
+	BestScore *float64       `json:"best_score" yaml:"best_score"`
+	Trials    int            `json:"trials" yaml:"trials"`
+	Job       int            `json:"job" yaml:"job"`
+	Params    map[string]any `json:"params" yaml:"params"`
+}
+
+var (
+	algorithmKeys = []string{"recommender_class_name", "algorithm", "recommender_name", "recommender", "class_name"}
+	scoreKeys     = []string{"score", "value"}
+	paramsKeys    = []string{"params", "parameters"}
+	// metaKeys are bookkeeping columns that are neither parameters nor scores.
+	metaKeys = map[string]bool{
+		"trial_index": true, "number": true, "trial": true, "state": true,
+		"datetime_start": true, "datetime_complete": true, "duration": true,
+		"time": true, "elapsed": true, "elapsed_time": true,
+		"user_attrs": true, "system_attrs": true,
+	}
+)
+
+// ParseTrials decodes tried_algorithms_json. Both a list of trial records and
+// a table of columns keyed by row number (as written by pandas) are accepted.
+// The algorithm is read from a column such as recommender_class_name or
+// algorithm, the score from score or value, and the parameters from a params
+// object or else from the remaining columns; optuna's "params_" prefix is
+// stripped and null parameters, which belong to other algorithms in a table,
+// are dropped. A plain list of algorithm names yields no trials.
+func ParseTrials(job int, s string) ([]Trial, error) {
+	records, err := decodeRecords(s)
+	if err != nil {
+		return nil, err
+	}
+	trials := make([]Trial, 0, len(records))
+	for _, r := range records {
+		t := Trial{Job: job, Params: map[string]any{}}
+		for _, k := range algorithmKeys {
+			if v, ok := r[k].(string); ok && v != "" {
+				t.Algorithm = v
+				break
+			}
+		}
+		for _, k := range scoreKeys {
+			if v, ok := r[k].(float64); ok && !math.IsNaN(v) {
+				t.Score = &v
+				break
+			}
+		}
+		nested := false
+		for _, k := range paramsKeys {
+			if p, ok := r[k].(map[string]any); ok {
+				for name, v := range p {
+					if v != nil {
+						t.Params[name] = v
+					}
+				}
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			for k, v := range r {
+				if v == nil || metaKeys[k] || contains(algorithmKeys, k) || contains(scoreKeys, k) {
+					continue
+				}
+				t.Params[strings.TrimPrefix(k, "params_")] = v
+			}
+		}
+		if t.Algorithm == "" {
+			t.Algorithm = "unknown"
+		}
+		trials = append(trials, t)
+	}
+	return trials, nil
+}
+
+// Algorithms returns the algorithms named in tried_algorithms_json, whether it
+// holds the list of algorithms to try or the trial results, in the order
+// they first appear.
+func Algorithms(s string) ([]string, error) {
+	var names []string
+	if err := json.Unmarshal([]byte(s), &names); err == nil {
+		return names, nil
+	}
+	trials, err := ParseTrials(0, s)
+	if err != nil {
+		return nil, err
+	}
+	names = nil
+	seen := map[string]bool{}
+	for _, t := range trials {
+		if !seen[t.Algorithm] {
+			seen[t.Algorithm] = true
+			names = append(names, t.Algorithm)
+		}
+	}
+	return names, nil
+}
+
+func decodeRecords(s string) ([]map[string]any, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var records []map[string]any
+	if err := json.Unmarshal([]byte(s), &records); err == nil {
+		return records, nil
+	}
+	// Before tuning runs, the field holds the names of the algorithms to try.
+	var names []string
+	if err := json.Unmarshal([]byte(s), &names); err == nil {
+		return nil, nil
+	}
+	var columns map[string]map[string]any
+	if err := json.Unmarshal([]byte(s), &columns); err != nil {
+		return nil, fmt.Errorf("tried_algorithms_json is neither a list of trials nor a table: %w", err)
+	}
+	rows := map[string]map[string]any{}
+	for col, values := range columns {
+		for row, v := range values {
+			if rows[row] == nil {
+				rows[row] = map[string]any{}
+			}
+			rows[row][col] = v
+		}
+	}
+	keys := make([]string, 0, len(rows))
+	for k := range rows {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.Atoi(keys[i])
+		b, errB := strconv.Atoi(keys[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		records = append(records, rows[k])
+	}
+	return records, nil
+}
+
+func contains(keys []string, k string) bool {
+	for _, key := range keys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Rank groups trials by algorithm and orders the algorithms by their best
+// score, highest first. Algorithms without a scored trial come last.
+func Rank(trials []Trial) []Entry {
+	byAlgorithm := map[string]*Entry{}
+	var order []string
+	for _, t := range trials {
+		e, ok := byAlgorithm[t.Algorithm]
+		if !ok {
+			e = &Entry{Algorithm: t.Algorithm, Job: t.Job, Params: t.Params}
+			byAlgorithm[t.Algorithm] = e
+			order = append(order, t.Algorithm)
+		}
+		e.Trials++
+		if t.Score != nil && (e.BestScore == nil || *t.Score > *e.BestScore) {
+			e.BestScore, e.Job, e.Params = t.Score, t.Job, t.Params
+		}
+	}
+	entries := make([]Entry, 0, len(order))
+	for _, a := range order {
+		entries = append(entries, *byAlgorithm[a])
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].BestScore, entries[j].BestScore
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return *a > *b
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+	return entries
+}
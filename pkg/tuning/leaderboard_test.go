@@ -0,0 +1,110 @@
+package tuning
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTrialsRecords(t *testing.T) {
+	trials, err := ParseTrials(3, `[
+		{"recommender_class_name": "IALSRecommender", "score": 0.21, "params": {"n_components": 64, "alpha": null}},
+		{"algorithm": "TopPopRecommender", "value": 0.05, "trial_index": 1}
+	]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trials) != 2 {
+		t.Fatalf("expected 2 trials, got %d", len(trials))
+	}
+	if trials[0].Job != 3 || trials[0].Algorithm != "IALSRecommender" || *trials[0].Score != 0.21 {
+		t.Errorf("unexpected first trial: %+v", trials[0])
+	}
+	if !reflect.DeepEqual(trials[0].Params, map[string]any{"n_components": float64(64)}) {
+		t.Errorf("expected null parameters to be dropped, got %v", trials[0].Params)
+	}
+	if trials[1].Algorithm != "TopPopRecommender" || *trials[1].Score != 0.05 || len(trials[1].Params) != 0 {
+		t.Errorf("unexpected second trial: %+v", trials[1])
+	}
+}
+
+func TestParseTrialsColumns(t *testing.T) {
+	trials, err := ParseTrials(1, `{
+		"algorithm": {"0": "IALSRecommender", "1": "RP3betaRecommender", "10": "IALSRecommender"},
+		"score": {"0": 0.1, "1": 0.3, "10": null},
+		"params_n_components": {"0": 32, "1": null, "10": 128},
+		"params_beta": {"0": null, "1": 0.5, "10": null}
+	}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trials) != 3 {
+		t.Fatalf("expected 3 trials, got %d", len(trials))
+	}
+	if trials[1].Algorithm != "RP3betaRecommender" || !reflect.DeepEqual(trials[1].Params, map[string]any{"beta": 0.5}) {
+		t.Errorf("unexpected second trial: %+v", trials[1])
+	}
+	if trials[2].Score != nil || !reflect.DeepEqual(trials[2].Params, map[string]any{"n_components": float64(128)}) {
+		t.Errorf("expected rows in numeric order with a failed last trial, got %+v", trials[2])
+	}
+}
+
+func TestParseTrialsInvalid(t *testing.T) {
+	if _, err := ParseTrials(1, `"nope"`); err == nil {
+		t.Error("expected an error")
+	}
+	for _, s := range []string{"", `["IALSRecommender", "TopPopRecommender"]`} {
+		trials, err := ParseTrials(1, s)
+		if err != nil || len(trials) != 0 {
+			t.Errorf("expected no trials for %q, got %v, %v", s, trials, err)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	score := func(v float64) *float64 { return &v }
+	trials := []Trial{
+		{Job: 1, Algorithm: "IALSRecommender", Score: score(0.1), Params: map[string]any{"n_components": 32}},
+		{Job: 1, Algorithm: "TopPopRecommender", Score: score(0.05)},
+		{Job: 2, Algorithm: "IALSRecommender", Score: score(0.3), Params: map[string]any{"n_components": 128}},
+		{Job: 2, Algorithm: "DenseSLIMRecommender"},
+		{Job: 2, Algorithm: "IALSRecommender", Score: score(0.2)},
+	}
+	entries := Rank(trials)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	first := entries[0]
+	if first.Rank != 1 || first.Algorithm != "IALSRecommender" || *first.BestScore != 0.3 || first.Trials != 3 || first.Job != 2 {
+		t.Errorf("unexpected winner: %+v", first)
+	}
+	if !reflect.DeepEqual(first.Params, map[string]any{"n_components": 128}) {
+		t.Errorf("expected the best trial's parameters, got %v", first.Params)
+	}
+	if entries[1].Algorithm != "TopPopRecommender" || entries[2].Algorithm != "DenseSLIMRecommender" || entries[2].BestScore != nil {
+		t.Errorf("unexpected order: %+v", entries)
+	}
+}
+
+func TestAlgorithms(t *testing.T) {
+	tests := []struct {
+		json string
+		want []string
+	}{
+		{`["IALSRecommender","TopPopRecommender"]`, []string{"IALSRecommender", "TopPopRecommender"}},
+		{`[{"algorithm":"IALSRecommender"},{"algorithm":"RP3betaRecommender"},{"algorithm":"IALSRecommender"}]`,
+			[]string{"IALSRecommender", "RP3betaRecommender"}},
+		{``, nil},
+	}
+	for _, tt := range tests {
+		got, err := Algorithms(tt.json)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.json, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.json, tt.want, got)
+		}
+	}
+	if _, err := Algorithms(`{`); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,243 @@
+package tuning
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ref refers to a resource by ID or by name.
+type Ref struct {
+	ID   *int
+	Name string
+}
+
+// ParseRef reads a reference from a flag value: digits are an ID, anything
+// else is a name.
+func ParseRef(s string) Ref {
+	if id, err := strconv.Atoi(s); err == nil {
+		return Ref{ID: &id}
+	}
+	return Ref{Name: s}
+}
+
+// IsZero reports whether the reference is unset.
+func (r Ref) IsZero() bool {
+	return r.ID == nil && r.Name == ""
+}
+
+func (r Ref) String() string {
+	if r.ID != nil {
+		return strconv.Itoa(*r.ID)
+	}
+	return strconv.Quote(r.Name)
+}
+
+// UnmarshalYAML accepts an integer ID or a name.
+func (r *Ref) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: expected an ID or a name", n.Line)
+	}
+	if n.Tag == "!!int" {
+		id, err := strconv.Atoi(n.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid ID %q", n.Line, n.Value)
+		}
+		*r = Ref{ID: &id}
+		return nil
+	}
+	*r = Ref{Name: n.Value}
+	return nil
+}
+
+// MarshalYAML writes the ID or the name.
+func (r Ref) MarshalYAML() (any, error) {
+	if r.ID != nil {
+		return *r.ID, nil
+	}
+	if r.Name != "" {
+		return r.Name, nil
+	}
+	return nil, nil
+}
+
+// Spec describes a parameter tuning job. Data, split and evaluation may be
+// given by ID or by name; timeouts are in seconds.
+type Spec struct {
+	Preset            string   `yaml:"preset,omitempty"`
+	Data              Ref      `yaml:"data,omitempty"`
+	Split             Ref      `yaml:"split,omitempty"`
+	Evaluation        Ref      `yaml:"evaluation,omitempty"`
+	NTrials           *int     `yaml:"n_trials,omitempty"`
+	NTasksParallel    *int     `yaml:"n_tasks_parallel,omitempty"`
+	MemoryBudget      *int     `yaml:"memory_budget,omitempty"`
+	TimeoutOverall    *int     `yaml:"timeout_overall,omitempty"`
+	TimeoutSinglestep *int     `yaml:"timeout_singlestep,omitempty"`
+	RandomSeed        *int     `yaml:"random_seed,omitempty"`
+	TrainAfterTuning  *bool    `yaml:"train_after_tuning,omitempty"`
+	Algorithms        []string `yaml:"algorithms,omitempty"`
+}
+
+func intPtr(v int) *int { return &v }
+
+// Presets are the built-in tuning budgets.
+var Presets = map[string]Spec{
+	"quick": {
+		NTrials:           intPtr(10),
+		NTasksParallel:    intPtr(1),
+		TimeoutOverall:    intPtr(600),
+		TimeoutSinglestep: intPtr(120),
+	},
+	"balanced": {
+		NTrials:           intPtr(40),
+		NTasksParallel:    intPtr(2),
+		TimeoutOverall:    intPtr(3600),
+		TimeoutSinglestep: intPtr(600),
+	},
+	"thorough": {
+		NTrials:           intPtr(100),
+		NTasksParallel:    intPtr(4),
+		TimeoutOverall:    intPtr(4 * 3600),
+		TimeoutSinglestep: intPtr(1800),
+	},
+}
+
+// PresetNames lists the presets in alphabetical order.
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadSpec reads and validates a job spec file.
+func LoadSpec(path string) (*Spec, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadSpec(f)
+}
+
+// ReadSpec is LoadSpec for a reader. Unknown fields are rejected. Required
+// fields are not checked, as flags may still supply them.
+func ReadSpec(r io.Reader) (*Spec, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	s := &Spec{}
+	if err := dec.Decode(s); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid job spec: %w", err)
+	}
+	return s, nil
+}
+
+// Override returns s with every field that is set in o replaced.
+func (s Spec) Override(o Spec) Spec {
+	if o.Preset != "" {
+		s.Preset = o.Preset
+	}
+	if !o.Data.IsZero() {
+		s.Data = o.Data
+	}
+	if !o.Split.IsZero() {
+		s.Split = o.Split
+	}
+	if !o.Evaluation.IsZero() {
+		s.Evaluation = o.Evaluation
+	}
+	override(&s.NTrials, o.NTrials)
+	override(&s.NTasksParallel, o.NTasksParallel)
+	override(&s.MemoryBudget, o.MemoryBudget)
+	override(&s.TimeoutOverall, o.TimeoutOverall)
+	override(&s.TimeoutSinglestep, o.TimeoutSinglestep)
+	override(&s.RandomSeed, o.RandomSeed)
+	override(&s.TrainAfterTuning, o.TrainAfterTuning)
+	if o.Algorithms != nil {
+		s.Algorithms = o.Algorithms
+	}
+	return s
+}
+
+func override[T any](dst **T, v *T) {
+	if v != nil {
+		*dst = v
+	}
+}
+
+// Resolve applies the named preset, if any, underneath the spec.
+func (s Spec) Resolve() (Spec, error) {
+	if s.Preset == "" {
+		return s, nil
+	}
+	preset, ok := Presets[s.Preset]
+	if !ok {
+		return s, fmt.Errorf("unknown preset %q (expected %s)", s.Preset, strings.Join(PresetNames(), ", "))
+	}
+	return preset.Override(s), nil
+}
+
+// Validate checks required references and value ranges. All problems are
+// reported together.
+func (s Spec) Validate() error {
+	var errs []string
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+	if s.Preset != "" {
+		if _, ok := Presets[s.Preset]; !ok {
+			fail("preset: unknown preset %q (expected %s)", s.Preset, strings.Join(PresetNames(), ", "))
+		}
+	}
+	required := func(field string, r Ref) {
+		if r.IsZero() {
+			fail("%s: required (an ID or a name)", field)
+		} else if r.ID != nil && *r.ID <= 0 {
+			fail("%s: ID must be positive, got %d", field, *r.ID)
+		}
+	}
+	required("data", s.Data)
+	required("split", s.Split)
+	required("evaluation", s.Evaluation)
+	atLeast := func(field string, v *int, min int) {
+		if v != nil && *v < min {
+			fail("%s: must be at least %d, got %d", field, min, *v)
+		}
+	}
+	atLeast("n_trials", s.NTrials, 1)
+	atLeast("n_tasks_parallel", s.NTasksParallel, 1)
+	atLeast("memory_budget", s.MemoryBudget, 1)
+	atLeast("timeout_overall", s.TimeoutOverall, 1)
+	atLeast("timeout_singlestep", s.TimeoutSinglestep, 1)
+	atLeast("random_seed", s.RandomSeed, 0)
+	if s.TimeoutOverall != nil && s.TimeoutSinglestep != nil && *s.TimeoutSinglestep > *s.TimeoutOverall {
+		fail("timeout_singlestep (%d) must not exceed timeout_overall (%d)", *s.TimeoutSinglestep, *s.TimeoutOverall)
+	}
+	seen := map[string]bool{}
+	for i, a := range s.Algorithms {
+		switch {
+		case strings.TrimSpace(a) == "":
+			fail("algorithms[%d]: empty name", i)
+		case seen[a]:
+			fail("algorithms: %q is listed more than once", a)
+		}
+		seen[a] = true
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid job spec:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package tuning
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadSpec(t *testing.T) {
+	spec, err := ReadSpec(strings.NewReader(`
+preset: quick
+data: 12
+split: default-split
+evaluation: "7"
+n_trials: 60
+algorithms: [IALSRecommender, RP3betaRecommender]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.Data.ID == nil || *spec.Data.ID != 12 {
+		t.Errorf("expected data ID 12, got %v", spec.Data)
+	}
+	if spec.Split.Name != "default-split" || spec.Split.ID != nil {
+		t.Errorf("expected split by name, got %v", spec.Split)
+	}
+	if spec.Evaluation.Name != "7" {
+		t.Errorf("expected a quoted number to be a name, got %v", spec.Evaluation)
+	}
+	if *spec.NTrials != 60 || len(spec.Algorithms) != 2 {
+		t.Errorf("unexpected spec: %+v", spec)
+	}
+}
+
+func TestReadSpecRejectsUnknownAndMistypedFields(t *testing.T) {
+	for _, doc := range []string{"n_trial: 10\n", "n_trials: ten\n", "data: [1]\n"} {
+		if _, err := ReadSpec(strings.NewReader(doc)); err == nil {
+			t.Errorf("expected an error for %q", doc)
+		}
+	}
+}
+
+func TestResolveAppliesPresetUnderneath(t *testing.T) {
+	n := 60
+	spec, err := Spec{Preset: "quick", NTrials: &n}.Resolve()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *spec.NTrials != 60 {
+		t.Errorf("expected the spec to win over the preset, got %d", *spec.NTrials)
+	}
+	if *spec.TimeoutOverall != *Presets["quick"].TimeoutOverall {
+		t.Errorf("expected the preset timeout, got %d", *spec.TimeoutOverall)
+	}
+
+	if _, err := (Spec{Preset: "fast"}).Resolve(); err == nil || !strings.Contains(err.Error(), "balanced, quick, thorough") {
+		t.Errorf("expected an unknown preset error listing the presets, got %v", err)
+	}
+}
+
+func TestOverride(t *testing.T) {
+	a, b := 10, 20
+	base := Spec{Data: ParseRef("1"), Split: ParseRef("s"), NTrials: &a, Algorithms: []string{"A"}}
+	got := base.Override(Spec{Split: ParseRef("3"), NTrials: &b})
+	if *got.Data.ID != 1 || *got.Split.ID != 3 || *got.NTrials != 20 || got.Algorithms[0] != "A" {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	zero, negative, big, small := 0, -1, 100, 200
+	spec := Spec{
+		Data:              Ref{ID: &zero},
+		Evaluation:        ParseRef("e"),
+		NTrials:           &zero,
+		RandomSeed:        &negative,
+		TimeoutOverall:    &big,
+		TimeoutSinglestep: &small,
+		Algorithms:        []string{"IALSRecommender", "", "IALSRecommender"},
+	}
+	err := spec.Validate()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{
+		"data: ID must be positive",
+		"split: required",
+		"n_trials: must be at least 1, got 0",
+		"random_seed: must be at least 0, got -1",
+		"timeout_singlestep (200) must not exceed timeout_overall (100)",
+		"algorithms[1]: empty name",
+		`"IALSRecommender" is listed more than once`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in:\n%s", want, err)
+		}
+	}
+
+	valid, err := Spec{Preset: "balanced", Data: ParseRef("1"), Split: ParseRef("2"), Evaluation: ParseRef("3")}.Resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected a valid spec, got %v", err)
+	}
+}
@@ -76,3 +76,12 @@ func NilOrTargetMetric(s string) *openapi.TargetMetricEnum {
 	}
 	return nil
 }
+
+// Deref returns the value v points to, or the zero value if v is nil.
+func Deref[T any](v *T) T {
+	if v == nil {
+		var zero T
+		return zero
+	}
+	return *v
+}
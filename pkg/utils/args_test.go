@@ -172,6 +172,18 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+func TestDeref(t *testing.T) {
+	if got := Deref(intPtr(3)); got != 3 {
+		t.Errorf("expected 3, got %d", got)
+	}
+	if got := Deref[int](nil); got != 0 {
+		t.Errorf("expected 0, got %d", got)
+	}
+	if got := Deref[string](nil); got != "" {
+		t.Errorf("expected empty string, got %q", got)
+	}
+}
+
 func intPtr(i int) *int {
 	return &i
 }
@@ -3,6 +3,7 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -72,6 +73,44 @@ func printTextMap(m map[string]any) {
 	fmt.Println(strings.Join(parts, "\t"))
 }
 
+// StreamPrinter prints values one at a time as they arrive, such as the
+// snapshots of a polled resource. JSON is written as one compact object per
+// line (NDJSON) and YAML as a stream of documents separated by "---", so
+// the output stays parseable however many values there are.
+type StreamPrinter struct {
+	format string
+	out    io.Writer
+	count  int
+}
+
+// NewStreamPrinter returns a StreamPrinter writing to stdout.
+func NewStreamPrinter(format string) *StreamPrinter {
+	return &StreamPrinter{format: strings.ToLower(format), out: os.Stdout}
+}
+
+// Print writes one value.
+func (p *StreamPrinter) Print(v any) {
+	switch p.format {
+	case "json":
+		if err := json.NewEncoder(p.out).Encode(v); err != nil {
+			fmt.Fprintf(os.Stderr, "JSON encoding error: %v\n", err)
+		}
+	case "yaml":
+		if p.count > 0 {
+			fmt.Fprintln(p.out, "---")
+		}
+		enc := yaml.NewEncoder(p.out)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			fmt.Fprintf(os.Stderr, "YAML encoding error: %v\n", err)
+		}
+		_ = enc.Close()
+	default:
+		fmt.Fprintln(p.out, v)
+	}
+	p.count++
+}
+
 // ToMap converts a struct-like value to a map for output
 func ToMap(pairs ...any) map[string]any {
 	m := make(map[string]any)
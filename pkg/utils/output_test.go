@@ -2,10 +2,14 @@ package utils
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 func captureStdout(t *testing.T, fn func()) string {
@@ -136,3 +140,43 @@ func TestPrintOutputTextMap(t *testing.T) {
 		t.Errorf("expected map values in text output, got %s", output)
 	}
 }
+
+func TestStreamPrinterJSON(t *testing.T) {
+	var buf bytes.Buffer
+	p := &StreamPrinter{format: "json", out: &buf}
+	p.Print(map[string]any{"n": 1})
+	p.Print(map[string]any{"n": 2})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per value, got %q", buf.String())
+	}
+	for i, line := range lines {
+		var v map[string]int
+		if err := json.Unmarshal([]byte(line), &v); err != nil || v["n"] != i+1 {
+			t.Errorf("line %d: got %q, %v", i+1, line, err)
+		}
+	}
+}
+
+func TestStreamPrinterYAML(t *testing.T) {
+	var buf bytes.Buffer
+	p := &StreamPrinter{format: "yaml", out: &buf}
+	p.Print(map[string]any{"n": 1})
+	p.Print(map[string]any{"n": 2})
+
+	dec := yaml.NewDecoder(&buf)
+	for i := 1; ; i++ {
+		var v map[string]int
+		err := dec.Decode(&v)
+		if errors.Is(err, io.EOF) {
+			if i != 3 {
+				t.Errorf("expected 2 documents, got %d", i-1)
+			}
+			break
+		}
+		if err != nil || v["n"] != i {
+			t.Fatalf("document %d: got %v, %v", i, v, err)
+		}
+	}
+}